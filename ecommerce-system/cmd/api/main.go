@@ -3,18 +3,39 @@ package main
 
 // Importación de paquetes necesarios
 import (
-	"fmt"      // Paquete para salida estándar
-	"log"      // Paquete para registro de errores y eventos
-	"net/http" // Paquete para la creación de servidores HTTP
-	"time"     // Paquete para manejo de tiempo
+	"context"   // Paquete para cancelar tareas en segundo plano
+	"fmt"       // Paquete para salida estándar
+	"log"       // Paquete para registro de errores y eventos
+	"net/http"  // Paquete para la creación de servidores HTTP
+	"os"        // Paquete para leer variables de entorno
+	"os/signal" // Paquete para recibir señales de apagado
+	"strconv"   // Paquete para convertir valores numéricos
+	"syscall"   // Señales del sistema operativo
+	"time"      // Paquete para manejo de tiempo
 
 	"github.com/gorilla/mux" // Paquete para manejo de rutas HTTP
 
 	// Importación de módulos internos para funcionalidades específicas
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/alerts"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/api"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/carriers"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/cart"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/idempotency"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/invoicing"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/notifications"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/returns"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/scheduler"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/subscriptions"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/users"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses"
 )
 
 // Función principal del programa
@@ -23,37 +44,346 @@ func main() {
 	fmt.Println("Iniciando Sistema de Gestión de E-commerce como Servicio Web...")
 
 	// Creación de repositorios en memoria para usuarios, productos y órdenes
-	userRepo := users.NewInMemoryRepository()       // Repositorio de usuarios
-	productRepo := products.NewInMemoryRepository() // Repositorio de productos
-	orderRepo := orders.NewInMemoryRepository()     // Repositorio de órdenes
+	userRepo := users.NewInMemoryRepository()                 // Repositorio de usuarios
+	productRepo := products.NewInMemoryRepository()           // Repositorio de productos
+	orderRepo := orders.NewInMemoryRepository()               // Repositorio de órdenes
+	stockLedger := products.NewInMemoryLedger()               // Libro de movimientos de inventario
+	warehouseRepo := warehouses.NewInMemoryRepository()       // Repositorio de bodegas
+	cartRepo := cart.NewInMemoryRepository()                  // Repositorio de carritos
+	couponRepo := coupons.NewInMemoryRepository()             // Repositorio de cupones
+	promotionRepo := promotions.NewInMemoryRepository()       // Repositorio de reglas de promoción
+	paymentRepo := payments.NewInMemoryRepository()           // Repositorio de pagos
+	refundRepo := refunds.NewInMemoryRepository()             // Repositorio de reembolsos
+	returnRepo := returns.NewInMemoryRepository()             // Repositorio de devoluciones
+	shipmentRepo := shipments.NewInMemoryRepository()         // Repositorio de envíos
+	invoiceRepo := invoicing.NewInMemoryRepository()          // Repositorio de facturas
+	subscriptionRepo := subscriptions.NewInMemoryRepository() // Repositorio de suscripciones
+
+	// Estrategia de asignación de bodegas: priority (por defecto), nearest o fewest_splits
+	allocationStrategy, err := warehouses.StrategyByName(os.Getenv("ALLOCATION_STRATEGY"))
+	if err != nil {
+		log.Fatalf("Estrategia de asignación inválida: %v\n", err)
+	}
+
+	// Canal de notificaciones: archivo si se define NOTIFICATIONS_FILE, si no el registro estándar
+	var notifier notifications.Notifier = notifications.NewLogNotifier()
+	if path := os.Getenv("NOTIFICATIONS_FILE"); path != "" {
+		notifier = notifications.NewFileNotifier(path)
+	}
+
+	// Contexto de las tareas en segundo plano; se cancela al terminar el programa
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Respuestas guardadas por Idempotency-Key; IDEMPOTENCY_TTL define cuánto duran (por defecto 24h)
+	idempotencyTTL := 24 * time.Hour
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		if idempotencyTTL, err = time.ParseDuration(value); err != nil || idempotencyTTL <= 0 {
+			log.Fatalf("Valor inválido para IDEMPOTENCY_TTL: %q\n", value)
+		}
+	}
+	idempotencyStore := idempotency.NewInMemoryStore(idempotencyTTL)
+	go idempotencyStore.Run(ctx, time.Minute) // Limpieza de claves expiradas
+
+	// Verificador de stock bajo que revisa cada cambio de stock en segundo plano
+	lowStockChecker := alerts.NewLowStockChecker(notifier, 256)
+	go lowStockChecker.Run(ctx)
+
+	// Dependencias opcionales del servicio de productos
+	productOptions := []products.Option{
+		products.WithStockObserver(lowStockChecker), // Alertas de stock bajo
+	}
 
 	// Creación de servicios a partir de los repositorios
-	userService := users.NewService(userRepo)                    // Servicio de usuarios
-	productService := products.NewService(productRepo)           // Servicio de productos
-	orderService := orders.NewService(orderRepo, productService) // Servicio de órdenes
+	userService := users.NewService(userRepo)                                                    // Servicio de usuarios
+	productService := products.NewService(productRepo, stockLedger, productOptions...)           // Servicio de productos
+	warehouseService := warehouses.NewService(warehouseRepo, productService, allocationStrategy) // Servicio de bodegas
+	couponService := coupons.NewService(couponRepo)                                              // Servicio de cupones
+	promotionService := promotions.NewService(promotionRepo)                                     // Servicio de promociones
+
+	// Reglas de promoción iniciales desde PROMOTIONS_FILE (lista JSON de reglas)
+	if path := os.Getenv("PROMOTIONS_FILE"); path != "" {
+		rules, err := promotions.LoadFile(path)
+		if err != nil {
+			log.Fatalf("No se pudieron leer las promociones: %v\n", err)
+		}
+		for _, rule := range rules {
+			if _, err := promotionService.CreateRule(ctx, rule); err != nil {
+				log.Fatalf("Promoción inválida %q: %v\n", rule.Name, err)
+			}
+		}
+		log.Printf("%d promociones cargadas desde %s\n", len(rules), path)
+	}
+
+	// Pasos de impuestos y envío del pipeline de precios (TAX_RATE, SHIPPING_FLAT_RATE, SHIPPING_FREE_ABOVE)
+	pricingSteps := []orders.PricingStep{orders.TaxStep{DefaultRate: envFloat("TAX_RATE")}}
+	if path := os.Getenv("SHIPPING_RATES_FILE"); path != "" {
+		// Tarifas por zona y peso desde SHIPPING_RATES_FILE; reemplazan la tarifa fija
+		rates, err := shipping.LoadFile(path)
+		if err != nil {
+			log.Fatalf("No se pudieron leer las tarifas de envío: %v\n", err)
+		}
+		pricingSteps = append(pricingSteps, orders.ZoneShippingStep{Rates: rates})
+		log.Printf("%d zonas de envío cargadas desde %s\n", len(rates.Zones), path)
+	} else {
+		pricingSteps = append(pricingSteps, orders.ShippingStep{FlatRate: envFloat("SHIPPING_FLAT_RATE"), FreeAbove: envFloat("SHIPPING_FREE_ABOVE")})
+	}
+
+	// Facturación al quedar pagada cada orden; INVOICE_SERIES y CREDIT_NOTE_SERIES fijan las series,
+	// INVOICE_ISSUER_NAME e INVOICE_ISSUER_TAX_ID los datos del emisor
+	invoiceSeries, creditNoteSeries := invoicing.DefaultInvoiceSeries, invoicing.DefaultCreditNoteSeries
+	if value := os.Getenv("INVOICE_SERIES"); value != "" {
+		invoiceSeries = value
+	}
+	if value := os.Getenv("CREDIT_NOTE_SERIES"); value != "" {
+		creditNoteSeries = value
+	}
+	if invoiceSeries == creditNoteSeries {
+		log.Fatalf("INVOICE_SERIES y CREDIT_NOTE_SERIES deben ser distintas: %q\n", invoiceSeries)
+	}
+	issuer := invoicing.Party{Name: "E-commerce System", TaxID: os.Getenv("INVOICE_ISSUER_TAX_ID")}
+	if value := os.Getenv("INVOICE_ISSUER_NAME"); value != "" {
+		issuer.Name = value
+	}
+	invoiceService := invoicing.NewService(invoiceRepo, issuer, userService, invoicing.WithSeries(invoiceSeries, creditNoteSeries))
+
+	// Dependencias opcionales del servicio de órdenes
+	orderOptions := []orders.Option{
+		orders.WithStockAllocator(warehouseService), // Asignación de bodegas por línea
+		orders.WithCoupons(couponService),           // Códigos de descuento
+		orders.WithPromotions(promotionService),     // Promociones automáticas
+		orders.WithPricingSteps(pricingSteps...),    // Impuestos y envío
+		orders.WithPaymentObserver(invoiceService),  // Factura al quedar pagada
+	}
+	orderService := orders.NewService(orderRepo, productService, orderOptions...) // Servicio de órdenes
+	cartService := cart.NewService(cartRepo, productService, orderService)        // Servicio de carritos
+
+	// Pagos con la pasarela simulada; PAYMENT_TIMEOUT limita la espera de cada llamada (por defecto 10s)
+	var paymentOptions []payments.Option
+	if value := os.Getenv("PAYMENT_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Valor inválido para PAYMENT_TIMEOUT: %q\n", value)
+		}
+		paymentOptions = append(paymentOptions, payments.WithGatewayTimeout(timeout))
+	}
+	paymentService := payments.NewService(paymentRepo, payments.NewFakeGateway(), orderService, paymentOptions...)
+	refundService := refunds.NewService(refundRepo, orderService, paymentService, productService,
+		refunds.WithRestocker(warehouseService), // Reingreso a las bodegas de origen
+		refunds.WithObserver(invoiceService),    // Nota de crédito por cada reembolso
+	)
+
+	// Plazo de devolución desde la entrega; RETURN_WINDOW lo cambia (por defecto 30 días)
+	returnWindow := 30 * 24 * time.Hour
+	if value := os.Getenv("RETURN_WINDOW"); value != "" {
+		if returnWindow, err = time.ParseDuration(value); err != nil || returnWindow <= 0 {
+			log.Fatalf("Valor inválido para RETURN_WINDOW: %q\n", value)
+		}
+	}
+	returnService := returns.NewService(returnRepo, returnWindow, orderService, productService, refundService, returns.WithRestocker(warehouseService))
+
+	// Transportadora simulada; FAKE_CARRIER_STEP separa sus eventos de rastreo (por defecto 1m)
+	carrierStep := time.Minute
+	if value := os.Getenv("FAKE_CARRIER_STEP"); value != "" {
+		if carrierStep, err = time.ParseDuration(value); err != nil || carrierStep <= 0 {
+			log.Fatalf("Valor inválido para FAKE_CARRIER_STEP: %q\n", value)
+		}
+	}
+	shipmentService := shipments.NewService(shipmentRepo, orderService, shipments.WithCarriers(carriers.NewFakeCarrier(carrierStep, nil)))
+
+	// Consulta periódica del rastreo; TRACKING_POLL_INTERVAL la cambia (por defecto 1m)
+	trackingInterval := time.Minute
+	if value := os.Getenv("TRACKING_POLL_INTERVAL"); value != "" {
+		if trackingInterval, err = time.ParseDuration(value); err != nil || trackingInterval <= 0 {
+			log.Fatalf("Valor inválido para TRACKING_POLL_INTERVAL: %q\n", value)
+		}
+	}
+	trackingDone := make(chan struct{})
+	go func() {
+		shipmentService.Run(ctx, trackingInterval)
+		close(trackingDone)
+	}()
+
+	// Planificador de tareas periódicas; se detiene junto con el servidor
+	clock := scheduler.NewRealClock()
+	jobScheduler := scheduler.New(clock)
+
+	// Cancelación de órdenes pendientes sin pago: PENDING_ORDER_TTL (por defecto 24h, 0 la desactiva)
+	// revisada cada PENDING_ORDER_SWEEP_INTERVAL (por defecto 1m)
+	pendingTTL := 24 * time.Hour
+	if value := os.Getenv("PENDING_ORDER_TTL"); value != "" {
+		if pendingTTL, err = time.ParseDuration(value); err != nil || pendingTTL < 0 {
+			log.Fatalf("Valor inválido para PENDING_ORDER_TTL: %q\n", value)
+		}
+	}
+	sweepInterval := time.Minute
+	if value := os.Getenv("PENDING_ORDER_SWEEP_INTERVAL"); value != "" {
+		if sweepInterval, err = time.ParseDuration(value); err != nil || sweepInterval <= 0 {
+			log.Fatalf("Valor inválido para PENDING_ORDER_SWEEP_INTERVAL: %q\n", value)
+		}
+	}
+	if pendingTTL > 0 {
+		jobScheduler.Every("cancelar órdenes pendientes", sweepInterval, orders.ExpirePendingJob(orderService, pendingTTL))
+	}
+
+	// Suscripciones: se revisan cada SUBSCRIPTION_POLL_INTERVAL (por defecto 1m); una entrega fallida se reintenta
+	// SUBSCRIPTION_MAX_ATTEMPTS veces (por defecto 3) cada SUBSCRIPTION_RETRY_DELAY (por defecto 1h)
+	subscriptionInterval := time.Minute
+	if value := os.Getenv("SUBSCRIPTION_POLL_INTERVAL"); value != "" {
+		if subscriptionInterval, err = time.ParseDuration(value); err != nil || subscriptionInterval <= 0 {
+			log.Fatalf("Valor inválido para SUBSCRIPTION_POLL_INTERVAL: %q\n", value)
+		}
+	}
+	retryDelay := time.Hour
+	if value := os.Getenv("SUBSCRIPTION_RETRY_DELAY"); value != "" {
+		if retryDelay, err = time.ParseDuration(value); err != nil || retryDelay <= 0 {
+			log.Fatalf("Valor inválido para SUBSCRIPTION_RETRY_DELAY: %q\n", value)
+		}
+	}
+	maxAttempts := 3
+	if value := os.Getenv("SUBSCRIPTION_MAX_ATTEMPTS"); value != "" {
+		if maxAttempts, err = strconv.Atoi(value); err != nil || maxAttempts <= 0 {
+			log.Fatalf("Valor inválido para SUBSCRIPTION_MAX_ATTEMPTS: %q\n", value)
+		}
+	}
+	subscriptionService := subscriptions.NewService(subscriptionRepo, orderService, notifier,
+		subscriptions.WithPayments(paymentService),
+		subscriptions.WithRetry(maxAttempts, retryDelay),
+		subscriptions.WithClock(clock.Now))
+	jobScheduler.Every("generar órdenes de suscripciones", subscriptionInterval, subscriptionService.RunDue)
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		jobScheduler.Run(ctx)
+		close(schedulerDone)
+	}()
 
 	// Inicialización del manejador API con los servicios creados
 	apiHandler := api.NewHandler(&productService, &userService, &orderService)
+	apiHandler.WarehouseService = &warehouseService
+	apiHandler.CartService = &cartService
+	apiHandler.CouponService = &couponService
+	apiHandler.PromotionService = &promotionService
+	apiHandler.PaymentService = &paymentService
+	apiHandler.RefundService = &refundService
+	apiHandler.ReturnService = &returnService
+	apiHandler.ShipmentService = &shipmentService
+	apiHandler.InvoiceService = &invoiceService
+	apiHandler.SubscriptionService = &subscriptionService
 
 	// Creación de un enrutador para manejar rutas HTTP
 	r := mux.NewRouter()
+	r.Use(api.Idempotency(idempotencyStore)) // Reintentos seguros de POST con Idempotency-Key
 
 	// Rutas y manejadores para productos
 	r.HandleFunc("/products", apiHandler.CreateProductHandler).Methods("POST")        // Crear producto
 	r.HandleFunc("/products", apiHandler.ListProductsHandler).Methods("GET")          // Listar productos
 	r.HandleFunc("/products/{id}", apiHandler.GetProductByIDHandler).Methods("GET")   // Obtener producto por ID
 	r.HandleFunc("/products/{id}", apiHandler.UpdateProductHandler).Methods("PUT")    // Actualizar producto
+	r.HandleFunc("/products/{id}", apiHandler.PatchProductHandler).Methods("PATCH")   // Actualizar parcialmente un producto
 	r.HandleFunc("/products/{id}", apiHandler.DeleteProductHandler).Methods("DELETE") // Eliminar producto
 
+	// Rutas y manejadores para el libro de inventario
+	r.HandleFunc("/products/{id}/stock-movements", apiHandler.ListStockMovementsHandler).Methods("GET")   // Historial de movimientos de stock
+	r.HandleFunc("/products/{id}/stock-movements", apiHandler.CreateStockMovementHandler).Methods("POST") // Registrar un ajuste de stock
+
+	// Rutas y manejadores para alertas de inventario
+	r.HandleFunc("/inventory/low-stock", apiHandler.ListLowStockHandler).Methods("GET") // Productos a reponer
+
+	// Rutas y manejadores para bodegas
+	r.HandleFunc("/warehouses", apiHandler.CreateWarehouseHandler).Methods("POST")                            // Crear bodega
+	r.HandleFunc("/warehouses", apiHandler.ListWarehousesHandler).Methods("GET")                              // Listar bodegas
+	r.HandleFunc("/warehouses/transfers", apiHandler.TransferStockHandler).Methods("POST")                    // Trasladar stock entre bodegas
+	r.HandleFunc("/warehouses/{id}/stock", apiHandler.GetWarehouseStockHandler).Methods("GET")                // Stock de una bodega
+	r.HandleFunc("/warehouses/{id}/stock", apiHandler.AdjustWarehouseStockHandler).Methods("POST")            // Ajustar stock en una bodega
+	r.HandleFunc("/products/{id}/warehouse-stock", apiHandler.GetProductWarehouseStockHandler).Methods("GET") // Stock de un producto por bodega
+
+	// Rutas y manejadores para el carrito (identificado por X-User-ID o X-Cart-ID)
+	r.HandleFunc("/cart", apiHandler.GetCartHandler).Methods("GET")                             // Obtener carrito
+	r.HandleFunc("/cart/items", apiHandler.AddCartItemHandler).Methods("POST")                  // Agregar producto
+	r.HandleFunc("/cart/items/{productId}", apiHandler.UpdateCartItemHandler).Methods("PUT")    // Cambiar cantidad
+	r.HandleFunc("/cart/items/{productId}", apiHandler.RemoveCartItemHandler).Methods("DELETE") // Quitar producto
+	r.HandleFunc("/cart/checkout", apiHandler.CheckoutCartHandler).Methods("POST")              // Convertir carrito en orden
+
+	// Rutas y manejadores para cupones de descuento
+	r.HandleFunc("/coupons", apiHandler.CreateCouponHandler).Methods("POST")    // Crear cupón
+	r.HandleFunc("/coupons", apiHandler.ListCouponsHandler).Methods("GET")      // Listar cupones
+	r.HandleFunc("/coupons/{code}", apiHandler.GetCouponHandler).Methods("GET") // Obtener cupón por código
+
+	// Rutas y manejadores para reglas de promoción
+	r.HandleFunc("/promotions", apiHandler.CreatePromotionHandler).Methods("POST")        // Crear regla
+	r.HandleFunc("/promotions", apiHandler.ListPromotionsHandler).Methods("GET")          // Listar reglas en orden de evaluación
+	r.HandleFunc("/promotions/{id}", apiHandler.GetPromotionHandler).Methods("GET")       // Obtener regla
+	r.HandleFunc("/promotions/{id}", apiHandler.UpdatePromotionHandler).Methods("PUT")    // Reemplazar regla
+	r.HandleFunc("/promotions/{id}", apiHandler.DeletePromotionHandler).Methods("DELETE") // Eliminar regla
+
 	// Rutas y manejadores para usuarios
-	r.HandleFunc("/users/register", apiHandler.RegisterUserHandler).Methods("POST") // Registrar usuario
-	r.HandleFunc("/users/login", apiHandler.LoginUserHandler).Methods("POST")       // Iniciar sesión de usuario
+	r.HandleFunc("/users/register", apiHandler.RegisterUserHandler).Methods("POST")    // Registrar usuario
+	r.HandleFunc("/users/login", apiHandler.LoginUserHandler).Methods("POST")          // Iniciar sesión de usuario
+	r.HandleFunc("/users/{id}", apiHandler.GetUserByIDHandler).Methods("GET")          // Obtener usuario por ID
+	r.HandleFunc("/users/{id}", apiHandler.PatchUserHandler).Methods("PATCH")          // Actualizar parcialmente el perfil
+	r.HandleFunc("/users/{id}/orders", apiHandler.GetUserOrdersHandler).Methods("GET") // Órdenes de un usuario
 
 	// Rutas y manejadores para órdenes
-	r.HandleFunc("/orders", apiHandler.CreateOrderHandler).Methods("POST")                       // Crear orden
-	r.HandleFunc("/orders/{userId}", apiHandler.GetUserOrdersHandler).Methods("GET")             // Obtener órdenes de un usuario
-	r.HandleFunc("/orders/{orderId}/status", apiHandler.UpdateOrderStatusHandler).Methods("PUT") // Actualizar estado de una orden
-	r.HandleFunc("/orders", apiHandler.ListAllOrdersHandler).Methods("GET")                      // Listar todas las órdenes
+	r.HandleFunc("/orders", apiHandler.CreateOrderHandler).Methods("POST")                              // Crear orden
+	r.HandleFunc("/orders/quote", apiHandler.QuoteOrderHandler).Methods("POST")                         // Cotizar orden sin crearla
+	r.HandleFunc("/orders/{id}", apiHandler.GetOrderHandler).Methods("GET")                             // Obtener orden (acepta la ruta obsoleta /orders/{userId})
+	r.HandleFunc("/orders/{orderId}/status", apiHandler.UpdateOrderStatusHandler).Methods("PUT")        // Actualizar estado de una orden
+	r.HandleFunc("/orders/{orderId}/items", apiHandler.AddOrderItemHandler).Methods("POST")             // Agregar producto a una orden pendiente
+	r.HandleFunc("/orders/{orderId}/items/{line}", apiHandler.UpdateOrderItemHandler).Methods("PATCH")  // Cambiar cantidad de una línea
+	r.HandleFunc("/orders/{orderId}/items/{line}", apiHandler.RemoveOrderItemHandler).Methods("DELETE") // Quitar una línea
+	r.HandleFunc("/orders/{orderId}/reorder", apiHandler.ReorderHandler).Methods("POST")                // Volver a comprar una orden
+	r.HandleFunc("/orders", apiHandler.ListAllOrdersHandler).Methods("GET")                             // Listar todas las órdenes
+
+	// Rutas y manejadores para pagos
+	r.HandleFunc("/orders/{orderId}/payments", apiHandler.CreatePaymentHandler).Methods("POST")    // Pagar una orden
+	r.HandleFunc("/orders/{orderId}/payments", apiHandler.ListOrderPaymentsHandler).Methods("GET") // Pagos de una orden
+	r.HandleFunc("/payments/{id}", apiHandler.GetPaymentHandler).Methods("GET")                    // Obtener pago
+	r.HandleFunc("/payments/{id}/capture", apiHandler.CapturePaymentHandler).Methods("POST")       // Cobrar pago autorizado
+	r.HandleFunc("/payments/{id}/void", apiHandler.VoidPaymentHandler).Methods("POST")             // Anular pago autorizado
+	r.HandleFunc("/payments/{id}/refund", apiHandler.RefundPaymentHandler).Methods("POST")         // Devolver lo cobrado
+
+	// Rutas y manejadores para reembolsos
+	r.HandleFunc("/orders/{orderId}/refunds", apiHandler.CreateRefundHandler).Methods("POST")    // Reembolsar una orden
+	r.HandleFunc("/orders/{orderId}/refunds", apiHandler.ListOrderRefundsHandler).Methods("GET") // Reembolsos de una orden
+
+	// Rutas y manejadores para devoluciones (RMA)
+	r.HandleFunc("/orders/{orderId}/returns", apiHandler.CreateReturnHandler).Methods("POST")    // Solicitar devolución
+	r.HandleFunc("/orders/{orderId}/returns", apiHandler.ListOrderReturnsHandler).Methods("GET") // Devoluciones de una orden
+	r.HandleFunc("/returns", apiHandler.ListReturnsHandler).Methods("GET")                       // Listar devoluciones
+	r.HandleFunc("/returns/{id}", apiHandler.GetReturnHandler).Methods("GET")                    // Obtener devolución
+	r.HandleFunc("/returns/{id}/approve", apiHandler.ApproveReturnHandler).Methods("POST")       // Aprobar
+	r.HandleFunc("/returns/{id}/reject", apiHandler.RejectReturnHandler).Methods("POST")         // Rechazar
+	r.HandleFunc("/returns/{id}/receive", apiHandler.ReceiveReturnHandler).Methods("POST")       // Registrar recepción
+	r.HandleFunc("/returns/{id}/inspect", apiHandler.InspectReturnHandler).Methods("POST")       // Inspeccionar y reembolsar
+	r.HandleFunc("/returns/{id}/refund", apiHandler.RefundReturnHandler).Methods("POST")         // Reintentar reembolso
+
+	// Rutas y manejadores para envíos
+	r.HandleFunc("/orders/{orderId}/shipments", apiHandler.CreateShipmentHandler).Methods("POST")    // Armar un envío
+	r.HandleFunc("/orders/{orderId}/shipments", apiHandler.ListOrderShipmentsHandler).Methods("GET") // Envíos de una orden
+	r.HandleFunc("/shipments/{id}", apiHandler.GetShipmentHandler).Methods("GET")                    // Obtener envío
+	r.HandleFunc("/shipments/{id}/ship", apiHandler.ShipShipmentHandler).Methods("POST")             // Despachar
+	r.HandleFunc("/shipments/{id}/deliver", apiHandler.DeliverShipmentHandler).Methods("POST")       // Confirmar entrega
+	r.HandleFunc("/shipments/{id}/tracking", apiHandler.SyncShipmentTrackingHandler).Methods("POST") // Consultar rastreo
+	r.HandleFunc("/shipments/{id}/label", apiHandler.GetShipmentLabelHandler).Methods("GET")         // Descargar guía
+
+	// Rutas y manejadores para facturación
+	r.HandleFunc("/orders/{orderId}/invoices", apiHandler.ListOrderInvoicesHandler).Methods("GET")      // Documentos de una orden
+	r.HandleFunc("/orders/{orderId}/invoices", apiHandler.IssueInvoiceHandler).Methods("POST")          // Emitir la factura a mano
+	r.HandleFunc("/invoices", apiHandler.ListInvoicesHandler).Methods("GET")                            // Listar documentos
+	r.HandleFunc("/invoices/{number}", apiHandler.GetInvoiceHandler).Methods("GET")                     // Obtener documento
+	r.HandleFunc("/invoices/{number}/html", apiHandler.GetInvoiceHTMLHandler).Methods("GET")            // Documento en HTML
+	r.HandleFunc("/invoices/{number}/pdf", apiHandler.GetInvoicePDFHandler).Methods("GET")              // Documento en PDF
+	r.HandleFunc("/invoices/{number}/credit-notes", apiHandler.CreateCreditNoteHandler).Methods("POST") // Emitir nota de crédito
+
+	// Rutas y manejadores para suscripciones
+	r.HandleFunc("/subscriptions", apiHandler.CreateSubscriptionHandler).Methods("POST")             // Crear suscripción
+	r.HandleFunc("/subscriptions", apiHandler.ListSubscriptionsHandler).Methods("GET")               // Suscripciones del usuario
+	r.HandleFunc("/subscriptions/{id}", apiHandler.GetSubscriptionHandler).Methods("GET")            // Obtener suscripción
+	r.HandleFunc("/subscriptions/{id}/pause", apiHandler.PauseSubscriptionHandler).Methods("POST")   // Pausar
+	r.HandleFunc("/subscriptions/{id}/resume", apiHandler.ResumeSubscriptionHandler).Methods("POST") // Reanudar
+	r.HandleFunc("/subscriptions/{id}/skip", apiHandler.SkipSubscriptionHandler).Methods("POST")     // Saltar entregas
+	r.HandleFunc("/subscriptions/{id}/cancel", apiHandler.CancelSubscriptionHandler).Methods("POST") // Cancelar
 
 	// Configuración del puerto del servidor
 	port := ":8080" // Puerto en el que el servidor escuchará
@@ -69,12 +399,44 @@ func main() {
 		IdleTimeout:  60 * time.Second, // Tiempo de inactividad
 	}
 
+	// Apagado ordenado con CTRL+C o SIGTERM: deja de aceptar solicitudes, termina las en curso
+	// y detiene las tareas en segundo plano
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	shutdownDone := make(chan struct{}) // Se cierra cuando terminan las solicitudes en curso
+	go func() {
+		defer close(shutdownDone)
+		<-stop
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error al detener servidor: %v\n", err)
+		}
+	}()
+
 	// Inicio del servidor y manejo de errores
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Error al iniciar servidor: %v\n", err) // Registro del error en caso de fallo
 	}
+	<-shutdownDone  // ListenAndServe retorna al empezar Shutdown; esperar a que terminen las solicitudes en curso
+	cancel()        // Detener las tareas en segundo plano
+	<-schedulerDone // Esperar a que terminen las tareas programadas en curso
+	<-trackingDone  // Esperar a que termine la consulta de rastreo en curso
 	// Mensaje al detener el servidor
 	fmt.Println("Servidor detenido.")
 }
 
 //29-6-2025
+
+// Lee una variable de entorno numérica (0 si no está definida)
+func envFloat(name string) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Valor inválido para %s: %v\n", name, err)
+	}
+	return f
+}
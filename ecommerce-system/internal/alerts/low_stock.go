@@ -0,0 +1,82 @@
+// Paquete para alertas de inventario
+package alerts
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de mensajes
+	"log"     // Registro de eventos
+	"strconv" // Conversión de números a texto
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/notifications" // Canales de notificación
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"      // Productos observados
+)
+
+// Tipo de notificación emitida cuando un producto cae bajo su umbral
+const KindLowStock = "low_stock"
+
+// LowStockChecker revisa en segundo plano cada cambio de stock y alerta cuando un producto
+// llega a su umbral de reposición. Solo alerta una vez hasta que el stock vuelve a subir.
+type LowStockChecker struct {
+	notifier notifications.Notifier // Canal por el que se envían las alertas
+	events   chan products.Product  // Cambios de stock pendientes de revisar
+	alerted  map[string]bool        // Productos ya alertados (solo lo usa la goroutine de Run)
+}
+
+// Constructor para crear un verificador de stock bajo con una cola de tamaño buffer
+func NewLowStockChecker(notifier notifications.Notifier, buffer int) *LowStockChecker {
+	return &LowStockChecker{
+		notifier: notifier,
+		events:   make(chan products.Product, buffer),
+		alerted:  make(map[string]bool),
+	}
+}
+
+// Recibe un cambio de stock del servicio de productos sin bloquearlo
+func (c *LowStockChecker) StockChanged(p products.Product) {
+	select {
+	case c.events <- p:
+	default:
+		log.Printf("Cola de alertas de stock llena, se omite la revisión de %s\n", p.ID)
+	}
+}
+
+// Procesa los cambios de stock hasta que se cancele el contexto
+func (c *LowStockChecker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-c.events:
+			c.check(ctx, p)
+		}
+	}
+}
+
+// Revisa un producto y envía la alerta si corresponde
+func (c *LowStockChecker) check(ctx context.Context, p products.Product) {
+	if !p.IsLowStock() {
+		delete(c.alerted, p.ID) // Se repuso: la próxima caída vuelve a alertar
+		return
+	}
+	if c.alerted[p.ID] {
+		return
+	}
+	err := c.notifier.Notify(ctx, notifications.Notification{
+		Kind:    KindLowStock,
+		Subject: fmt.Sprintf("Stock bajo: %s", p.Name),
+		Message: fmt.Sprintf("El producto %s (%s) tiene %d unidades; umbral de reposición %d", p.Name, p.ID, p.Stock, p.ReorderThreshold),
+		Data: map[string]string{
+			"product_id":        p.ID,
+			"sku":               p.SKU,
+			"stock":             strconv.Itoa(p.Stock),
+			"reorder_threshold": strconv.Itoa(p.ReorderThreshold),
+		},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("No se pudo enviar la alerta de stock bajo de %s: %v\n", p.ID, err)
+		return // Se reintentará en el próximo cambio de stock
+	}
+	c.alerted[p.ID] = true
+}
@@ -0,0 +1,181 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"crypto/rand"   // Generación de tokens de carrito anónimo
+	"encoding/hex"  // Codificación de tokens
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+	"strings"       // Manipulación de cadenas
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/cart"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+)
+
+// --- MANEJADORES DEL CARRITO ---
+
+// Determina la clave del carrito: el del usuario si envía X-User-ID, si no el anónimo de X-Cart-ID.
+// Si create es verdadero y no hay identificación, crea un carrito anónimo y devuelve su token en X-Cart-ID.
+func cartKey(w http.ResponseWriter, r *http.Request, create bool) (string, bool) {
+	if userID := requesterID(r); userID != "" {
+		return cart.UserCartKey(userID), true
+	}
+	token := strings.TrimSpace(r.Header.Get(headerCartID))
+	if token == "" {
+		if !create {
+			return "", false
+		}
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		token = hex.EncodeToString(buf)
+	}
+	w.Header().Set(headerCartID, token) // El cliente debe reenviar este token en las siguientes solicitudes
+	return cart.AnonymousCartKey(token), true
+}
+
+// Responde el error correspondiente a una operación del carrito
+func respondCartError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, products.ErrProductNotFound), errors.Is(err, cart.ErrItemNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, products.ErrorStockInsuficiente), errors.Is(err, cart.ErrCartNotPurchasable):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondOrderError(w, err) // Errores del checkout al crear la orden (por ejemplo, cupones)
+	}
+}
+
+// Obtener el carrito con precios y stock actuales
+func (h *Handler) GetCartHandler(w http.ResponseWriter, r *http.Request) {
+	key, ok := cartKey(w, r, false)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Se requiere "+headerUserID+" o "+headerCartID)
+		return
+	}
+	view, err := (*h.CartService).GetCart(context.Background(), key)
+	if err != nil {
+		respondCartError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, view) // Responde con el carrito valorizado
+}
+
+// Agregar un producto al carrito
+func (h *Handler) AddCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	var req cart.AddItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	key, _ := cartKey(w, r, true)
+	view, err := (*h.CartService).AddItem(context.Background(), key, req)
+	if err != nil {
+		respondCartError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, view) // Responde con el carrito actualizado
+}
+
+// Cambiar la cantidad de un producto del carrito
+func (h *Handler) UpdateCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req cart.UpdateItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	key, ok := cartKey(w, r, false)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Se requiere "+headerUserID+" o "+headerCartID)
+		return
+	}
+	view, err := (*h.CartService).UpdateItem(context.Background(), key, vars["productId"], req)
+	if err != nil {
+		respondCartError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, view) // Responde con el carrito actualizado
+}
+
+// Quitar un producto del carrito
+func (h *Handler) RemoveCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := cartKey(w, r, false)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Se requiere "+headerUserID+" o "+headerCartID)
+		return
+	}
+	view, err := (*h.CartService).RemoveItem(context.Background(), key, vars["productId"])
+	if err != nil {
+		respondCartError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, view) // Responde con el carrito actualizado
+}
+
+// Convertir el carrito del usuario en una orden
+func (h *Handler) CheckoutCartHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para finalizar la compra")
+		return
+	}
+	var req cart.CheckoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+			return
+		}
+	}
+	order, err := (*h.CartService).Checkout(context.Background(), userID, req)
+	if err != nil {
+		respondCartError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, order) // Responde con la orden creada
+}
+
+// Volver a comprar los productos de una orden anterior (orden nueva o carrito)
+func (h *Handler) ReorderHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para repetir una orden")
+		return
+	}
+	var req cart.ReorderRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+			return
+		}
+	}
+	result, err := (*h.CartService).Reorder(context.Background(), userID, vars["orderId"], req)
+	switch {
+	case errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, orders.ErrNotOrderOwner):
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, cart.ErrNothingToReorder):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case errors.Is(err, cart.ErrInvalidTarget):
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		respondCartError(w, err)
+		return
+	}
+	if result.Order != nil {
+		respondJSON(w, http.StatusCreated, result) // Responde con la orden creada y los cambios
+		return
+	}
+	respondJSON(w, http.StatusOK, result) // Responde con el carrito y los cambios
+}
@@ -0,0 +1,91 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+	"time"          // Manejo de tiempos y fechas
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// --- MANEJADORES DE CUPONES ---
+
+// Traduce los errores de cupones al código HTTP correspondiente
+func respondCouponError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, coupons.ErrCouponNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, coupons.ErrInvalidCoupon):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusUnprocessableEntity, err.Error()) // Cupón existente que no aplica a la orden
+	}
+}
+
+// Traduce los errores al crear una orden (los de cupones se distinguen del resto)
+func respondOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, coupons.ErrCouponNotFound), errors.Is(err, coupons.ErrCouponNotYetValid),
+		errors.Is(err, coupons.ErrCouponExpired), errors.Is(err, coupons.ErrCouponUsageLimit),
+		errors.Is(err, coupons.ErrCouponUserLimit), errors.Is(err, coupons.ErrMinimumNotReached),
+		errors.Is(err, coupons.ErrCouponNotApplicable),
+		errors.Is(err, shipping.ErrInvalidAddress), errors.Is(err, shipping.ErrUnsupportedCountry),
+		errors.Is(err, shipping.ErrAddressRequired), errors.Is(err, shipping.ErrNoShippingZone),
+		errors.Is(err, shipping.ErrOverweight):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// Crear un cupón (solo administradores)
+func (h *Handler) CreateCouponHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "crear cupones") {
+		return
+	}
+	var req coupons.CouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	c, err := (*h.CouponService).CreateCoupon(context.Background(), req)
+	if err != nil {
+		respondCouponError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, c) // Responde con el cupón creado
+}
+
+// Listar todos los cupones (solo administradores)
+func (h *Handler) ListCouponsHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "listar los cupones") {
+		return
+	}
+	list, err := (*h.CouponService).ListCoupons(context.Background())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con la lista de cupones
+}
+
+// Obtener un cupón por su código: el administrador lo ve completo y el resto solo puede validarlo
+func (h *Handler) GetCouponHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	c, err := (*h.CouponService).GetCoupon(context.Background(), vars["code"])
+	if err != nil {
+		respondCouponError(w, err)
+		return
+	}
+	if !h.isAdmin(r.Context(), requesterID(r)) {
+		respondJSON(w, http.StatusOK, c.Validation(time.Now())) // Responde solo con las condiciones del cupón
+		return
+	}
+	respondJSON(w, http.StatusOK, c) // Responde con el cupón
+}
@@ -0,0 +1,57 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"errors"   // Manejo de errores
+	"net/http" // Manejo de solicitudes HTTP
+	"strconv"  // Conversión de texto a número
+	"strings"  // Manipulación de cadenas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+)
+
+// Errores al interpretar el encabezado If-Match
+var (
+	errIfMatchMissing = errors.New("se requiere el encabezado If-Match")
+	errIfMatchInvalid = errors.New("encabezado If-Match inválido")
+)
+
+// Escribe el encabezado ETag a partir de la versión del recurso
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// Obtiene la versión esperada desde el encabezado If-Match (acepta ETags débiles W/"n")
+func parseIfMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		return 0, errIfMatchMissing
+	}
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, errIfMatchInvalid
+	}
+	return version, nil
+}
+
+// Lee If-Match y responde el error correspondiente; retorna false si la solicitud no debe continuar
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, err := parseIfMatch(r)
+	if errors.Is(err, errIfMatchMissing) {
+		respondError(w, http.StatusPreconditionRequired, err.Error())
+		return 0, false
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	return version, true
+}
+
+// Indica si el error corresponde a una escritura con versión desactualizada
+func isVersionConflict(err error) bool {
+	return errors.Is(err, products.ErrVersionConflict) || errors.Is(err, orders.ErrVersionConflict)
+}
@@ -5,15 +5,27 @@ package api
 import (
 	"context"       // Manejo de contexto en solicitudes
 	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
 	"fmt"           // Salida estándar
 	"net/http"      // Manejo de solicitudes HTTP
+	"strconv"       // Encabezado con el total de resultados
 
 	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
 
 	// Módulos internos para usuarios, productos y órdenes
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/cart"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/invoicing"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/returns"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/subscriptions"
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/users"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses"
 )
 
 // Estructura para errores en la API
@@ -27,6 +39,18 @@ type Handler struct {
 	ProductService *products.Service // Servicio de productos
 	UserService    *users.Service    // Servicio de usuarios
 	OrderService   *orders.Service   // Servicio de órdenes
+
+	// Servicios de subsistemas opcionales (se asignan después de NewHandler)
+	WarehouseService    *warehouses.Service    // Servicio de bodegas
+	CartService         *cart.Service          // Servicio de carritos
+	CouponService       *coupons.Service       // Servicio de cupones
+	PromotionService    *promotions.Service    // Servicio de promociones
+	PaymentService      *payments.Service      // Servicio de pagos
+	RefundService       *refunds.Service       // Servicio de reembolsos
+	ReturnService       *returns.Service       // Servicio de devoluciones
+	ShipmentService     *shipments.Service     // Servicio de envíos
+	InvoiceService      *invoicing.Service     // Servicio de facturación
+	SubscriptionService *subscriptions.Service // Servicio de suscripciones
 }
 
 // Constructor para inicializar el manejador con los servicios
@@ -65,7 +89,7 @@ func (h *Handler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
 		return
 	}
-	prod, err := (*h.ProductService).CreateProduct(context.Background(), req.Name, req.Description, req.Price, req.Stock, req.Category)
+	prod, err := (*h.ProductService).CreateProduct(context.Background(), req)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -92,6 +116,7 @@ func (h *Handler) GetProductByIDHandler(w http.ResponseWriter, r *http.Request)
 		respondError(w, http.StatusNotFound, "Producto no encontrado")
 		return
 	}
+	setETag(w, prod.Version)            // Versión actual para usar en If-Match
 	respondJSON(w, http.StatusOK, prod) // Responde con el producto encontrado
 }
 
@@ -99,19 +124,136 @@ func (h *Handler) GetProductByIDHandler(w http.ResponseWriter, r *http.Request)
 func (h *Handler) UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
 	var req products.ProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
 		return
 	}
-	updatedProd, err := (*h.ProductService).UpdateProduct(context.Background(), id, req.Name, req.Description, req.Price, req.Stock, req.Category)
+	if prod, err := (*h.ProductService).GetProductByID(r.Context(), id); err == nil && prod.Stock != req.Stock &&
+		!h.allowProductStockChange(w, r, id) {
+		return
+	}
+	updatedProd, err := (*h.ProductService).UpdateProduct(context.Background(), id, version, req)
+	if isVersionConflict(err) {
+		respondError(w, http.StatusPreconditionFailed, "El producto fue modificado por otra solicitud")
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	setETag(w, updatedProd.Version)
 	respondJSON(w, http.StatusOK, updatedProd) // Responde con el producto actualizado
 }
 
+// Actualizar parcialmente un producto (JSON Merge Patch o JSON Patch)
+func (h *Handler) PatchProductHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
+	prod, err := (*h.ProductService).GetProductByID(context.Background(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Producto no encontrado")
+		return
+	}
+	if prod.Version != version {
+		respondError(w, http.StatusPreconditionFailed, "El producto fue modificado por otra solicitud")
+		return
+	}
+	var req products.ProductRequest
+	if !applyPatchRequest(w, r, prod.ToRequest(), &req) {
+		return
+	}
+	if req.Stock != prod.Stock && !h.allowProductStockChange(w, r, id) {
+		return
+	}
+	updatedProd, err := (*h.ProductService).UpdateProduct(context.Background(), id, version, req)
+	if isVersionConflict(err) {
+		respondError(w, http.StatusPreconditionFailed, "El producto fue modificado por otra solicitud")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	setETag(w, updatedProd.Version)
+	respondJSON(w, http.StatusOK, updatedProd) // Responde con el producto actualizado
+}
+
+// Listar el historial de movimientos de stock de un producto
+func (h *Handler) ListStockMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	prod, err := (*h.ProductService).GetProductByID(context.Background(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Producto no encontrado")
+		return
+	}
+	movements, err := (*h.ProductService).ListStockMovements(context.Background(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"product_id": prod.ID,
+		"stock":      prod.Stock,
+		"movements":  movements,
+	}) // Responde con el stock actual y su historial
+}
+
+// Registrar un ajuste de stock (reposición, merma, corrección, etc.)
+func (h *Handler) CreateStockMovementHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var req products.StockAdjustment
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	if !h.requireAdmin(w, r, "ajustar el stock de un producto") {
+		return
+	}
+	// Ventas, devoluciones y cancelaciones las registran sus propios flujos
+	if !req.Reason.IsManual() {
+		respondError(w, http.StatusBadRequest, "Motivo no permitido: use restock, shrinkage o manual_correction")
+		return
+	}
+	req.Actor = requesterID(r) // El actor es quien hace la solicitud, no lo que diga el cuerpo
+	if !h.allowProductStockChange(w, r, vars["id"]) {
+		return
+	}
+	prod, err := (*h.ProductService).AdjustStock(context.Background(), vars["id"], req)
+	if errors.Is(err, products.ErrProductNotFound) {
+		respondError(w, http.StatusNotFound, "Producto no encontrado")
+		return
+	}
+	if errors.Is(err, products.ErrorStockInsuficiente) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusCreated, prod) // Responde con el producto y su nuevo stock
+}
+
+// Listar los productos con stock en o bajo su umbral de reposición
+func (h *Handler) ListLowStockHandler(w http.ResponseWriter, r *http.Request) {
+	prods, err := (*h.ProductService).ListLowStock(context.Background())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, prods) // Responde con los productos a reponer
+}
+
 // --- MANEJADORES DE USUARIOS ---
 
 // Registrar un nuevo usuario
@@ -141,9 +283,65 @@ func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusUnauthorized, "Credenciales inválidas")
 		return
 	}
+	// Si el cliente traía un carrito anónimo, se fusiona con el carrito del usuario
+	if token := r.Header.Get(headerCartID); token != "" && h.CartService != nil {
+		(*h.CartService).MergeAnonymous(context.Background(), cart.AnonymousCartKey(token), user.ID)
+	}
 	respondJSON(w, http.StatusOK, user) // Responde con los datos del usuario autenticado
 }
 
+// Verifica que quien hace la solicitud sea el propio usuario o un administrador
+func (h *Handler) authorizeUser(w http.ResponseWriter, r *http.Request, userID string) bool {
+	requester := requesterID(r)
+	if requester == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para acceder a un perfil")
+		return false
+	}
+	if requester != userID && !h.isAdmin(r.Context(), requester) {
+		respondError(w, http.StatusForbidden, "Solo el propio usuario o un administrador pueden acceder a su perfil")
+		return false
+	}
+	return true
+}
+
+// Obtener un usuario por su ID (el propio usuario o un administrador)
+func (h *Handler) GetUserByIDHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeUser(w, r, vars["id"]) {
+		return
+	}
+	user, err := (*h.UserService).GetUserByID(context.Background(), vars["id"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Usuario no encontrado")
+		return
+	}
+	respondJSON(w, http.StatusOK, user) // Responde con el usuario encontrado
+}
+
+// Actualizar parcialmente el perfil de un usuario (JSON Merge Patch o JSON Patch); el propio usuario o un administrador
+func (h *Handler) PatchUserHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if !h.authorizeUser(w, r, id) {
+		return
+	}
+	user, err := (*h.UserService).GetUserByID(context.Background(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Usuario no encontrado")
+		return
+	}
+	var req users.UserProfileRequest
+	if !applyPatchRequest(w, r, user.ToProfileRequest(), &req) {
+		return
+	}
+	updatedUser, err := (*h.UserService).UpdateProfile(context.Background(), id, req)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, updatedUser) // Responde con el usuario actualizado
+}
+
 // --- MANEJADORES DE ÓRDENES ---
 
 // Crear una nueva orden
@@ -153,40 +351,176 @@ func (h *Handler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
 		return
 	}
-	order, err := (*h.OrderService).CreateOrder(context.Background(), req.UserID, req.LineItems)
+	order, err := (*h.OrderService).CreateOrder(context.Background(), req)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondOrderError(w, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, order) // Responde con la orden creada
 }
 
-// Listar órdenes de un usuario
+// Cotizar una orden sin crearla (mismas validaciones y precios que la creación, sin apartar stock)
+func (h *Handler) QuoteOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req orders.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	quote, err := (*h.OrderService).QuoteOrder(context.Background(), req)
+	if err != nil {
+		respondOrderError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, quote) // Responde con el desglose de precios
+}
+
+// Obtener una orden por su ID; solo su dueño o un administrador pueden verla.
+// Si el ID no es de una orden se atiende la ruta antigua GET /orders/{userId} (obsoleta)
+func (h *Handler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	requester := requesterID(r)
+	var order *orders.Order
+	var err error
+	if h.isAdmin(r.Context(), requester) {
+		order, err = (*h.OrderService).GetOrderByID(context.Background(), id)
+	} else {
+		order, err = (*h.OrderService).GetOwnedOrder(context.Background(), id, requester)
+	}
+	switch {
+	case errors.Is(err, orders.ErrOrderNotFound):
+		h.legacyUserOrders(w, r, id)
+		return
+	case errors.Is(err, orders.ErrNotOrderOwner) && requester == "":
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar una orden")
+		return
+	case errors.Is(err, orders.ErrNotOrderOwner):
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	setETag(w, order.Version)
+	respondJSON(w, http.StatusOK, order) // Responde con la orden
+}
+
+// Obtener las órdenes de un usuario (mismos filtros y paginación que GET /orders); solo el propio usuario o un administrador
 func (h *Handler) GetUserOrdersHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	userID := vars["userId"]
-	orders, err := (*h.OrderService).GetOrdersByUserID(context.Background(), userID)
+	userID := vars["id"]
+	requester := requesterID(r)
+	if requester == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar órdenes")
+		return
+	}
+	if requester != userID && !h.isAdmin(r.Context(), requester) {
+		respondError(w, http.StatusForbidden, "Solo el propio usuario o un administrador pueden ver sus órdenes")
+		return
+	}
+	filter, err := parseOrderFilter(r.URL.Query())
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Usuario no encontrado")
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.UserID = userID
+	page, err := (*h.OrderService).SearchOrders(context.Background(), filter)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	respondJSON(w, http.StatusOK, orders) // Responde con las órdenes del usuario
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	respondJSON(w, http.StatusOK, page.Orders) // Responde con las órdenes del usuario
+}
+
+// Fecha de retiro anunciada para la ruta antigua GET /orders/{userId} (encabezado Sunset, RFC 8594)
+const legacyOrdersSunset = "Sun, 28 Feb 2027 00:00:00 GMT"
+
+// Ruta antigua GET /orders/{userId}: responde como antes pero anuncia su retiro y la ruta que la reemplaza;
+// igual que GET /users/{id}/orders, solo el propio usuario o un administrador
+func (h *Handler) legacyUserOrders(w http.ResponseWriter, r *http.Request, userID string) {
+	requester := requesterID(r)
+	if requester == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar órdenes")
+		return
+	}
+	if requester != userID && !h.isAdmin(r.Context(), requester) {
+		respondError(w, http.StatusNotFound, "Orden no encontrada") // No revela si existe un usuario con ese ID
+		return
+	}
+	list, err := (*h.OrderService).GetOrdersByUserID(context.Background(), userID)
+	if err == nil && len(list) == 0 {
+		if _, userErr := (*h.UserService).GetUserByID(context.Background(), userID); userErr != nil {
+			err = orders.ErrOrderNotFound // Ni orden ni usuario con ese ID
+		}
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Orden no encontrada")
+		return
+	}
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Sunset", legacyOrdersSunset)
+	w.Header().Set("Link", "</users/"+userID+`/orders>; rel="successor-version"`)
+	respondJSON(w, http.StatusOK, list) // Responde con las órdenes del usuario
 }
 
 // Actualizar el estado de una orden
 func (h *Handler) UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	orderID := vars["orderId"]
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
 	var req orders.UpdateOrderStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
 		return
 	}
-	updatedOrder, err := (*h.OrderService).UpdateOrderStatus(context.Background(), orderID, req.Status)
+	if !h.authorizeOrder(w, r, orderID) {
+		return
+	}
+	// El cliente solo puede cancelar; los demás estados los fijan los pagos y envíos, o a mano un administrador
+	if req.Status != orders.StatusCancelled && !h.requireAdmin(w, r, "marcar una orden como "+string(req.Status)) {
+		return
+	}
+	if req.Status == orders.StatusCancelled {
+		// Antes de cancelar se anulan los pagos autorizados sin cobrar para liberar el dinero reservado
+		current, err := (*h.OrderService).GetOrderByID(context.Background(), orderID)
+		if err != nil {
+			respondOrderError(w, err)
+			return
+		}
+		if current.Version != version {
+			respondError(w, http.StatusPreconditionFailed, "La orden fue modificada por otra solicitud")
+			return
+		}
+		if current.AuthorizedAmount > 0 {
+			if err := h.voidAuthorizations(context.Background(), orderID); err != nil {
+				respondPaymentError(w, err)
+				return
+			}
+			if current, err = (*h.OrderService).GetOrderByID(context.Background(), orderID); err != nil {
+				respondOrderError(w, err)
+				return
+			}
+			version = current.Version // La anulación cambió la versión de la orden
+		}
+	}
+	updatedOrder, err := (*h.OrderService).UpdateOrderStatus(context.Background(), orderID, req.Status, version)
+	if isVersionConflict(err) {
+		respondError(w, http.StatusPreconditionFailed, "La orden fue modificada por otra solicitud")
+		return
+	}
+	if errors.Is(err, orders.ErrPaymentRequired) || errors.Is(err, orders.ErrInvalidTransition) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	setETag(w, updatedOrder.Version)
 	respondJSON(w, http.StatusOK, updatedOrder) // Responde con la orden actualizada
 }
 
@@ -194,7 +528,15 @@ func (h *Handler) UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Reques
 func (h *Handler) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	err := (*h.ProductService).DeleteProduct(context.Background(), id)
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
+	err := (*h.ProductService).DeleteProduct(context.Background(), id, version)
+	if isVersionConflict(err) {
+		respondError(w, http.StatusPreconditionFailed, "El producto fue modificado por otra solicitud")
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Producto no encontrado para eliminar")
 		return
@@ -202,8 +544,22 @@ func (h *Handler) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusNoContent, nil) // Responde con estado No Content
 }
 
-// Listar todas las órdenes
+// Listar órdenes con filtros, orden y paginación; el total de coincidencias va en X-Total-Count.
+// Solo administradores (cada usuario consulta las suyas en GET /users/{id}/orders)
 func (h *Handler) ListAllOrdersHandler(w http.ResponseWriter, r *http.Request) {
-	allOrders := (*h.OrderService).ListAllOrders(context.Background())
-	respondJSON(w, http.StatusOK, allOrders) // Responde con la lista de todas las órdenes
+	if !h.requireAdmin(w, r, "listar todas las órdenes") {
+		return
+	}
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := (*h.OrderService).SearchOrders(context.Background(), filter)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	respondJSON(w, http.StatusOK, page.Orders) // Responde con la página de órdenes
 }
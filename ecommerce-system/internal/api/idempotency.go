@@ -0,0 +1,93 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"bytes"         // Copia del cuerpo de la solicitud
+	"crypto/sha256" // Huella de la solicitud
+	"encoding/hex"  // Representación de la huella
+	"errors"        // Comparación de errores
+	"io"            // Lectura del cuerpo
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/idempotency"
+)
+
+// Encabezados de idempotencia
+const (
+	headerIdempotencyKey = "Idempotency-Key"     // Clave que envía el cliente al reintentar
+	headerReplayed       = "Idempotent-Replayed" // Indica que la respuesta es una repetición
+)
+
+// Middleware que hace idempotentes las solicitudes POST que incluyen Idempotency-Key:
+// la primera respuesta se guarda y se repite ante reintentos con la misma clave y el mismo cuerpo.
+func Idempotency(store idempotency.Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(headerIdempotencyKey)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "No se pudo leer la solicitud")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body)) // El manejador vuelve a leer el cuerpo
+
+			// La clave es propia de cada usuario y ruta; la huella detecta cuerpos distintos con la misma clave
+			scopedKey := requesterID(r) + " " + r.URL.Path + " " + key
+			sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+			fingerprint := hex.EncodeToString(sum[:])
+
+			rec, err := store.Begin(scopedKey, fingerprint)
+			switch {
+			case errors.Is(err, idempotency.ErrFingerprintMismatch), errors.Is(err, idempotency.ErrInProgress):
+				respondError(w, http.StatusConflict, err.Error())
+				return
+			case rec != nil:
+				for name, values := range rec.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(headerReplayed, "true")
+				w.WriteHeader(rec.Status)
+				w.Write(rec.Body)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if recorder.status >= http.StatusInternalServerError {
+				store.Abort(scopedKey) // Los errores del servidor se pueden reintentar
+				return
+			}
+			store.Complete(scopedKey, idempotency.Record{
+				Fingerprint: fingerprint,
+				Status:      recorder.status,
+				Header:      w.Header().Clone(),
+				Body:        recorder.body.Bytes(),
+			})
+		})
+	}
+}
+
+// responseRecorder copia la respuesta mientras se envía al cliente
+type responseRecorder struct {
+	http.ResponseWriter
+	status int          // Código HTTP enviado
+	body   bytes.Buffer // Cuerpo enviado
+}
+
+// Registra el código HTTP
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Registra el cuerpo
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,130 @@
+// Pruebas del middleware de idempotencia con el almacén en memoria
+package api_test
+
+import (
+	"fmt"               // Cuerpos de respuesta numerados
+	"net/http"          // Manejo de solicitudes HTTP
+	"net/http/httptest" // Servidor de prueba
+	"strings"           // Cuerpos de solicitud
+	"sync/atomic"       // Contador de llamadas al manejador
+	"testing"           // Paquete de pruebas
+	"time"              // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/api"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/idempotency"
+)
+
+// Manejador que crea un recurso numerado en cada llamada y responde status
+func countingHandler(calls *int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		w.Header().Set("Location", fmt.Sprintf("/orders/%d", n))
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"id":%d}`, n)
+	})
+}
+
+// Envía un POST con la clave y el cuerpo indicados
+func post(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	req.Header.Set("X-User-ID", "user-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	var calls int32
+	handler := api.Idempotency(idempotency.NewInMemoryStore(time.Hour))(countingHandler(&calls, http.StatusCreated))
+
+	first := post(handler, "k1", `{"qty":1}`)
+	second := post(handler, "k1", `{"qty":1}`)
+	if calls != 1 {
+		t.Fatalf("llamadas al manejador = %d, se esperaba 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("repetición = %d %s, se esperaba %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Location") != "/orders/1" || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("encabezados repetidos = %v", second.Header())
+	}
+
+	// Otra clave crea otro recurso
+	if third := post(handler, "k2", `{"qty":1}`); third.Body.String() != `{"id":2}` {
+		t.Fatalf("otra clave = %s, se esperaba un recurso nuevo", third.Body)
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	var calls int32
+	handler := api.Idempotency(idempotency.NewInMemoryStore(time.Hour))(countingHandler(&calls, http.StatusCreated))
+
+	post(handler, "k1", `{"qty":1}`)
+	if rr := post(handler, "k1", `{"qty":2}`); rr.Code != http.StatusConflict {
+		t.Fatalf("código = %d, se esperaba %d", rr.Code, http.StatusConflict)
+	}
+	if calls != 1 {
+		t.Fatalf("llamadas al manejador = %d, se esperaba 1", calls)
+	}
+}
+
+func TestIdempotencyKeyExpires(t *testing.T) {
+	var calls int32
+	handler := api.Idempotency(idempotency.NewInMemoryStore(20 * time.Millisecond))(countingHandler(&calls, http.StatusCreated))
+
+	post(handler, "k1", `{"qty":1}`)
+	time.Sleep(30 * time.Millisecond)
+	// Vencida la ventana, la misma clave (incluso con otro cuerpo) es una solicitud nueva
+	if rr := post(handler, "k1", `{"qty":2}`); rr.Code != http.StatusCreated || rr.Body.String() != `{"id":2}` {
+		t.Fatalf("tras expirar = %d %s, se esperaba un recurso nuevo", rr.Code, rr.Body)
+	}
+}
+
+func TestIdempotencyRetriesServerErrors(t *testing.T) {
+	var calls int32
+	handler := api.Idempotency(idempotency.NewInMemoryStore(time.Hour))(countingHandler(&calls, http.StatusServiceUnavailable))
+
+	post(handler, "k1", `{"qty":1}`)
+	if rr := post(handler, "k1", `{"qty":1}`); rr.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("se repitió un error del servidor")
+	}
+	if calls != 2 {
+		t.Fatalf("llamadas al manejador = %d, se esperaba 2", calls)
+	}
+}
+
+func TestIdempotencyIgnoresRequestsWithoutKey(t *testing.T) {
+	var calls int32
+	handler := api.Idempotency(idempotency.NewInMemoryStore(time.Hour))(countingHandler(&calls, http.StatusCreated))
+
+	post(handler, "", `{"qty":1}`)
+	post(handler, "", `{"qty":1}`)
+	if calls != 2 {
+		t.Fatalf("llamadas al manejador = %d, se esperaba 2", calls)
+	}
+}
+
+func TestIdempotencyRejectsConcurrentRetry(t *testing.T) {
+	store := idempotency.NewInMemoryStore(time.Hour)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := api.Idempotency(store)(slow)
+
+	done := make(chan struct{})
+	go func() {
+		post(handler, "k1", `{"qty":1}`)
+		close(done)
+	}()
+	<-started
+	if rr := post(handler, "k1", `{"qty":1}`); rr.Code != http.StatusConflict {
+		t.Fatalf("reintento en curso: código = %d, se esperaba %d", rr.Code, http.StatusConflict)
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,79 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"  // Manejo de contexto en solicitudes
+	"errors"   // Comparación de errores
+	"net/http" // Manejo de solicitudes HTTP
+	"strings"  // Manipulación de cadenas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/users"
+)
+
+// Encabezados con los que el cliente se identifica (el sistema aún no emite tokens de sesión)
+const (
+	headerUserID = "X-User-ID" // ID del usuario que realiza la solicitud
+	headerCartID = "X-Cart-ID" // Token de un carrito anónimo
+)
+
+// Obtiene el ID del usuario que realiza la solicitud (vacío si es anónimo)
+func requesterID(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(headerUserID))
+}
+
+// Indica si el usuario tiene el rol de administrador
+func (h *Handler) isAdmin(ctx context.Context, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	u, err := (*h.UserService).GetUserByID(ctx, userID)
+	if err != nil {
+		return false
+	}
+	for _, role := range u.Roles {
+		if string(role) == string(users.RolAdministrador) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifica que quien hace la solicitud sea administrador; si no, responde 401 o 403 y devuelve false
+func (h *Handler) requireAdmin(w http.ResponseWriter, r *http.Request, action string) bool {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para "+action)
+		return false
+	}
+	if !h.isAdmin(r.Context(), userID) {
+		respondError(w, http.StatusForbidden, "Solo un administrador puede "+action)
+		return false
+	}
+	return true
+}
+
+// Verifica que la orden exista y que quien la consulta sea su dueño o un administrador; si no, responde el error
+func (h *Handler) authorizeOrder(w http.ResponseWriter, r *http.Request, orderID string) bool {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar una orden")
+		return false
+	}
+	if h.isAdmin(r.Context(), userID) {
+		if _, err := (*h.OrderService).GetOrderByID(r.Context(), orderID); err != nil {
+			respondOrderError(w, err)
+			return false
+		}
+		return true
+	}
+	if _, err := (*h.OrderService).GetOwnedOrder(r.Context(), orderID, userID); err != nil {
+		if errors.Is(err, orders.ErrNotOrderOwner) {
+			respondError(w, http.StatusForbidden, err.Error())
+			return false
+		}
+		respondOrderError(w, err)
+		return false
+	}
+	return true
+}
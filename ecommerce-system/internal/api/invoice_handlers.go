@@ -0,0 +1,154 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/invoicing"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+)
+
+// --- MANEJADORES DE FACTURACIÓN ---
+
+// Traduce los errores de facturación al código HTTP correspondiente
+func respondInvoiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, invoicing.ErrInvoiceNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, invoicing.ErrNothingToCredit), errors.Is(err, invoicing.ErrNotAnInvoice),
+		errors.Is(err, invoicing.ErrAlreadyInvoiced), errors.Is(err, invoicing.ErrNotInvoiceable):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, invoicing.ErrInvalidCreditNote):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Obtiene el documento pedido si quien lo solicita es el cliente facturado o un administrador
+func (h *Handler) authorizeInvoice(w http.ResponseWriter, r *http.Request) (*invoicing.Invoice, bool) {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar un documento")
+		return nil, false
+	}
+	inv, err := (*h.InvoiceService).GetInvoice(context.Background(), mux.Vars(r)["number"])
+	if err != nil {
+		respondInvoiceError(w, err)
+		return nil, false
+	}
+	if inv.Customer.UserID != userID && !h.isAdmin(r.Context(), userID) {
+		respondError(w, http.StatusForbidden, "El documento pertenece a otro usuario")
+		return nil, false
+	}
+	return inv, true
+}
+
+// Listar facturas y notas de crédito (filtro opcional ?series=); solo administradores
+func (h *Handler) ListInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "listar documentos") {
+		return
+	}
+	list, err := (*h.InvoiceService).ListInvoices(context.Background(), r.URL.Query().Get("series"))
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con los documentos
+}
+
+// Listar los documentos de una orden (dueño de la orden o administrador)
+func (h *Handler) ListOrderInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	list, err := (*h.InvoiceService).ListByOrder(context.Background(), vars["orderId"])
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con la factura y sus notas de crédito
+}
+
+// Emitir a mano la factura de una orden pagada (por ejemplo, si falló la facturación automática); solo administradores
+func (h *Handler) IssueInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "emitir facturas") {
+		return
+	}
+	vars := mux.Vars(r)
+	order, err := (*h.OrderService).GetOrderByID(context.Background(), vars["orderId"])
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	inv, err := (*h.InvoiceService).IssueInvoice(context.Background(), *order)
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, inv) // Responde con la factura emitida
+}
+
+// Obtener un documento por su número (cliente facturado o administrador)
+func (h *Handler) GetInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.authorizeInvoice(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, inv) // Responde con el documento
+}
+
+// Descargar el documento en HTML
+func (h *Handler) GetInvoiceHTMLHandler(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.authorizeInvoice(w, r)
+	if !ok {
+		return
+	}
+	doc, err := invoicing.RenderHTML(inv)
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc)
+}
+
+// Descargar el documento en PDF
+func (h *Handler) GetInvoicePDFHandler(w http.ResponseWriter, r *http.Request) {
+	inv, ok := h.authorizeInvoice(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+inv.Number+`.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(invoicing.RenderPDF(inv))
+}
+
+// Emitir una nota de crédito que corrige una factura; solo administradores
+func (h *Handler) CreateCreditNoteHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "emitir notas de crédito") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req invoicing.CreditNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	note, err := (*h.InvoiceService).IssueCreditNote(context.Background(), vars["number"], req)
+	if err != nil {
+		respondInvoiceError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, note) // Responde con la nota de crédito emitida
+}
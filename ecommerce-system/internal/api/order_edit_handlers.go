@@ -0,0 +1,130 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+	"strconv"       // Conversión del número de línea
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+)
+
+// --- MANEJADORES DE EDICIÓN DE ÓRDENES ---
+
+// Traduce los errores de la edición de una orden al código HTTP correspondiente
+func respondOrderEditError(w http.ResponseWriter, err error) {
+	switch {
+	case isVersionConflict(err):
+		respondError(w, http.StatusPreconditionFailed, "La orden fue modificada por otra solicitud")
+	case errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, orders.ErrOrderNotEditable):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, orders.ErrInvalidEdit):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondOrderError(w, err) // Stock, cupones y direcciones
+	}
+}
+
+// Verifica que quien edita sea el dueño de la orden o un administrador; retorna el actor y si puede continuar
+func (h *Handler) authorizeOrderEdit(w http.ResponseWriter, r *http.Request, orderID string) (string, bool) {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para editar una orden")
+		return "", false
+	}
+	if h.isAdmin(r.Context(), userID) {
+		return userID, true
+	}
+	if _, err := (*h.OrderService).GetOwnedOrder(context.Background(), orderID, userID); err != nil {
+		if errors.Is(err, orders.ErrNotOrderOwner) {
+			respondError(w, http.StatusForbidden, err.Error())
+		} else {
+			respondOrderEditError(w, err)
+		}
+		return "", false
+	}
+	return userID, true
+}
+
+// Responde con la orden editada y su nueva versión
+func respondEditedOrder(w http.ResponseWriter, o *orders.Order, err error) {
+	if err != nil {
+		respondOrderEditError(w, err)
+		return
+	}
+	setETag(w, o.Version)
+	respondJSON(w, http.StatusOK, o) // Responde con la orden recalculada
+}
+
+// Agregar un producto a una orden pendiente
+func (h *Handler) AddOrderItemHandler(w http.ResponseWriter, r *http.Request) {
+	orderID := mux.Vars(r)["orderId"]
+	actor, ok := h.authorizeOrderEdit(w, r, orderID)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
+	var req orders.LineItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	o, err := (*h.OrderService).AddLineItem(context.Background(), orderID, req, actor, version)
+	respondEditedOrder(w, o, err)
+}
+
+// Cambiar la cantidad de una línea de una orden pendiente
+func (h *Handler) UpdateOrderItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["orderId"]
+	line, err := strconv.Atoi(vars["line"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Número de línea inválido")
+		return
+	}
+	actor, ok := h.authorizeOrderEdit(w, r, orderID)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
+	var req orders.UpdateLineItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	o, err := (*h.OrderService).UpdateLineItem(context.Background(), orderID, line, req.Quantity, actor, version)
+	respondEditedOrder(w, o, err)
+}
+
+// Quitar una línea de una orden pendiente
+func (h *Handler) RemoveOrderItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID := vars["orderId"]
+	line, err := strconv.Atoi(vars["line"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Número de línea inválido")
+		return
+	}
+	actor, ok := h.authorizeOrderEdit(w, r, orderID)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r) // Versión que el cliente leyó
+	if !ok {
+		return
+	}
+	o, err := (*h.OrderService).RemoveLineItem(context.Background(), orderID, line, actor, version)
+	respondEditedOrder(w, o, err)
+}
@@ -0,0 +1,88 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"fmt"     // Formateo de errores
+	"net/url" // Parámetros de consulta
+	"strconv" // Conversión de números
+	"strings" // Separación de listas
+	"time"    // Fechas del filtro
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+)
+
+// Convierte los parámetros de GET /orders en un filtro de búsqueda:
+// status (lista separada por comas), user_id, product_id, created_from, created_to (RFC 3339 o AAAA-MM-DD),
+// min_total, max_total, sort (created_at, updated_at; prefijo "-" = descendente), limit y offset
+func parseOrderFilter(q url.Values) (orders.OrderFilter, error) {
+	f := orders.OrderFilter{
+		UserID:     q.Get("user_id"),
+		ProductID:  q.Get("product_id"),
+		SortBy:     orders.SortByCreated,
+		Descending: true, // Por defecto las más recientes primero
+	}
+	if value := q.Get("status"); value != "" {
+		for _, st := range strings.Split(value, ",") {
+			f.Statuses = append(f.Statuses, orders.OrderStatus(strings.TrimSpace(st)))
+		}
+	}
+	if value := q.Get("sort"); value != "" {
+		f.Descending = strings.HasPrefix(value, "-")
+		f.SortBy = orders.SortField(strings.TrimPrefix(value, "-"))
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseFilterTime(q.Get("created_from"), false); err != nil {
+		return f, fmt.Errorf("created_from inválido: %w", err)
+	}
+	if f.CreatedTo, err = parseFilterTime(q.Get("created_to"), true); err != nil {
+		return f, fmt.Errorf("created_to inválido: %w", err)
+	}
+	if f.MinTotal, err = parseFilterFloat(q.Get("min_total")); err != nil {
+		return f, fmt.Errorf("min_total inválido: %w", err)
+	}
+	if f.MaxTotal, err = parseFilterFloat(q.Get("max_total")); err != nil {
+		return f, fmt.Errorf("max_total inválido: %w", err)
+	}
+	if value := q.Get("limit"); value != "" {
+		if f.Limit, err = strconv.Atoi(value); err != nil || f.Limit <= 0 {
+			return f, fmt.Errorf("limit inválido: %q", value)
+		}
+	}
+	if value := q.Get("offset"); value != "" {
+		if f.Offset, err = strconv.Atoi(value); err != nil {
+			return f, fmt.Errorf("offset inválido: %q", value)
+		}
+	}
+	return f, nil
+}
+
+// Interpreta una fecha RFC 3339 o AAAA-MM-DD; una fecha sin hora usada como límite final incluye todo ese día
+func parseFilterTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1) // El límite final es excluyente: hasta el inicio del día siguiente
+	}
+	return &t, nil
+}
+
+// Interpreta un monto opcional
+func parseFilterFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,47 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"bytes"         // Lectura del documento resultante
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Manejo de errores
+	"io"            // Lectura del cuerpo de la solicitud
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/patch"
+)
+
+// Aplica el parche del cuerpo de la solicitud sobre current y decodifica el resultado en dst.
+// Responde el error correspondiente y retorna false si la solicitud no debe continuar.
+func applyPatchRequest(w http.ResponseWriter, r *http.Request, current, dst interface{}) bool {
+	patchDoc, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return false
+	}
+	doc, err := json.Marshal(current) // Documento actual sobre el que se aplica el parche
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	patched, err := patch.Apply(r.Header.Get("Content-Type"), doc, patchDoc)
+	if errors.Is(err, patch.ErrUnsupportedMediaType) {
+		respondError(w, http.StatusUnsupportedMediaType, "Content-Type debe ser "+patch.MediaTypeMergePatch+" o "+patch.MediaTypeJSONPatch)
+		return false
+	}
+	if errors.Is(err, patch.ErrTestFailed) {
+		respondError(w, http.StatusConflict, err.Error())
+		return false
+	}
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "Parche inválido: "+err.Error())
+		return false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields() // Solo se permiten los campos editables
+	if err := decoder.Decode(dst); err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "Parche inválido: "+err.Error())
+		return false
+	}
+	return true
+}
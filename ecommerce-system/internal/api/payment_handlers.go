@@ -0,0 +1,195 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+)
+
+// --- MANEJADORES DE PAGOS ---
+
+// Traduce los errores de pagos al código HTTP correspondiente
+func respondPaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, payments.ErrPaymentNotFound), errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, payments.ErrDeclined):
+		respondError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, payments.ErrGatewayTimeout):
+		respondError(w, http.StatusGatewayTimeout, err.Error())
+	case errors.Is(err, payments.ErrAlreadyPaid), errors.Is(err, payments.ErrInvalidTransition), errors.Is(err, orders.ErrNotPayable),
+		errors.Is(err, payments.ErrOperationInProgress):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// Pagar una orden (autorizar y, si se pide, cobrar); dueño de la orden o administrador
+func (h *Handler) CreatePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	var req payments.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	payment, err := (*h.PaymentService).Authorize(context.Background(), vars["orderId"], req)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, payment) // Responde con el pago creado
+}
+
+// Listar los pagos de una orden (dueño de la orden o administrador)
+func (h *Handler) ListOrderPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	list, err := (*h.PaymentService).ListByOrder(context.Background(), vars["orderId"])
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con los pagos de la orden
+}
+
+// Obtener un pago por su ID (dueño de la orden o administrador)
+func (h *Handler) GetPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizePayment(w, r, false)
+	if !ok {
+		return
+	}
+	payment, err := (*h.PaymentService).GetPayment(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, payment) // Responde con el pago
+}
+
+// Verifica que el pago exista y que quien lo pide sea el dueño de su orden o un administrador;
+// con adminOnly solo se admite a un administrador
+func (h *Handler) authorizePayment(w http.ResponseWriter, r *http.Request, adminOnly bool) (string, bool) {
+	id := mux.Vars(r)["id"]
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para operar un pago")
+		return "", false
+	}
+	payment, err := (*h.PaymentService).GetPayment(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return "", false
+	}
+	if h.isAdmin(r.Context(), userID) {
+		return id, true
+	}
+	if adminOnly {
+		respondError(w, http.StatusForbidden, "Solo un administrador puede devolver un pago")
+		return "", false
+	}
+	if _, err := (*h.OrderService).GetOwnedOrder(context.Background(), payment.OrderID, userID); err != nil {
+		if errors.Is(err, orders.ErrNotOrderOwner) {
+			respondError(w, http.StatusForbidden, "El pago pertenece a una orden de otro usuario")
+			return "", false
+		}
+		respondPaymentError(w, err)
+		return "", false
+	}
+	return id, true
+}
+
+// Anula los pagos autorizados y aún sin cobrar de una orden (antes de cancelarla)
+func (h *Handler) voidAuthorizations(ctx context.Context, orderID string) error {
+	list, err := (*h.PaymentService).ListByOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	for _, p := range list {
+		if p.Status != payments.StatusAuthorized {
+			continue
+		}
+		if _, err := (*h.PaymentService).Void(ctx, p.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cobrar un pago autorizado (dueño de la orden o administrador)
+func (h *Handler) CapturePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizePayment(w, r, false)
+	if !ok {
+		return
+	}
+	payment, err := (*h.PaymentService).Capture(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, payment) // Responde con el pago cobrado
+}
+
+// Anular un pago autorizado (dueño de la orden o administrador)
+func (h *Handler) VoidPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizePayment(w, r, false)
+	if !ok {
+		return
+	}
+	payment, err := (*h.PaymentService).Void(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, payment) // Responde con el pago anulado
+}
+
+// Devolver parte o todo lo cobrado de un pago (solo administradores); pasa por el servicio de reembolsos
+// para que la orden y el registro de reembolsos reflejen la devolución
+func (h *Handler) RefundPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizePayment(w, r, true)
+	if !ok {
+		return
+	}
+	var req refunds.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	if req.Amount <= 0 || len(req.Lines) > 0 {
+		respondRefundError(w, refunds.ErrInvalidRefund) // Esta ruta devuelve solo por monto
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = refunds.ReasonOther
+	}
+	payment, err := (*h.PaymentService).GetPayment(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	req.PaymentID = id
+	if _, err := (*h.RefundService).CreateRefund(context.Background(), payment.OrderID, requesterID(r), req); err != nil {
+		respondRefundError(w, err)
+		return
+	}
+	payment, err = (*h.PaymentService).GetPayment(context.Background(), id)
+	if err != nil {
+		respondPaymentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, payment) // Responde con el pago actualizado
+}
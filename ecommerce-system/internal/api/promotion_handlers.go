@@ -0,0 +1,95 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions"
+)
+
+// --- MANEJADORES DE PROMOCIONES ---
+
+// Traduce los errores de promociones al código HTTP correspondiente
+func respondPromotionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, promotions.ErrRuleNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	respondError(w, http.StatusBadRequest, err.Error())
+}
+
+// Crear una regla de promoción (solo administradores)
+func (h *Handler) CreatePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "crear promociones") {
+		return
+	}
+	var req promotions.RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	rule, err := (*h.PromotionService).CreateRule(context.Background(), req)
+	if err != nil {
+		respondPromotionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, rule) // Responde con la regla creada
+}
+
+// Listar las reglas en el orden en que se evalúan
+func (h *Handler) ListPromotionsHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := (*h.PromotionService).ListRules(context.Background())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, rules) // Responde con la lista de reglas
+}
+
+// Obtener una regla por su ID
+func (h *Handler) GetPromotionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rule, err := (*h.PromotionService).GetRule(context.Background(), vars["id"])
+	if err != nil {
+		respondPromotionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rule) // Responde con la regla
+}
+
+// Reemplazar una regla existente (solo administradores)
+func (h *Handler) UpdatePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "modificar promociones") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req promotions.RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	rule, err := (*h.PromotionService).UpdateRule(context.Background(), vars["id"], req)
+	if err != nil {
+		respondPromotionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rule) // Responde con la regla actualizada
+}
+
+// Eliminar una regla (solo administradores)
+func (h *Handler) DeletePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "eliminar promociones") {
+		return
+	}
+	vars := mux.Vars(r)
+	if err := (*h.PromotionService).DeleteRule(context.Background(), vars["id"]); err != nil {
+		respondPromotionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil) // Responde con estado No Content
+}
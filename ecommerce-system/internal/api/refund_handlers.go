@@ -0,0 +1,63 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+)
+
+// --- MANEJADORES DE REEMBOLSOS ---
+
+// Traduce los errores de reembolsos al código HTTP correspondiente
+func respondRefundError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, refunds.ErrNotRefundable):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, refunds.ErrInvalidRefund), errors.Is(err, refunds.ErrExceedsRefundable):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondPaymentError(w, err) // Errores de la pasarela al devolver el dinero
+	}
+}
+
+// Reembolsar una orden (solo administradores)
+func (h *Handler) CreateRefundHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "reembolsar una orden") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req refunds.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	refund, err := (*h.RefundService).CreateRefund(context.Background(), vars["orderId"], requesterID(r), req)
+	if err != nil {
+		respondRefundError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, refund) // Responde con el reembolso creado
+}
+
+// Listar los reembolsos de una orden (dueño de la orden o administrador)
+func (h *Handler) ListOrderRefundsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	list, err := (*h.RefundService).ListByOrder(context.Background(), vars["orderId"])
+	if err != nil {
+		respondRefundError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con los reembolsos de la orden
+}
@@ -0,0 +1,153 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"io"            // Detección de cuerpo vacío
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/returns"
+)
+
+// --- MANEJADORES DE DEVOLUCIONES ---
+
+// Traduce los errores de devoluciones al código HTTP correspondiente
+func respondReturnError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, returns.ErrReturnNotFound), errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, returns.ErrNotOrderOwner):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, returns.ErrInvalidTransition), errors.Is(err, returns.ErrNotReturnable), errors.Is(err, returns.ErrReturnWindowClosed):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, returns.ErrInvalidReturn):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondRefundError(w, err) // Errores del reembolso final
+	}
+}
+
+// Solicitar la devolución de productos de una orden entregada
+func (h *Handler) CreateReturnHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para solicitar una devolución")
+		return
+	}
+	var req returns.ReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	rma, err := (*h.ReturnService).RequestReturn(context.Background(), vars["orderId"], userID, req)
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, rma) // Responde con la devolución creada
+}
+
+// Listar las devoluciones de una orden (dueño de la orden o administrador)
+func (h *Handler) ListOrderReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	list, err := (*h.ReturnService).ListByOrder(context.Background(), vars["orderId"])
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con las devoluciones de la orden
+}
+
+// Listar todas las devoluciones (filtro opcional ?status=, solo administradores)
+func (h *Handler) ListReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "listar todas las devoluciones") {
+		return
+	}
+	list, err := (*h.ReturnService).ListReturns(context.Background(), returns.Status(r.URL.Query().Get("status")))
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con las devoluciones
+}
+
+// Obtener una devolución por su ID (dueño de la orden o administrador)
+func (h *Handler) GetReturnHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rma, err := (*h.ReturnService).GetReturn(context.Background(), vars["id"])
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	if !h.authorizeOrder(w, r, rma.OrderID) {
+		return
+	}
+	respondJSON(w, http.StatusOK, rma) // Responde con la devolución
+}
+
+// Aprobar una devolución
+func (h *Handler) ApproveReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.returnStep(w, r, (*h.ReturnService).Approve)
+}
+
+// Rechazar una devolución
+func (h *Handler) RejectReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.returnStep(w, r, (*h.ReturnService).Reject)
+}
+
+// Registrar la recepción de los productos devueltos
+func (h *Handler) ReceiveReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.returnStep(w, r, (*h.ReturnService).Receive)
+}
+
+// Reintentar el reembolso de una devolución inspeccionada
+func (h *Handler) RefundReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.returnStep(w, r, (*h.ReturnService).Refund)
+}
+
+// Registrar la inspección (reingreso o baja por línea) y emitir el reembolso (solo administradores)
+func (h *Handler) InspectReturnHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "inspeccionar una devolución") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req returns.InspectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	rma, err := (*h.ReturnService).Inspect(context.Background(), vars["id"], requesterID(r), req)
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rma) // Responde con la devolución actualizada
+}
+
+// Ejecuta un paso de la devolución que solo recibe un comentario opcional; son pasos del personal (solo administradores)
+func (h *Handler) returnStep(w http.ResponseWriter, r *http.Request, step func(ctx context.Context, id, actor, note string) (*returns.Return, error)) {
+	if !h.requireAdmin(w, r, "gestionar una devolución") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req returns.StepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	rma, err := step(context.Background(), vars["id"], requesterID(r), req.Note)
+	if err != nil {
+		respondReturnError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, rma) // Responde con la devolución actualizada
+}
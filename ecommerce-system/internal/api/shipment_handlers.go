@@ -0,0 +1,159 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"io"            // Detección de cuerpo vacío
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/carriers"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipments"
+)
+
+// --- MANEJADORES DE ENVÍOS ---
+
+// Traduce los errores de envíos al código HTTP correspondiente
+func respondShipmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, shipments.ErrShipmentNotFound), errors.Is(err, orders.ErrOrderNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, shipments.ErrNotShippable), errors.Is(err, shipments.ErrInvalidTransition), errors.Is(err, shipments.ErrNoCarrierAdapter):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, shipments.ErrInvalidShipment), errors.Is(err, shipments.ErrMissingTracking), errors.Is(err, carriers.ErrLabelRejected):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, carriers.ErrUnknownTracking):
+		respondError(w, http.StatusBadGateway, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Verifica que el envío exista y que quien lo consulta sea el dueño de su orden o un administrador
+// (la guía lleva la dirección del cliente)
+func (h *Handler) authorizeShipment(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	shipment, err := (*h.ShipmentService).GetShipment(context.Background(), id)
+	if err != nil {
+		respondShipmentError(w, err)
+		return "", false
+	}
+	if !h.authorizeOrder(w, r, shipment.OrderID) {
+		return "", false
+	}
+	return id, true
+}
+
+// Armar un envío con líneas de una orden (sin líneas: todo lo pendiente); solo administradores
+func (h *Handler) CreateShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "armar un envío") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req shipments.ShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	shipment, err := (*h.ShipmentService).CreateShipment(context.Background(), vars["orderId"], req)
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, shipment) // Responde con el envío creado
+}
+
+// Listar los envíos de una orden (dueño de la orden o administrador)
+func (h *Handler) ListOrderShipmentsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.authorizeOrder(w, r, vars["orderId"]) {
+		return
+	}
+	list, err := (*h.ShipmentService).ListByOrder(context.Background(), vars["orderId"])
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con los envíos de la orden
+}
+
+// Obtener un envío por su ID (dueño de la orden o administrador)
+func (h *Handler) GetShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeShipment(w, r)
+	if !ok {
+		return
+	}
+	shipment, err := (*h.ShipmentService).GetShipment(context.Background(), id)
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, shipment) // Responde con el envío
+}
+
+// Despachar un envío pendiente indicando transportadora y guía; solo administradores
+func (h *Handler) ShipShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "despachar un envío") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req shipments.ShipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	shipment, err := (*h.ShipmentService).Ship(context.Background(), vars["id"], req)
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, shipment) // Responde con el envío despachado
+}
+
+// Confirmar la entrega de un envío; solo administradores
+func (h *Handler) DeliverShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "confirmar la entrega de un envío") {
+		return
+	}
+	vars := mux.Vars(r)
+	shipment, err := (*h.ShipmentService).Deliver(context.Background(), vars["id"])
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, shipment) // Responde con el envío entregado
+}
+
+// Consultar el rastreo del envío en la transportadora (una entrega confirmada actualiza la orden); solo administradores
+func (h *Handler) SyncShipmentTrackingHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "consultar el rastreo de un envío") {
+		return
+	}
+	vars := mux.Vars(r)
+	shipment, err := (*h.ShipmentService).SyncTracking(context.Background(), vars["id"])
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, shipment) // Responde con el envío y sus eventos
+}
+
+// Descargar la guía generada por la transportadora (dueño de la orden o administrador)
+func (h *Handler) GetShipmentLabelHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeShipment(w, r)
+	if !ok {
+		return
+	}
+	label, err := (*h.ShipmentService).GetLabel(context.Background(), id)
+	if err != nil {
+		respondShipmentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", label.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(label.Data) // Documento de la guía tal como lo entregó la transportadora
+}
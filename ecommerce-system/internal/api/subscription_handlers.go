@@ -0,0 +1,157 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/subscriptions"
+)
+
+// --- MANEJADORES DE SUSCRIPCIONES ---
+
+// Traduce los errores de suscripciones al código HTTP correspondiente
+func respondSubscriptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, subscriptions.ErrSubscriptionNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, subscriptions.ErrInvalidTransition):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, subscriptions.ErrInvalidSubscription):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondOrderError(w, err) // Productos, stock y direcciones validados al cotizar
+	}
+}
+
+// Verifica que la suscripción exista y sea del usuario que la pide (o que este sea administrador)
+func (h *Handler) authorizeSubscription(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para gestionar una suscripción")
+		return "", false
+	}
+	sub, err := (*h.SubscriptionService).GetSubscription(context.Background(), id)
+	if err != nil {
+		respondSubscriptionError(w, err)
+		return "", false
+	}
+	if sub.UserID != userID && !h.isAdmin(r.Context(), userID) {
+		respondError(w, http.StatusForbidden, "La suscripción pertenece a otro usuario")
+		return "", false
+	}
+	return id, true
+}
+
+// Crear una suscripción para el usuario que hace la solicitud
+func (h *Handler) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para suscribirse")
+		return
+	}
+	var req subscriptions.SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	sub, err := (*h.SubscriptionService).CreateSubscription(context.Background(), userID, req)
+	if err != nil {
+		respondSubscriptionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, sub) // Responde con la suscripción creada
+}
+
+// Listar las suscripciones del usuario (un administrador puede indicar otro con user_id)
+func (h *Handler) ListSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requesterID(r)
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "Se requiere "+headerUserID+" para consultar suscripciones")
+		return
+	}
+	if other := r.URL.Query().Get("user_id"); other != "" && other != userID {
+		if !h.isAdmin(r.Context(), userID) {
+			respondError(w, http.StatusForbidden, "Solo un administrador puede ver suscripciones de otro usuario")
+			return
+		}
+		userID = other
+	}
+	list, err := (*h.SubscriptionService).ListByUser(context.Background(), userID)
+	if err != nil {
+		respondSubscriptionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con las suscripciones
+}
+
+// Obtener una suscripción por su ID
+func (h *Handler) GetSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub, err := (*h.SubscriptionService).GetSubscription(context.Background(), id)
+	respondSubscription(w, sub, err)
+}
+
+// Pausar una suscripción
+func (h *Handler) PauseSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub, err := (*h.SubscriptionService).Pause(context.Background(), id)
+	respondSubscription(w, sub, err)
+}
+
+// Reanudar una suscripción pausada
+func (h *Handler) ResumeSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub, err := (*h.SubscriptionService).Resume(context.Background(), id)
+	respondSubscription(w, sub, err)
+}
+
+// Saltar las próximas entregas (por defecto una)
+func (h *Handler) SkipSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+	var req subscriptions.SkipRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+			return
+		}
+	}
+	sub, err := (*h.SubscriptionService).Skip(context.Background(), id, req.Count)
+	respondSubscription(w, sub, err)
+}
+
+// Cancelar una suscripción
+func (h *Handler) CancelSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+	sub, err := (*h.SubscriptionService).Cancel(context.Background(), id)
+	respondSubscription(w, sub, err)
+}
+
+// Responde con la suscripción o con el error correspondiente
+func respondSubscription(w http.ResponseWriter, sub *subscriptions.Subscription, err error) {
+	if err != nil {
+		respondSubscriptionError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, sub) // Responde con la suscripción
+}
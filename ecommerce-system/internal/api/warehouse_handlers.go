@@ -0,0 +1,129 @@
+// Paquete que define la API para manejar solicitudes HTTP
+package api
+
+import (
+	"context"       // Manejo de contexto en solicitudes
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Comparación de errores
+	"net/http"      // Manejo de solicitudes HTTP
+
+	"github.com/gorilla/mux" // Paquete para enrutamiento HTTP
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses"
+)
+
+// --- MANEJADORES DE BODEGAS ---
+
+// Crear una bodega (solo administradores)
+func (h *Handler) CreateWarehouseHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "crear bodegas") {
+		return
+	}
+	var req warehouses.WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	wh, err := (*h.WarehouseService).CreateWarehouse(context.Background(), req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusCreated, wh) // Responde con la bodega creada
+}
+
+// Listar todas las bodegas
+func (h *Handler) ListWarehousesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := (*h.WarehouseService).ListWarehouses(context.Background())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, list) // Responde con la lista de bodegas
+}
+
+// Listar el stock de una bodega
+func (h *Handler) GetWarehouseStockHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	levels, err := (*h.WarehouseService).ListStockByWarehouse(context.Background(), vars["id"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Bodega no encontrada")
+		return
+	}
+	respondJSON(w, http.StatusOK, levels) // Responde con el stock de la bodega
+}
+
+// Ajustar el stock de un producto en una bodega (solo administradores)
+func (h *Handler) AdjustWarehouseStockHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "ajustar el stock de una bodega") {
+		return
+	}
+	vars := mux.Vars(r)
+	var req warehouses.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	level, err := (*h.WarehouseService).AdjustStock(context.Background(), vars["id"], req)
+	if errors.Is(err, warehouses.ErrWarehouseNotFound) || errors.Is(err, products.ErrProductNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, products.ErrorStockInsuficiente) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusCreated, level) // Responde con el nuevo stock en la bodega
+}
+
+// Rechaza con 409 un cambio directo del stock de un producto gestionado por bodegas (descuadraría sus niveles);
+// devuelve false si ya respondió
+func (h *Handler) allowProductStockChange(w http.ResponseWriter, r *http.Request, productID string) bool {
+	if h.WarehouseService == nil || !(*h.WarehouseService).Manages(r.Context(), productID) {
+		return true
+	}
+	respondError(w, http.StatusConflict, "El stock de este producto se gestiona por bodega; ajústelo con POST /warehouses/{id}/stock")
+	return false
+}
+
+// Trasladar stock entre bodegas (solo administradores)
+func (h *Handler) TransferStockHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r, "trasladar stock entre bodegas") {
+		return
+	}
+	var req warehouses.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Solicitud inválida: "+err.Error())
+		return
+	}
+	transfer, err := (*h.WarehouseService).TransferStock(context.Background(), req)
+	if errors.Is(err, warehouses.ErrWarehouseNotFound) || errors.Is(err, products.ErrProductNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, warehouses.ErrInsufficientStock) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusCreated, transfer) // Responde con el traslado registrado
+}
+
+// Listar el stock de un producto en cada bodega
+func (h *Handler) GetProductWarehouseStockHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	levels, err := (*h.WarehouseService).ListStockByProduct(context.Background(), vars["id"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, levels) // Responde con el stock por bodega
+}
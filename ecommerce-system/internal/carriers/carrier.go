@@ -0,0 +1,60 @@
+// Paquete para integración con transportadoras (guías y rastreo)
+package carriers
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping" // Dirección de destino
+)
+
+// Status es el estado normalizado de un paquete, común a todas las transportadoras
+type Status string
+
+// Constantes que definen los estados de rastreo
+const (
+	StatusLabelCreated   Status = "label_created"    // Guía generada, aún sin retirar
+	StatusInTransit      Status = "in_transit"       // En camino
+	StatusOutForDelivery Status = "out_for_delivery" // En reparto
+	StatusDelivered      Status = "delivered"        // Entregado al destinatario
+	StatusException      Status = "exception"        // Incidencia (dirección errónea, ausente...)
+	StatusUnknown        Status = "unknown"          // Código que la transportadora no documenta
+)
+
+// Carrier es el adaptador de una transportadora
+type Carrier interface {
+	Code() string                                                              // Código con el que se identifica en los envíos (ej. "fake")
+	CreateLabel(ctx context.Context, req LabelRequest) (*Label, error)         // Genera la guía y el número de rastreo
+	Track(ctx context.Context, trackingNumber string) ([]TrackingEvent, error) // Historial completo de eventos del paquete
+	MapStatus(code string) Status                                              // Traduce un código propio al estado normalizado
+}
+
+// LabelRequest son los datos para generar una guía
+type LabelRequest struct {
+	Reference string           // Referencia interna (ID del envío)
+	To        shipping.Address // Destinatario
+	WeightKg  float64          // Peso del paquete
+}
+
+// Label es la guía generada por la transportadora
+type Label struct {
+	TrackingNumber string `json:"tracking_number"` // Número de rastreo asignado
+	ContentType    string `json:"content_type"`    // Formato del documento (ej. text/plain para ZPL)
+	Data           []byte `json:"-"`               // Documento para imprimir
+}
+
+// TrackingEvent es un evento de rastreo reportado por la transportadora
+type TrackingEvent struct {
+	Code        string    `json:"code"`               // Código propio de la transportadora
+	Status      Status    `json:"status"`             // Estado normalizado
+	Description string    `json:"description"`        // Detalle legible
+	Location    string    `json:"location,omitempty"` // Lugar del evento
+	At          time.Time `json:"at"`                 // Momento del evento
+}
+
+// Errores de las transportadoras
+var (
+	ErrUnknownTracking = errors.New("unknown tracking number")
+	ErrLabelRejected   = errors.New("carrier rejected the label request")
+)
@@ -0,0 +1,113 @@
+// Paquete para integración con transportadoras (guías y rastreo)
+package carriers
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de guías y números de rastreo
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+)
+
+// ScriptedEvent es un evento que la transportadora simulada emite tras un tiempo desde la guía
+type ScriptedEvent struct {
+	Code        string        // Código propio
+	Description string        // Detalle legible
+	Location    string        // Lugar del evento
+	After       time.Duration // Tiempo desde la creación de la guía
+}
+
+// Valor de Line2 del destinatario con el que la transportadora simulada reporta una incidencia en lugar de entregar
+const FakeLineException = "tok_exception"
+
+// Códigos de la transportadora simulada
+var fakeStatuses = map[string]Status{
+	"LC": StatusLabelCreated,
+	"PU": StatusInTransit,
+	"IT": StatusInTransit,
+	"OD": StatusOutForDelivery,
+	"DL": StatusDelivered,
+	"EX": StatusException,
+}
+
+// Guía registrada en la transportadora simulada
+type fakeShipment struct {
+	createdAt time.Time       // Momento en que se generó la guía
+	script    []ScriptedEvent // Eventos que emitirá
+}
+
+// FakeCarrier simula una transportadora en el mismo proceso: cada guía recorre un guion de eventos
+// espaciados por step (retiro, tránsito, reparto y entrega)
+type FakeCarrier struct {
+	mu        sync.Mutex               // Sincroniza el acceso a las guías
+	step      time.Duration            // Separación entre eventos del guion por defecto
+	now       func() time.Time         // Reloj (reemplazable para pruebas)
+	shipments map[string]*fakeShipment // Guías por número de rastreo
+	seq       int                      // Secuencia de números de rastreo
+}
+
+// Constructor para crear una transportadora simulada; clock nil usa la hora real
+func NewFakeCarrier(step time.Duration, clock func() time.Time) *FakeCarrier {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &FakeCarrier{step: step, now: clock, shipments: make(map[string]*fakeShipment)}
+}
+
+// Código de la transportadora simulada
+func (c *FakeCarrier) Code() string { return "fake" }
+
+// Genera una guía ZPL y programa el guion de eventos; los destinos marcados con tok_exception terminan en incidencia
+func (c *FakeCarrier) CreateLabel(ctx context.Context, req LabelRequest) (*Label, error) {
+	if req.To.Name == "" || req.To.Line1 == "" {
+		return nil, ErrLabelRejected
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	tracking := fmt.Sprintf("FK%010d", c.seq)
+	script := []ScriptedEvent{
+		{Code: "LC", Description: "Label created"},
+		{Code: "PU", Description: "Picked up", Location: "Origin hub", After: c.step},
+		{Code: "IT", Description: "In transit", Location: "Sorting center", After: 2 * c.step},
+		{Code: "OD", Description: "Out for delivery", Location: req.To.City, After: 3 * c.step},
+		{Code: "DL", Description: "Delivered", Location: req.To.City, After: 4 * c.step},
+	}
+	if req.To.Line2 == FakeLineException {
+		script[3] = ScriptedEvent{Code: "EX", Description: "Recipient not found", Location: req.To.City, After: 3 * c.step}
+		script = script[:4]
+	}
+	c.shipments[tracking] = &fakeShipment{createdAt: c.now(), script: script}
+
+	zpl := fmt.Sprintf("^XA\n^FO40,40^A0N,40,40^FDFAKE CARRIER^FS\n^FO40,100^A0N,30,30^FD%s^FS\n"+
+		"^FO40,140^A0N,30,30^FD%s, %s %s^FS\n^FO40,180^A0N,30,30^FDREF %s - %.2f kg^FS\n^FO40,240^BCN,100,Y,N,N^FD%s^FS\n^XZ\n",
+		req.To.Name, req.To.City, req.To.PostalCode, req.To.Country, req.Reference, req.WeightKg, tracking)
+	return &Label{TrackingNumber: tracking, ContentType: "text/plain; charset=utf-8", Data: []byte(zpl)}, nil
+}
+
+// Retorna los eventos del guion cuyo momento ya llegó según el reloj
+func (c *FakeCarrier) Track(ctx context.Context, trackingNumber string) ([]TrackingEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sh, ok := c.shipments[trackingNumber]
+	if !ok {
+		return nil, ErrUnknownTracking
+	}
+	now := c.now()
+	var events []TrackingEvent
+	for _, e := range sh.script {
+		at := sh.createdAt.Add(e.After)
+		if at.After(now) {
+			break
+		}
+		events = append(events, TrackingEvent{Code: e.Code, Status: c.MapStatus(e.Code), Description: e.Description, Location: e.Location, At: at})
+	}
+	return events, nil
+}
+
+// Traduce los códigos de la transportadora simulada
+func (c *FakeCarrier) MapStatus(code string) Status {
+	if status, ok := fakeStatuses[code]; ok {
+		return status
+	}
+	return StatusUnknown
+}
@@ -0,0 +1,70 @@
+// Paquete para manejo de carritos de compra
+package cart
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+)
+
+// Prefijos de las claves de carrito según su dueño
+const (
+	userKeyPrefix      = "user:" // Carrito de un usuario registrado
+	anonymousKeyPrefix = "anon:" // Carrito anónimo identificado por un token
+)
+
+// Constructor de la clave del carrito de un usuario
+func UserCartKey(userID string) string {
+	return userKeyPrefix + userID
+}
+
+// Constructor de la clave de un carrito anónimo
+func AnonymousCartKey(token string) string {
+	return anonymousKeyPrefix + token
+}
+
+// Item es un producto guardado en el carrito (sin precio: el precio se consulta en vivo)
+type Item struct {
+	ProductID string    `json:"product_id"` // Producto agregado
+	Quantity  int       `json:"quantity"`   // Cantidad deseada
+	AddedAt   time.Time `json:"added_at"`   // Fecha en que se agregó
+}
+
+// Cart es el carrito almacenado
+type Cart struct {
+	Key       string    `json:"id"`         // Clave del carrito (usuario o token anónimo)
+	Items     []Item    `json:"items"`      // Productos en el carrito
+	UpdatedAt time.Time `json:"updated_at"` // Fecha de la última modificación
+}
+
+// ItemView es un ítem del carrito con precio y stock actuales
+type ItemView struct {
+	ProductID      string  `json:"product_id"`        // Producto
+	Name           string  `json:"name"`              // Nombre actual del producto
+	SKU            string  `json:"sku"`               // SKU actual del producto
+	Quantity       int     `json:"quantity"`          // Cantidad deseada
+	UnitPrice      float64 `json:"unit_price"`        // Precio unitario actual
+	Subtotal       float64 `json:"subtotal"`          // Precio unitario × cantidad
+	AvailableStock int     `json:"available_stock"`   // Stock disponible en este momento
+	Warning        string  `json:"warning,omitempty"` // Aviso si el ítem no puede comprarse tal cual
+	Purchasable    bool    `json:"purchasable"`       // Indica si el ítem puede pasar a la orden
+}
+
+// View es el carrito valorizado con precios y stock actuales
+type View struct {
+	Key       string     `json:"id"`         // Clave del carrito
+	Items     []ItemView `json:"items"`      // Ítems valorizados
+	ItemCount int        `json:"item_count"` // Unidades totales
+	Total     float64    `json:"total"`      // Suma de subtotales de ítems comprables
+	UpdatedAt time.Time  `json:"updated_at"` // Fecha de la última modificación
+}
+
+// Errores del paquete
+var (
+	ErrInvalidQuantity    = errors.New("invalid quantity")
+	ErrItemNotFound       = errors.New("item not in cart")
+	ErrEmptyCart          = errors.New("cart is empty")
+	ErrCartNotPurchasable = errors.New("cart has items that cannot be purchased")
+	ErrUserRequired       = errors.New("checkout requires a registered user")
+	ErrNothingToReorder   = errors.New("none of the items of the order can be bought again")
+	ErrInvalidTarget      = errors.New("reorder target must be order or cart")
+)
@@ -0,0 +1,44 @@
+// Paquete para manejo de carritos de compra
+package cart
+
+import (
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Ubicación de entrega
+)
+
+// Estructura que representa la solicitud para agregar un producto al carrito
+type AddItemRequest struct {
+	ProductID string `json:"product_id"` // Producto a agregar
+	Quantity  int    `json:"quantity"`   // Cantidad a agregar
+}
+
+// Estructura que representa la solicitud para cambiar la cantidad de un ítem
+type UpdateItemRequest struct {
+	Quantity int `json:"quantity"` // Nueva cantidad (0 elimina el ítem)
+}
+
+// Estructura que representa la solicitud para convertir el carrito en una orden
+type CheckoutRequest struct {
+	Destination *warehouses.Location `json:"destination,omitempty"` // Ubicación de entrega (opcional)
+	CouponCode  string               `json:"coupon_code,omitempty"` // Código de descuento (opcional)
+	// Direcciones de entrega (obligatoria) y facturación (opcional)
+	ShippingAddress *shipping.Address `json:"shipping_address,omitempty"`
+	BillingAddress  *shipping.Address `json:"billing_address,omitempty"`
+}
+
+// Destinos posibles de una recompra
+const (
+	TargetOrder = "order" // Crear una orden nueva (por defecto)
+	TargetCart  = "cart"  // Agregar los productos al carrito del usuario
+)
+
+// Estructura que representa la solicitud para volver a comprar lo de una orden anterior.
+// Sin direcciones ni destino se usan los de la orden original; el cupón original no se reutiliza.
+type ReorderRequest struct {
+	Target      string               `json:"target,omitempty"`      // order (por defecto) o cart
+	Destination *warehouses.Location `json:"destination,omitempty"` // Ubicación de entrega (opcional)
+	CouponCode  string               `json:"coupon_code,omitempty"` // Código de descuento (opcional)
+	// Direcciones de entrega y facturación (opcionales)
+	ShippingAddress *shipping.Address `json:"shipping_address,omitempty"`
+	BillingAddress  *shipping.Address `json:"billing_address,omitempty"`
+}
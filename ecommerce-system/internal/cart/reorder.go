@@ -0,0 +1,167 @@
+// Paquete para manejo de carritos de compra
+package cart
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de mensajes
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders" // Orden original y nueva
+)
+
+// Códigos de los cambios respecto de la orden original
+const (
+	ChangeDiscontinued     = "discontinued"      // El producto ya no existe en el catálogo: se omite
+	ChangeOutOfStock       = "out_of_stock"      // Sin stock: se omite
+	ChangeQuantityAdjusted = "quantity_adjusted" // Stock parcial: se pide lo disponible
+	ChangePriceChanged     = "price_changed"     // Se compra al precio actual
+)
+
+// ReorderChange explica una diferencia entre la orden original y lo que se volvió a comprar
+type ReorderChange struct {
+	ProductID         string  `json:"product_id"`               // Producto afectado
+	Name              string  `json:"name"`                     // Nombre del producto en la orden original
+	Code              string  `json:"code"`                     // Tipo de cambio
+	RequestedQuantity int     `json:"requested_quantity"`       // Unidades de la orden original
+	Quantity          int     `json:"quantity"`                 // Unidades que se volvieron a comprar
+	PreviousPrice     float64 `json:"previous_price,omitempty"` // Precio pagado en la orden original
+	CurrentPrice      float64 `json:"current_price,omitempty"`  // Precio actual
+	Message           string  `json:"message"`                  // Detalle legible
+}
+
+// ReorderResult es la orden o el carrito generado y los cambios respecto de la orden original
+type ReorderResult struct {
+	SourceOrderID string          `json:"source_order_id"` // Orden que se repite
+	Target        string          `json:"target"`          // order o cart
+	Order         *orders.Order   `json:"order,omitempty"` // Orden creada (target order)
+	Cart          *View           `json:"cart,omitempty"`  // Carrito resultante (target cart)
+	Changes       []ReorderChange `json:"changes"`         // Productos omitidos, ajustados o con otro precio
+}
+
+// Línea a recomprar: cantidad original y precio pagado
+type reorderLine struct {
+	productID string
+	name      string
+	quantity  int
+	price     float64
+}
+
+// Volver a comprar los productos de una orden anterior del usuario a precios actuales, creando una orden
+// nueva o agregándolos a su carrito; omite lo descontinuado o sin stock y ajusta lo que no alcanza
+func (s *cartService) Reorder(ctx context.Context, userID, orderID string, req ReorderRequest) (*ReorderResult, error) {
+	if userID == "" {
+		return nil, ErrUserRequired
+	}
+	target := req.Target
+	if target == "" {
+		target = TargetOrder
+	}
+	if target != TargetOrder && target != TargetCart {
+		return nil, ErrInvalidTarget
+	}
+	source, err := s.orderService.GetOwnedOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Líneas compradas (sin regalos de promociones), agrupadas por producto en el orden original
+	var lines []reorderLine
+	for _, item := range source.LineItems {
+		if item.Gift {
+			continue
+		}
+		merged := false
+		for i := range lines {
+			if lines[i].productID == item.ProductID {
+				lines[i].quantity += item.Quantity
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			lines = append(lines, reorderLine{productID: item.ProductID, name: item.Product.Name, quantity: item.Quantity, price: item.Price})
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.repo.Get(ctx, UserCartKey(userID))
+	result := &ReorderResult{SourceOrderID: source.ID, Target: target, Changes: []ReorderChange{}}
+	var items []orders.LineItemRequest
+	for _, line := range lines {
+		change := ReorderChange{ProductID: line.productID, Name: line.name, RequestedQuantity: line.quantity}
+		prod, err := s.productService.GetProductByID(ctx, line.productID)
+		if err != nil {
+			change.Code, change.Message = ChangeDiscontinued, "product is no longer sold"
+			result.Changes = append(result.Changes, change)
+			continue
+		}
+		available := prod.Stock
+		if target == TargetCart {
+			if idx := indexOf(c.Items, line.productID); idx >= 0 {
+				available -= c.Items[idx].Quantity // Lo que ya está en el carrito también ocupa stock
+			}
+		}
+		quantity := line.quantity
+		switch {
+		case available <= 0:
+			change.Code, change.Message = ChangeOutOfStock, "product is out of stock"
+			result.Changes = append(result.Changes, change)
+			continue
+		case available < quantity:
+			quantity = available
+			change.Code, change.Quantity = ChangeQuantityAdjusted, quantity
+			change.Message = fmt.Sprintf("only %d of %d units in stock", quantity, line.quantity)
+			result.Changes = append(result.Changes, change)
+		}
+		if prod.Price != line.price {
+			change.Code, change.Quantity = ChangePriceChanged, quantity
+			change.PreviousPrice, change.CurrentPrice = line.price, prod.Price
+			change.Message = fmt.Sprintf("price changed from %.2f to %.2f", line.price, prod.Price)
+			result.Changes = append(result.Changes, change)
+		}
+		items = append(items, orders.LineItemRequest{ProductID: line.productID, Quantity: quantity})
+	}
+	if len(items) == 0 {
+		return nil, ErrNothingToReorder
+	}
+
+	if target == TargetCart {
+		for _, item := range items {
+			if idx := indexOf(c.Items, item.ProductID); idx >= 0 {
+				c.Items[idx].Quantity += item.Quantity
+			} else {
+				c.Items = append(c.Items, Item{ProductID: item.ProductID, Quantity: item.Quantity, AddedAt: time.Now()})
+			}
+		}
+		c.UpdatedAt = time.Now()
+		s.repo.Save(ctx, c)
+		result.Cart = s.view(ctx, c)
+		return result, nil
+	}
+
+	orderReq := orders.OrderRequest{
+		UserID:          userID,
+		LineItems:       items,
+		Destination:     source.Destination,
+		CouponCode:      req.CouponCode,
+		ShippingAddress: source.ShippingAddress,
+		BillingAddress:  source.BillingAddress,
+	}
+	if req.Destination != nil {
+		orderReq.Destination = req.Destination
+	}
+	// Cada dirección se reemplaza por separado: cambiar la de entrega conserva la facturación original
+	if req.ShippingAddress != nil {
+		orderReq.ShippingAddress = req.ShippingAddress
+	}
+	if req.BillingAddress != nil {
+		orderReq.BillingAddress = req.BillingAddress
+	}
+	order, err := s.orderService.CreateOrder(ctx, orderReq)
+	if err != nil {
+		return nil, err
+	}
+	result.Order = order
+	return result, nil
+}
@@ -0,0 +1,260 @@
+// Paquete para manejo de carritos de compra
+package cart
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de mensajes
+	"strings" // Manipulación de claves
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"   // Servicio de órdenes
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Servicio de productos
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de carritos
+type Service interface {
+	GetCart(ctx context.Context, key string) (*View, error)                                          // Obtener carrito valorizado
+	AddItem(ctx context.Context, key string, req AddItemRequest) (*View, error)                      // Agregar producto
+	UpdateItem(ctx context.Context, key, productID string, req UpdateItemRequest) (*View, error)     // Cambiar cantidad
+	RemoveItem(ctx context.Context, key, productID string) (*View, error)                            // Quitar producto
+	MergeAnonymous(ctx context.Context, anonymousKey, userID string) (*View, error)                  // Fusionar carrito anónimo al iniciar sesión
+	Checkout(ctx context.Context, userID string, req CheckoutRequest) (*orders.Order, error)         // Convertir carrito en orden
+	Reorder(ctx context.Context, userID, orderID string, req ReorderRequest) (*ReorderResult, error) // Volver a comprar una orden anterior
+}
+
+// Implementación en memoria del repositorio de carritos
+type inMemoryRepository struct {
+	mu   sync.RWMutex    // Mutex para sincronizar acceso concurrente
+	data map[string]Cart // Carritos indexados por clave
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{data: make(map[string]Cart)} // Inicializa mapa vacío
+}
+
+// Obtiene un carrito por clave; si no existe retorna uno vacío
+func (r *inMemoryRepository) Get(ctx context.Context, key string) Cart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.data[key]
+	if !ok {
+		return Cart{Key: key, Items: []Item{}}
+	}
+	c.Items = append([]Item(nil), c.Items...) // Copia para no compartir el slice interno
+	return c
+}
+
+// Guarda un carrito
+func (r *inMemoryRepository) Save(ctx context.Context, c Cart) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[c.Key] = c
+}
+
+// Elimina un carrito
+func (r *inMemoryRepository) Delete(ctx context.Context, key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.data, key)
+}
+
+// Implementación del servicio de carritos
+type cartService struct {
+	mu             sync.Mutex          // Serializa las modificaciones de carritos
+	repo           *inMemoryRepository // Repositorio interno
+	productService products.Service    // Validación de precio y stock en vivo
+	orderService   orders.Service      // Creación de la orden en el checkout
+}
+
+// Constructor para crear un nuevo servicio de carritos
+func NewService(repo *inMemoryRepository, prodService products.Service, ordService orders.Service) Service {
+	return &cartService{repo: repo, productService: prodService, orderService: ordService}
+}
+
+// Obtener el carrito con precios y stock actuales
+func (s *cartService) GetCart(ctx context.Context, key string) (*View, error) {
+	return s.view(ctx, s.repo.Get(ctx, key)), nil
+}
+
+// Agregar un producto al carrito validando que exista y tenga stock
+func (s *cartService) AddItem(ctx context.Context, key string, req AddItemRequest) (*View, error) {
+	if req.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.repo.Get(ctx, key)
+	idx := indexOf(c.Items, req.ProductID)
+	quantity := req.Quantity
+	if idx >= 0 {
+		quantity += c.Items[idx].Quantity // El producto ya estaba: se suma la cantidad
+	}
+	if err := s.checkAvailable(ctx, req.ProductID, quantity); err != nil {
+		return nil, err
+	}
+	if idx >= 0 {
+		c.Items[idx].Quantity = quantity
+	} else {
+		c.Items = append(c.Items, Item{ProductID: req.ProductID, Quantity: quantity, AddedAt: time.Now()})
+	}
+	c.UpdatedAt = time.Now()
+	s.repo.Save(ctx, c)
+	return s.view(ctx, c), nil
+}
+
+// Cambiar la cantidad de un producto del carrito (0 lo elimina)
+func (s *cartService) UpdateItem(ctx context.Context, key, productID string, req UpdateItemRequest) (*View, error) {
+	if req.Quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if req.Quantity == 0 {
+		return s.RemoveItem(ctx, key, productID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.repo.Get(ctx, key)
+	idx := indexOf(c.Items, productID)
+	if idx < 0 {
+		return nil, ErrItemNotFound
+	}
+	if err := s.checkAvailable(ctx, productID, req.Quantity); err != nil {
+		return nil, err
+	}
+	c.Items[idx].Quantity = req.Quantity
+	c.UpdatedAt = time.Now()
+	s.repo.Save(ctx, c)
+	return s.view(ctx, c), nil
+}
+
+// Quitar un producto del carrito
+func (s *cartService) RemoveItem(ctx context.Context, key, productID string) (*View, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.repo.Get(ctx, key)
+	idx := indexOf(c.Items, productID)
+	if idx < 0 {
+		return nil, ErrItemNotFound
+	}
+	c.Items = append(c.Items[:idx], c.Items[idx+1:]...)
+	c.UpdatedAt = time.Now()
+	s.repo.Save(ctx, c)
+	return s.view(ctx, c), nil
+}
+
+// Fusionar un carrito anónimo en el carrito del usuario: se suman cantidades sin superar el stock disponible
+func (s *cartService) MergeAnonymous(ctx context.Context, anonymousKey, userID string) (*View, error) {
+	if !strings.HasPrefix(anonymousKey, anonymousKeyPrefix) {
+		return nil, fmt.Errorf("invalid anonymous cart %q", anonymousKey)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	anon := s.repo.Get(ctx, anonymousKey)
+	userCart := s.repo.Get(ctx, UserCartKey(userID))
+	for _, item := range anon.Items {
+		idx := indexOf(userCart.Items, item.ProductID)
+		quantity := item.Quantity
+		if idx >= 0 {
+			quantity += userCart.Items[idx].Quantity
+		}
+		if prod, err := s.productService.GetProductByID(ctx, item.ProductID); err == nil && quantity > prod.Stock {
+			quantity = prod.Stock // No se agregan más unidades de las disponibles
+		}
+		if quantity <= 0 {
+			continue
+		}
+		if idx >= 0 {
+			userCart.Items[idx].Quantity = quantity
+		} else {
+			userCart.Items = append(userCart.Items, Item{ProductID: item.ProductID, Quantity: quantity, AddedAt: item.AddedAt})
+		}
+	}
+	userCart.UpdatedAt = time.Now()
+	s.repo.Save(ctx, userCart)
+	s.repo.Delete(ctx, anonymousKey) // El carrito anónimo deja de existir
+	return s.view(ctx, userCart), nil
+}
+
+// Convertir el carrito del usuario en una orden y vaciarlo
+func (s *cartService) Checkout(ctx context.Context, userID string, req CheckoutRequest) (*orders.Order, error) {
+	if userID == "" {
+		return nil, ErrUserRequired
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.repo.Get(ctx, UserCartKey(userID))
+	if len(c.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	for _, item := range s.view(ctx, c).Items {
+		if !item.Purchasable {
+			return nil, fmt.Errorf("%w: %s %s", ErrCartNotPurchasable, item.ProductID, item.Warning)
+		}
+	}
+	orderReq := orders.OrderRequest{
+		UserID:          userID,
+		Destination:     req.Destination,
+		CouponCode:      req.CouponCode,
+		ShippingAddress: req.ShippingAddress,
+		BillingAddress:  req.BillingAddress,
+	}
+	for _, item := range c.Items {
+		orderReq.LineItems = append(orderReq.LineItems, orders.LineItemRequest{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	order, err := s.orderService.CreateOrder(ctx, orderReq)
+	if err != nil {
+		return nil, err // El carrito se conserva para que el cliente lo corrija
+	}
+	s.repo.Delete(ctx, c.Key)
+	return order, nil
+}
+
+// Valida que el producto exista y tenga stock para la cantidad indicada
+func (s *cartService) checkAvailable(ctx context.Context, productID string, quantity int) error {
+	prod, err := s.productService.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if prod.Stock < quantity {
+		return products.ErrorStockInsuficiente
+	}
+	return nil
+}
+
+// Valoriza el carrito con el precio y stock actuales de cada producto
+func (s *cartService) view(ctx context.Context, c Cart) *View {
+	v := &View{Key: c.Key, Items: []ItemView{}, UpdatedAt: c.UpdatedAt}
+	for _, item := range c.Items {
+		iv := ItemView{ProductID: item.ProductID, Quantity: item.Quantity}
+		prod, err := s.productService.GetProductByID(ctx, item.ProductID)
+		switch {
+		case err != nil:
+			iv.Warning = "product no longer available"
+		case prod.Stock < item.Quantity:
+			iv.Name, iv.SKU, iv.UnitPrice, iv.AvailableStock = prod.Name, prod.SKU, prod.Price, prod.Stock
+			iv.Warning = fmt.Sprintf("only %d units in stock", prod.Stock)
+		default:
+			iv.Name, iv.SKU, iv.UnitPrice, iv.AvailableStock = prod.Name, prod.SKU, prod.Price, prod.Stock
+			iv.Purchasable = true
+		}
+		iv.Subtotal = iv.UnitPrice * float64(iv.Quantity)
+		if iv.Purchasable {
+			v.Total += iv.Subtotal
+		}
+		v.ItemCount += iv.Quantity
+		v.Items = append(v.Items, iv)
+	}
+	return v
+}
+
+// Posición de un producto en la lista de ítems (-1 si no está)
+func indexOf(items []Item, productID string) int {
+	for i, item := range items {
+		if item.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,174 @@
+// Paquete para manejo de cupones y códigos de descuento
+package coupons
+
+import (
+	"errors"  // Manejo de errores
+	"math"    // Redondeo de montos
+	"strings" // Normalización de códigos
+	"time"    // Manejo de tiempos y fechas
+)
+
+// DiscountType representa la forma en que un cupón descuenta
+type DiscountType string
+
+// Constantes que definen los tipos de descuento
+const (
+	TypePercentage DiscountType = "percentage" // Porcentaje sobre el subtotal aplicable
+	TypeFixed      DiscountType = "fixed"      // Monto fijo (sin superar el subtotal aplicable)
+)
+
+// Coupon representa un código de descuento
+type Coupon struct {
+	Code           string       `json:"code"`                  // Código que ingresa el cliente (sin distinguir mayúsculas)
+	Description    string       `json:"description"`           // Descripción interna
+	Type           DiscountType `json:"type"`                  // percentage o fixed
+	Value          float64      `json:"value"`                 // Porcentaje (0-100) o monto fijo
+	MinOrderValue  float64      `json:"min_order_value"`       // Subtotal mínimo de la orden para aplicar
+	ValidFrom      *time.Time   `json:"valid_from,omitempty"`  // Inicio de vigencia (opcional)
+	ValidUntil     *time.Time   `json:"valid_until,omitempty"` // Fin de vigencia (opcional)
+	MaxUses        int          `json:"max_uses"`              // Usos totales permitidos (0 = ilimitado)
+	MaxUsesPerUser int          `json:"max_uses_per_user"`     // Usos permitidos por usuario (0 = ilimitado)
+	Categories     []string     `json:"categories,omitempty"`  // Solo aplica a estas categorías (vacío = todas)
+	ProductIDs     []string     `json:"product_ids,omitempty"` // Solo aplica a estos productos (vacío = todos)
+	Uses           int          `json:"uses"`                  // Usos registrados
+	CreatedAt      time.Time    `json:"created_at"`            // Fecha de creación
+}
+
+// Validation es la vista de un cupón para los clientes: sus condiciones, sin usos ni datos internos
+type Validation struct {
+	Code          string       `json:"code"`                  // Código del cupón
+	Type          DiscountType `json:"type"`                  // percentage o fixed
+	Value         float64      `json:"value"`                 // Porcentaje o monto fijo
+	MinOrderValue float64      `json:"min_order_value"`       // Subtotal mínimo de la orden
+	ValidFrom     *time.Time   `json:"valid_from,omitempty"`  // Inicio de vigencia
+	ValidUntil    *time.Time   `json:"valid_until,omitempty"` // Fin de vigencia
+	Categories    []string     `json:"categories,omitempty"`  // Categorías aplicables
+	ProductIDs    []string     `json:"product_ids,omitempty"` // Productos aplicables
+	Valid         bool         `json:"valid"`                 // Si el cupón puede usarse ahora
+}
+
+// Método que arma la vista de validación del cupón en un instante dado
+func (c *Coupon) Validation(now time.Time) Validation {
+	return Validation{
+		Code:          c.Code,
+		Type:          c.Type,
+		Value:         c.Value,
+		MinOrderValue: c.MinOrderValue,
+		ValidFrom:     c.ValidFrom,
+		ValidUntil:    c.ValidUntil,
+		Categories:    c.Categories,
+		ProductIDs:    c.ProductIDs,
+		Valid:         c.CheckValidity(now) == nil && (c.MaxUses == 0 || c.Uses < c.MaxUses),
+	}
+}
+
+// Line es una línea de orden evaluada por el cupón
+type Line struct {
+	ProductID string  // Producto
+	Category  string  // Categoría del producto
+	Quantity  int     // Cantidad
+	UnitPrice float64 // Precio unitario
+}
+
+// Application es el resultado de aplicar un cupón a una orden
+type Application struct {
+	Code           string    `json:"code"`            // Código aplicado
+	Amount         float64   `json:"amount"`          // Monto descontado
+	EligibleAmount float64   `json:"eligible_amount"` // Subtotal de las líneas a las que aplica
+	LineDiscounts  []float64 `json:"line_discounts"`  // Descuento asignado a cada línea (mismo orden que las líneas)
+}
+
+// Errores del paquete
+var (
+	ErrInvalidCoupon       = errors.New("invalid coupon data")
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponNotYetValid   = errors.New("coupon is not valid yet")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponUsageLimit    = errors.New("coupon usage limit reached")
+	ErrCouponUserLimit     = errors.New("coupon usage limit reached for this user")
+	ErrMinimumNotReached   = errors.New("order does not reach the coupon minimum")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to any item in the order")
+)
+
+// Normaliza un código para compararlo sin distinguir mayúsculas ni espacios
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Método que indica si el cupón aplica a una línea según sus restricciones
+func (c *Coupon) AppliesTo(line Line) bool {
+	if len(c.Categories) == 0 && len(c.ProductIDs) == 0 {
+		return true
+	}
+	for _, cat := range c.Categories {
+		if strings.EqualFold(cat, line.Category) {
+			return true
+		}
+	}
+	for _, id := range c.ProductIDs {
+		if id == line.ProductID {
+			return true
+		}
+	}
+	return false
+}
+
+// Método que verifica la vigencia del cupón en un instante dado
+func (c *Coupon) CheckValidity(now time.Time) error {
+	if c.ValidFrom != nil && now.Before(*c.ValidFrom) {
+		return ErrCouponNotYetValid
+	}
+	if c.ValidUntil != nil && now.After(*c.ValidUntil) {
+		return ErrCouponExpired
+	}
+	return nil
+}
+
+// Método que calcula el descuento sobre las líneas, repartido proporcionalmente entre las líneas aplicables
+func (c *Coupon) Calculate(lines []Line) (*Application, error) {
+	var orderSubtotal, eligible float64
+	for _, l := range lines {
+		amount := l.UnitPrice * float64(l.Quantity)
+		orderSubtotal += amount
+		if c.AppliesTo(l) {
+			eligible += amount
+		}
+	}
+	if orderSubtotal < c.MinOrderValue {
+		return nil, ErrMinimumNotReached
+	}
+	if eligible == 0 {
+		return nil, ErrCouponNotApplicable
+	}
+	discount := c.Value
+	if c.Type == TypePercentage {
+		discount = eligible * c.Value / 100
+	}
+	discount = roundCents(math.Min(discount, eligible))
+
+	app := &Application{Code: c.Code, Amount: discount, EligibleAmount: roundCents(eligible), LineDiscounts: make([]float64, len(lines))}
+	remaining := discount
+	lastEligible := -1
+	for i, l := range lines {
+		if c.AppliesTo(l) {
+			lastEligible = i
+		}
+	}
+	for i, l := range lines {
+		if !c.AppliesTo(l) {
+			continue
+		}
+		share := roundCents(discount * l.UnitPrice * float64(l.Quantity) / eligible)
+		if i == lastEligible {
+			share = roundCents(remaining) // La última línea absorbe la diferencia de redondeo
+		}
+		app.LineDiscounts[i] = share
+		remaining -= share
+	}
+	return app, nil
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,161 @@
+// Pruebas del cálculo, la vigencia y los límites de uso de los cupones
+package coupons_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons"
+)
+
+// Líneas de prueba: 2 cafés de 10 (bebidas) y 1 taza de 5 (hogar)
+var lines = []coupons.Line{
+	{ProductID: "cafe", Category: "Bebidas", Quantity: 2, UnitPrice: 10},
+	{ProductID: "taza", Category: "Hogar", Quantity: 1, UnitPrice: 5},
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name   string
+		coupon coupons.Coupon
+		want   float64   // Descuento total
+		lines  []float64 // Descuento por línea
+		err    error
+	}{
+		{name: "porcentaje sobre todo", coupon: coupons.Coupon{Type: coupons.TypePercentage, Value: 10}, want: 2.5, lines: []float64{2, 0.5}},
+		{name: "fijo repartido", coupon: coupons.Coupon{Type: coupons.TypeFixed, Value: 5}, want: 5, lines: []float64{4, 1}},
+		{name: "fijo no supera lo aplicable", coupon: coupons.Coupon{Type: coupons.TypeFixed, Value: 50, Categories: []string{"hogar"}}, want: 5, lines: []float64{0, 5}},
+		{name: "restringido a un producto", coupon: coupons.Coupon{Type: coupons.TypePercentage, Value: 50, ProductIDs: []string{"cafe"}}, want: 10, lines: []float64{10, 0}},
+		{name: "mínimo no alcanzado", coupon: coupons.Coupon{Type: coupons.TypeFixed, Value: 5, MinOrderValue: 30}, err: coupons.ErrMinimumNotReached},
+		{name: "sin líneas aplicables", coupon: coupons.Coupon{Type: coupons.TypeFixed, Value: 5, Categories: []string{"Ropa"}}, err: coupons.ErrCouponNotApplicable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := tt.coupon.Calculate(lines)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, se esperaba %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			if app.Amount != tt.want {
+				t.Fatalf("descuento = %.2f, se esperaba %.2f", app.Amount, tt.want)
+			}
+			for i, want := range tt.lines {
+				if app.LineDiscounts[i] != want {
+					t.Fatalf("descuentos por línea = %v, se esperaba %v", app.LineDiscounts, tt.lines)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckValidity(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name   string
+		coupon coupons.Coupon
+		want   error
+	}{
+		{name: "sin vigencia", coupon: coupons.Coupon{}},
+		{name: "vigente", coupon: coupons.Coupon{ValidFrom: &past, ValidUntil: &future}},
+		{name: "aún no vigente", coupon: coupons.Coupon{ValidFrom: &future}, want: coupons.ErrCouponNotYetValid},
+		{name: "vencido", coupon: coupons.Coupon{ValidUntil: &past}, want: coupons.ErrCouponExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.coupon.CheckValidity(now); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateCouponValidation(t *testing.T) {
+	svc := coupons.NewService(coupons.NewInMemoryRepository())
+	ctx := context.Background()
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	invalid := []coupons.CouponRequest{
+		{Code: "", Type: coupons.TypeFixed, Value: 5},
+		{Code: "CERO", Type: coupons.TypeFixed, Value: 0},
+		{Code: "TIPO", Type: "gratis", Value: 5},
+		{Code: "MUCHO", Type: coupons.TypePercentage, Value: 120},
+		{Code: "FECHAS", Type: coupons.TypeFixed, Value: 5, ValidFrom: &future, ValidUntil: &past},
+	}
+	for _, req := range invalid {
+		if _, err := svc.CreateCoupon(ctx, req); !errors.Is(err, coupons.ErrInvalidCoupon) {
+			t.Errorf("%q: err = %v, se esperaba %v", req.Code, err, coupons.ErrInvalidCoupon)
+		}
+	}
+	if _, err := svc.CreateCoupon(ctx, coupons.CouponRequest{Code: " verano ", Type: coupons.TypeFixed, Value: 5}); err != nil {
+		t.Fatalf("CreateCoupon: %v", err)
+	}
+	if _, err := svc.CreateCoupon(ctx, coupons.CouponRequest{Code: "VERANO", Type: coupons.TypeFixed, Value: 5}); !errors.Is(err, coupons.ErrInvalidCoupon) {
+		t.Fatalf("código repetido: err = %v, se esperaba %v", err, coupons.ErrInvalidCoupon)
+	}
+	if c, err := svc.GetCoupon(ctx, "Verano"); err != nil || c.Code != "VERANO" {
+		t.Fatalf("GetCoupon sin distinguir mayúsculas: %v, %v", c, err)
+	}
+}
+
+func TestRedeemUsageLimits(t *testing.T) {
+	svc := coupons.NewService(coupons.NewInMemoryRepository())
+	ctx := context.Background()
+	if _, err := svc.CreateCoupon(ctx, coupons.CouponRequest{Code: "PROMO", Type: coupons.TypeFixed, Value: 5, MaxUses: 2, MaxUsesPerUser: 1}); err != nil {
+		t.Fatalf("CreateCoupon: %v", err)
+	}
+
+	if _, err := svc.Redeem(ctx, "promo", "ana", "ord-1", lines); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if _, err := svc.Redeem(ctx, "PROMO", "ana", "ord-2", lines); !errors.Is(err, coupons.ErrCouponUserLimit) {
+		t.Fatalf("segundo uso del mismo usuario: err = %v, se esperaba %v", err, coupons.ErrCouponUserLimit)
+	}
+	// Evaluar no consume usos
+	if _, err := svc.Evaluate(ctx, "PROMO", "luis", lines); err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if _, err := svc.Redeem(ctx, "PROMO", "luis", "ord-3", lines); err != nil {
+		t.Fatalf("Redeem de otro usuario: %v", err)
+	}
+	if _, err := svc.Redeem(ctx, "PROMO", "eva", "ord-4", lines); !errors.Is(err, coupons.ErrCouponUsageLimit) {
+		t.Fatalf("tercer uso: err = %v, se esperaba %v", err, coupons.ErrCouponUsageLimit)
+	}
+
+	// Anular un uso lo devuelve al cupón y al usuario; anular otra orden no cambia nada
+	if err := svc.ReleaseRedemption(ctx, "PROMO", "ana", "ord-1"); err != nil {
+		t.Fatalf("ReleaseRedemption: %v", err)
+	}
+	if err := svc.ReleaseRedemption(ctx, "PROMO", "ana", "ord-1"); err != nil {
+		t.Fatalf("ReleaseRedemption repetido: %v", err)
+	}
+	if c, _ := svc.GetCoupon(ctx, "PROMO"); c.Uses != 1 {
+		t.Fatalf("usos = %d, se esperaba 1", c.Uses)
+	}
+	if _, err := svc.Redeem(ctx, "PROMO", "ana", "ord-5", lines); err != nil {
+		t.Fatalf("Redeem tras anular: %v", err)
+	}
+}
+
+func TestValidationHidesUsage(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	c := coupons.Coupon{Code: "PROMO", Type: coupons.TypeFixed, Value: 5, MaxUses: 1, Uses: 1}
+	if c.Validation(now).Valid {
+		t.Fatal("un cupón sin usos disponibles se muestra como válido")
+	}
+	c = coupons.Coupon{Code: "PROMO", Type: coupons.TypeFixed, Value: 5, ValidUntil: &past}
+	if c.Validation(now).Valid {
+		t.Fatal("un cupón vencido se muestra como válido")
+	}
+	c.ValidUntil = nil
+	if v := c.Validation(now); !v.Valid || v.Code != "PROMO" {
+		t.Fatalf("validación = %+v, se esperaba válido", v)
+	}
+}
@@ -0,0 +1,19 @@
+// Paquete para manejo de cupones y códigos de descuento
+package coupons
+
+import "time" // Manejo de tiempos y fechas
+
+// Estructura que representa la solicitud para crear un cupón
+type CouponRequest struct {
+	Code           string       `json:"code"`                  // Código del cupón
+	Description    string       `json:"description"`           // Descripción interna
+	Type           DiscountType `json:"type"`                  // percentage o fixed
+	Value          float64      `json:"value"`                 // Porcentaje o monto fijo
+	MinOrderValue  float64      `json:"min_order_value"`       // Subtotal mínimo
+	ValidFrom      *time.Time   `json:"valid_from,omitempty"`  // Inicio de vigencia
+	ValidUntil     *time.Time   `json:"valid_until,omitempty"` // Fin de vigencia
+	MaxUses        int          `json:"max_uses"`              // Usos totales permitidos
+	MaxUsesPerUser int          `json:"max_uses_per_user"`     // Usos permitidos por usuario
+	Categories     []string     `json:"categories,omitempty"`  // Categorías permitidas
+	ProductIDs     []string     `json:"product_ids,omitempty"` // Productos permitidos
+}
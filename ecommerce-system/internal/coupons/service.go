@@ -0,0 +1,177 @@
+// Paquete para manejo de cupones y códigos de descuento
+package coupons
+
+import (
+	"context" // Manejo de contexto en funciones
+	"sort"    // Ordenamiento de resultados
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de cupones
+type Service interface {
+	CreateCoupon(ctx context.Context, req CouponRequest) (*Coupon, error)                         // Crear cupón
+	ListCoupons(ctx context.Context) ([]Coupon, error)                                            // Listar cupones
+	GetCoupon(ctx context.Context, code string) (*Coupon, error)                                  // Obtener cupón por código
+	Evaluate(ctx context.Context, code, userID string, lines []Line) (*Application, error)        // Calcular el descuento sin consumir el cupón
+	Redeem(ctx context.Context, code, userID, orderID string, lines []Line) (*Application, error) // Validar, calcular y registrar el uso
+	ReleaseRedemption(ctx context.Context, code, userID, orderID string) error                    // Anular el uso de una orden
+}
+
+// Implementación en memoria del repositorio de cupones
+type inMemoryRepository struct {
+	coupons     map[string]Coupon         // Cupones indexados por código normalizado
+	usesByUser  map[string]map[string]int // Usos por código y usuario
+	redemptions map[string]string         // Orden -> código usado (para anular el uso)
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{
+		coupons:     make(map[string]Coupon),
+		usesByUser:  make(map[string]map[string]int),
+		redemptions: make(map[string]string),
+	}
+}
+
+// Implementación del servicio de cupones
+type couponService struct {
+	mu   sync.Mutex          // Serializa la validación y el registro de usos
+	repo *inMemoryRepository // Repositorio interno
+}
+
+// Constructor para crear un nuevo servicio de cupones
+func NewService(repo *inMemoryRepository) Service {
+	return &couponService{repo: repo}
+}
+
+// Crear un cupón validando sus datos
+func (s *couponService) CreateCoupon(ctx context.Context, req CouponRequest) (*Coupon, error) {
+	code := NormalizeCode(req.Code)
+	if code == "" || req.Value <= 0 || req.MinOrderValue < 0 || req.MaxUses < 0 || req.MaxUsesPerUser < 0 {
+		return nil, ErrInvalidCoupon
+	}
+	if req.Type != TypePercentage && req.Type != TypeFixed {
+		return nil, ErrInvalidCoupon
+	}
+	if req.Type == TypePercentage && req.Value > 100 {
+		return nil, ErrInvalidCoupon
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && req.ValidUntil.Before(*req.ValidFrom) {
+		return nil, ErrInvalidCoupon
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.repo.coupons[code]; exists {
+		return nil, ErrInvalidCoupon
+	}
+	c := Coupon{
+		Code:           code,
+		Description:    req.Description,
+		Type:           req.Type,
+		Value:          req.Value,
+		MinOrderValue:  req.MinOrderValue,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		Categories:     req.Categories,
+		ProductIDs:     req.ProductIDs,
+		CreatedAt:      time.Now(),
+	}
+	s.repo.coupons[code] = c
+	return &c, nil
+}
+
+// Listar cupones ordenados por código
+func (s *couponService) ListCoupons(ctx context.Context) ([]Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Coupon, 0, len(s.repo.coupons))
+	for _, c := range s.repo.coupons {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list, nil
+}
+
+// Obtener un cupón por su código
+func (s *couponService) GetCoupon(ctx context.Context, code string) (*Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.repo.coupons[NormalizeCode(code)]
+	if !ok {
+		return nil, ErrCouponNotFound
+	}
+	return &c, nil
+}
+
+// Calcular el descuento que daría el cupón sin registrar su uso
+func (s *couponService) Evaluate(ctx context.Context, code, userID string, lines []Line) (*Application, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.check(code, userID)
+	if err != nil {
+		return nil, err
+	}
+	return c.Calculate(lines)
+}
+
+// Validar el cupón, calcular el descuento y registrar el uso para la orden en una sola operación
+func (s *couponService) Redeem(ctx context.Context, code, userID, orderID string, lines []Line) (*Application, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.check(code, userID)
+	if err != nil {
+		return nil, err
+	}
+	app, err := c.Calculate(lines)
+	if err != nil {
+		return nil, err
+	}
+	c.Uses++
+	s.repo.coupons[c.Code] = *c
+	if s.repo.usesByUser[c.Code] == nil {
+		s.repo.usesByUser[c.Code] = make(map[string]int)
+	}
+	s.repo.usesByUser[c.Code][userID]++
+	s.repo.redemptions[orderID] = c.Code
+	return app, nil
+}
+
+// Anular el uso del cupón registrado para una orden (por ejemplo, si la orden no llegó a crearse)
+func (s *couponService) ReleaseRedemption(ctx context.Context, code, userID, orderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	normalized := NormalizeCode(code)
+	if s.repo.redemptions[orderID] != normalized {
+		return nil // No hay uso registrado para esa orden
+	}
+	delete(s.repo.redemptions, orderID)
+	if c, ok := s.repo.coupons[normalized]; ok && c.Uses > 0 {
+		c.Uses--
+		s.repo.coupons[normalized] = c
+	}
+	if s.repo.usesByUser[normalized][userID] > 0 {
+		s.repo.usesByUser[normalized][userID]--
+	}
+	return nil
+}
+
+// Verifica existencia, vigencia y límites de uso del cupón (requiere s.mu tomado)
+func (s *couponService) check(code, userID string) (*Coupon, error) {
+	c, ok := s.repo.coupons[NormalizeCode(code)]
+	if !ok {
+		return nil, ErrCouponNotFound
+	}
+	if err := c.CheckValidity(time.Now()); err != nil {
+		return nil, err
+	}
+	if c.MaxUses > 0 && c.Uses >= c.MaxUses {
+		return nil, ErrCouponUsageLimit
+	}
+	if c.MaxUsesPerUser > 0 && s.repo.usesByUser[c.Code][userID] >= c.MaxUsesPerUser {
+		return nil, ErrCouponUserLimit
+	}
+	return &c, nil
+}
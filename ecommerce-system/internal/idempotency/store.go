@@ -0,0 +1,101 @@
+// Paquete para manejo de claves de idempotencia en solicitudes que crean recursos
+package idempotency
+
+import (
+	"context"  // Manejo de contexto en funciones
+	"errors"   // Manejo de errores
+	"net/http" // Encabezados HTTP de la respuesta guardada
+	"sync"     // Sincronización de acceso concurrente
+	"time"     // Manejo de tiempos y fechas
+)
+
+// Record es la respuesta almacenada para una clave
+type Record struct {
+	Fingerprint string      // Huella de la solicitud original (método, ruta y cuerpo)
+	Status      int         // Código HTTP de la respuesta
+	Header      http.Header // Encabezados de la respuesta
+	Body        []byte      // Cuerpo de la respuesta
+	ExpiresAt   time.Time   // Momento en que la clave deja de ser válida
+	completed   bool        // false mientras la solicitud original sigue en curso
+}
+
+// Errores del paquete
+var (
+	ErrFingerprintMismatch = errors.New("idempotency key reused with a different request")
+	ErrInProgress          = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Store guarda las respuestas por clave durante una ventana de tiempo
+type Store interface {
+	Begin(key, fingerprint string) (*Record, error) // Reserva la clave; retorna la respuesta guardada si ya existe
+	Complete(key string, rec Record)                // Guarda la respuesta de una clave reservada
+	Abort(key string)                               // Libera una clave reservada sin guardar respuesta
+}
+
+// Implementación en memoria del almacén de claves
+type inMemoryStore struct {
+	mu      sync.Mutex        // Mutex para sincronizar acceso concurrente
+	ttl     time.Duration     // Ventana de validez de cada clave
+	records map[string]Record // Respuestas indexadas por clave
+}
+
+// Constructor para crear un nuevo almacén en memoria con la ventana de expiración indicada
+func NewInMemoryStore(ttl time.Duration) *inMemoryStore {
+	return &inMemoryStore{ttl: ttl, records: make(map[string]Record)}
+}
+
+// Reserva la clave para una solicitud nueva. Si la clave ya tiene respuesta con la misma huella, la retorna
+// para repetirla; si la huella es distinta o la solicitud original sigue en curso, retorna un error.
+func (s *inMemoryStore) Begin(key, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if rec, ok := s.records[key]; ok && now.Before(rec.ExpiresAt) {
+		if rec.Fingerprint != fingerprint {
+			return nil, ErrFingerprintMismatch
+		}
+		if !rec.completed {
+			return nil, ErrInProgress
+		}
+		return &rec, nil
+	}
+	s.records[key] = Record{Fingerprint: fingerprint, ExpiresAt: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Guarda la respuesta de una clave reservada
+func (s *inMemoryStore) Complete(key string, rec Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec.ExpiresAt = time.Now().Add(s.ttl)
+	rec.completed = true
+	s.records[key] = rec
+}
+
+// Libera una clave reservada (por ejemplo, si la solicitud falló con un error del servidor)
+func (s *inMemoryStore) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+}
+
+// Elimina periódicamente las claves expiradas hasta que se cancele el contexto
+func (s *inMemoryStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, rec := range s.records {
+				if !now.Before(rec.ExpiresAt) {
+					delete(s.records, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
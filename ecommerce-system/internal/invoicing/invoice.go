@@ -0,0 +1,93 @@
+// Paquete para emisión de facturas y notas de crédito con numeración fiscal correlativa
+package invoicing
+
+import (
+	"errors" // Manejo de errores
+	"fmt"    // Formateo de números
+	"math"   // Redondeo de montos
+	"time"   // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping" // Dirección de facturación
+)
+
+// DocumentType distingue facturas de notas de crédito
+type DocumentType string
+
+// Constantes que definen los tipos de documento
+const (
+	TypeInvoice    DocumentType = "invoice"     // Factura por una orden pagada
+	TypeCreditNote DocumentType = "credit_note" // Corrige (anula total o parcialmente) una factura
+)
+
+// Party son los datos fiscales de quien emite o recibe el documento
+type Party struct {
+	UserID  string            `json:"user_id,omitempty"` // Usuario del cliente
+	Name    string            `json:"name"`              // Nombre o razón social
+	TaxID   string            `json:"tax_id,omitempty"`  // Identificación tributaria
+	Email   string            `json:"email,omitempty"`   // Correo
+	Address *shipping.Address `json:"address,omitempty"` // Dirección fiscal
+}
+
+// InvoiceLine es una línea del documento (copia de la línea de la orden)
+type InvoiceLine struct {
+	Line        int     `json:"line"`          // Número de línea de la orden
+	Description string  `json:"description"`   // Nombre del producto al comprar
+	SKU         string  `json:"sku,omitempty"` // SKU del producto al comprar
+	Quantity    int     `json:"quantity"`      // Unidades
+	UnitPrice   float64 `json:"unit_price"`    // Precio unitario
+	Discount    float64 `json:"discount"`      // Descuentos de la línea
+	Tax         float64 `json:"tax"`           // Impuesto de la línea
+	Total       float64 `json:"total"`         // Precio × cantidad - descuento + impuesto
+}
+
+// Invoice es un documento fiscal emitido; nunca se modifica (las correcciones son notas de crédito)
+type Invoice struct {
+	Number        string        `json:"number"`             // Serie y correlativo (ej. F001-00000001)
+	Series        string        `json:"series"`             // Serie
+	Sequence      int           `json:"sequence"`           // Correlativo dentro de la serie, sin saltos
+	Type          DocumentType  `json:"type"`               // invoice o credit_note
+	OrderID       string        `json:"order_id"`           // Orden facturada
+	Corrects      string        `json:"corrects,omitempty"` // Factura que corrige (notas de crédito)
+	Reason        string        `json:"reason,omitempty"`   // Motivo de la nota de crédito
+	Issuer        Party         `json:"issuer"`             // Emisor
+	Customer      Party         `json:"customer"`           // Cliente
+	Lines         []InvoiceLine `json:"lines"`              // Líneas
+	Subtotal      float64       `json:"subtotal"`           // Suma de precio × cantidad
+	DiscountTotal float64       `json:"discount_total"`     // Suma de descuentos
+	TaxTotal      float64       `json:"tax_total"`          // Suma de impuestos
+	ShippingTotal float64       `json:"shipping_total"`     // Envío
+	GrandTotal    float64       `json:"grand_total"`        // Total del documento
+	IssuedAt      time.Time     `json:"issued_at"`          // Fecha de emisión
+}
+
+// Errores del servicio de facturación
+var (
+	ErrInvoiceNotFound   = errors.New("invoice not found")
+	ErrAlreadyInvoiced   = errors.New("order already has an invoice")
+	ErrNotInvoiceable    = errors.New("order is not fully paid")
+	ErrInvalidCreditNote = errors.New("invalid credit note")
+	ErrNothingToCredit   = errors.New("invoice is already fully credited")
+	ErrNotAnInvoice      = errors.New("credit notes can only correct invoices")
+)
+
+// Función que arma el número del documento a partir de la serie y el correlativo
+func formatNumber(series string, seq int) string {
+	return fmt.Sprintf("%s-%08d", series, seq)
+}
+
+// Método que recalcula los totales a partir de las líneas y el envío
+func (inv *Invoice) computeTotals() {
+	inv.Subtotal, inv.DiscountTotal, inv.TaxTotal = 0, 0, 0
+	for _, l := range inv.Lines {
+		inv.Subtotal += l.UnitPrice * float64(l.Quantity)
+		inv.DiscountTotal += l.Discount
+		inv.TaxTotal += l.Tax
+	}
+	inv.Subtotal, inv.DiscountTotal, inv.TaxTotal = roundCents(inv.Subtotal), roundCents(inv.DiscountTotal), roundCents(inv.TaxTotal)
+	inv.GrandTotal = roundCents(inv.Subtotal - inv.DiscountTotal + inv.TaxTotal + inv.ShippingTotal)
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,14 @@
+// Paquete para emisión de facturas y notas de crédito con numeración fiscal correlativa
+package invoicing
+
+// Estructura que representa la solicitud de una nota de crédito
+type CreditNoteRequest struct {
+	Reason string                  `json:"reason"`          // Motivo de la corrección (obligatorio)
+	Lines  []CreditNoteLineRequest `json:"lines,omitempty"` // Líneas y unidades a acreditar (vacío = todo lo pendiente, con envío)
+}
+
+// Estructura que representa una línea dentro de la solicitud de nota de crédito
+type CreditNoteLineRequest struct {
+	Line     int `json:"line"`     // Número de línea de la factura
+	Quantity int `json:"quantity"` // Unidades a acreditar
+}
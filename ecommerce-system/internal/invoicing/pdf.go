@@ -0,0 +1,79 @@
+// Paquete para emisión de facturas y notas de crédito con numeración fiscal correlativa
+package invoicing
+
+import (
+	"bytes"   // Armado del archivo
+	"fmt"     // Escritura de objetos PDF
+	"strings" // Escape de texto
+)
+
+// Líneas de texto por página del PDF
+const pdfLinesPerPage = 60
+
+// Arma un PDF 1.4 mínimo con las líneas en Courier (WinAnsi); sin dependencias externas
+func buildPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objetos: 1 catálogo, 2 árbol de páginas, 3 fuente; luego página y contenido por cada página
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		var content bytes.Buffer
+		content.WriteString("BT\n/F1 9 Tf\n11 TL\n40 800 Td\n")
+		for _, line := range page {
+			content.WriteString("(" + pdfEscape(line) + ") Tj T*\n")
+		}
+		content.WriteString("ET\n")
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// Escapa el texto para una cadena PDF; los caracteres fuera de ASCII se escriben en octal (WinAnsi)
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 128:
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteString(`\205`)
+		case r < 256:
+			fmt.Fprintf(&b, "\\%03o", r) // Latin-1 coincide con WinAnsi en este rango
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
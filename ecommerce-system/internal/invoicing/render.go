@@ -0,0 +1,125 @@
+// Paquete para emisión de facturas y notas de crédito con numeración fiscal correlativa
+package invoicing
+
+import (
+	"bytes"         // Buffer de salida
+	"fmt"           // Formateo de montos
+	"html/template" // Plantilla HTML con escape automático
+	"strings"       // Armado de líneas de texto
+)
+
+// Plantilla HTML del documento
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{"money": money}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-top: 1em; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.num, th.num { text-align: right; }
+.totals td { border: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} {{.Number}}</h1>
+<p>Fecha de emisión: {{.IssuedAt.Format "2006-01-02 15:04"}}<br>Orden: {{.OrderID}}{{if .Corrects}}<br>Corrige la factura: {{.Corrects}}<br>Motivo: {{.Reason}}{{end}}</p>
+<h2>Emisor</h2>
+<p>{{.Issuer.Name}}{{if .Issuer.TaxID}}<br>ID fiscal: {{.Issuer.TaxID}}{{end}}</p>
+<h2>Cliente</h2>
+<p>{{.Customer.Name}}{{if .Customer.Email}}<br>{{.Customer.Email}}{{end}}{{with .Customer.Address}}<br>{{.Line1}}{{if .Line2}}, {{.Line2}}{{end}}<br>{{.City}}{{if .Region}}, {{.Region}}{{end}} {{.PostalCode}} {{.Country}}{{end}}</p>
+<table>
+<tr><th>#</th><th>Descripción</th><th>SKU</th><th class="num">Cant.</th><th class="num">Precio</th><th class="num">Descuento</th><th class="num">Impuesto</th><th class="num">Total</th></tr>
+{{range .Lines}}<tr><td>{{.Line}}</td><td>{{.Description}}</td><td>{{.SKU}}</td><td class="num">{{.Quantity}}</td><td class="num">{{money .UnitPrice}}</td><td class="num">{{money .Discount}}</td><td class="num">{{money .Tax}}</td><td class="num">{{money .Total}}</td></tr>
+{{end}}</table>
+<table class="totals">
+<tr><td class="num">Subtotal</td><td class="num">{{money .Subtotal}}</td></tr>
+<tr><td class="num">Descuentos</td><td class="num">-{{money .DiscountTotal}}</td></tr>
+<tr><td class="num">Impuestos</td><td class="num">{{money .TaxTotal}}</td></tr>
+<tr><td class="num">Envío</td><td class="num">{{money .ShippingTotal}}</td></tr>
+<tr><td class="num"><strong>Total</strong></td><td class="num"><strong>{{money .GrandTotal}}</strong></td></tr>
+</table>
+</body>
+</html>
+`))
+
+// Datos que recibe la plantilla
+type htmlView struct {
+	*Invoice
+	Title string // Factura o Nota de crédito
+}
+
+// Genera el documento en HTML
+func RenderHTML(inv *Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, htmlView{Invoice: inv, Title: inv.title()}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Genera el documento en PDF (texto monoespaciado, una o más páginas)
+func RenderPDF(inv *Invoice) []byte {
+	return buildPDF(inv.textLines())
+}
+
+// Método que retorna el título del documento según su tipo
+func (inv *Invoice) title() string {
+	if inv.Type == TypeCreditNote {
+		return "Nota de crédito"
+	}
+	return "Factura"
+}
+
+// Método que arma el documento como líneas de texto de ancho fijo
+func (inv *Invoice) textLines() []string {
+	lines := []string{
+		fmt.Sprintf("%s %s", strings.ToUpper(inv.title()), inv.Number),
+		"Fecha de emisión: " + inv.IssuedAt.Format("2006-01-02 15:04"),
+		"Orden: " + inv.OrderID,
+	}
+	if inv.Corrects != "" {
+		lines = append(lines, "Corrige la factura: "+inv.Corrects, "Motivo: "+inv.Reason)
+	}
+	lines = append(lines, "", "Emisor: "+inv.Issuer.Name)
+	if inv.Issuer.TaxID != "" {
+		lines = append(lines, "ID fiscal: "+inv.Issuer.TaxID)
+	}
+	lines = append(lines, "", "Cliente: "+inv.Customer.Name)
+	if inv.Customer.Email != "" {
+		lines = append(lines, inv.Customer.Email)
+	}
+	if a := inv.Customer.Address; a != nil {
+		lines = append(lines, strings.TrimSuffix(a.Line1+", "+a.Line2, ", "),
+			strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %s", a.City, a.Region, a.PostalCode, a.Country)), " "))
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("%-3s %-30s %5s %10s %10s %10s %11s", "#", "Descripción", "Cant.", "Precio", "Descuento", "Impuesto", "Total"),
+		strings.Repeat("-", 85))
+	for _, l := range inv.Lines {
+		lines = append(lines, fmt.Sprintf("%-3d %-30s %5d %10s %10s %10s %11s",
+			l.Line, truncate(l.Description, 30), l.Quantity, money(l.UnitPrice), money(l.Discount), money(l.Tax), money(l.Total)))
+	}
+	lines = append(lines, strings.Repeat("-", 85),
+		fmt.Sprintf("%73s %11s", "Subtotal", money(inv.Subtotal)),
+		fmt.Sprintf("%73s %11s", "Descuentos", "-"+money(inv.DiscountTotal)),
+		fmt.Sprintf("%73s %11s", "Impuestos", money(inv.TaxTotal)),
+		fmt.Sprintf("%73s %11s", "Envío", money(inv.ShippingTotal)),
+		fmt.Sprintf("%73s %11s", "TOTAL", money(inv.GrandTotal)))
+	return lines
+}
+
+// Formatea un monto con dos decimales
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// Recorta un texto a n caracteres
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,302 @@
+// Paquete para emisión de facturas y notas de crédito con numeración fiscal correlativa
+package invoicing
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de errores
+	"log"     // Registro de fallas al facturar automáticamente
+	"math"    // Tope del ajuste de una nota de crédito
+	"sort"    // Ordenamiento de resultados
+	"strings" // Limpieza del motivo
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders" // Órdenes facturadas
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/users"  // Datos del cliente
+)
+
+// Series por defecto
+const (
+	DefaultInvoiceSeries    = "F001"  // Serie de facturas
+	DefaultCreditNoteSeries = "NC001" // Serie de notas de crédito
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de facturación
+type Service interface {
+	OrderPaid(ctx context.Context, o orders.Order)                                               // Factura automáticamente la orden pagada
+	OrderRefunded(ctx context.Context, o orders.Order, amount float64, quantities map[int]int)   // Acredita automáticamente lo reembolsado
+	IssueInvoice(ctx context.Context, o orders.Order) (*Invoice, error)                          // Emitir la factura de una orden pagada
+	IssueCreditNote(ctx context.Context, number string, req CreditNoteRequest) (*Invoice, error) // Corregir una factura con una nota de crédito
+	GetInvoice(ctx context.Context, number string) (*Invoice, error)                             // Obtener documento por número
+	ListInvoices(ctx context.Context, series string) ([]Invoice, error)                          // Listar documentos (filtro opcional por serie)
+	ListByOrder(ctx context.Context, orderID string) ([]Invoice, error)                          // Documentos de una orden
+}
+
+// Implementación en memoria del repositorio de facturas; solo admite agregar documentos
+type inMemoryRepository struct {
+	invoices  map[string]Invoice // Documentos por número
+	sequences map[string]int     // Último correlativo emitido por serie
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{invoices: make(map[string]Invoice), sequences: make(map[string]int)}
+}
+
+// Implementación del servicio de facturación
+type invoiceService struct {
+	mu               sync.Mutex          // Serializa la emisión para que los correlativos no tengan saltos ni duplicados
+	repo             *inMemoryRepository // Repositorio interno
+	userService      users.Service       // Datos del cliente
+	issuer           Party               // Emisor de los documentos
+	invoiceSeries    string              // Serie de facturas
+	creditNoteSeries string              // Serie de notas de crédito
+}
+
+// Option configura dependencias opcionales del servicio de facturación
+type Option func(*invoiceService)
+
+// Opción que cambia las series de facturas y notas de crédito
+func WithSeries(invoiceSeries, creditNoteSeries string) Option {
+	return func(s *invoiceService) { s.invoiceSeries, s.creditNoteSeries = invoiceSeries, creditNoteSeries }
+}
+
+// Constructor para crear un nuevo servicio de facturación con los datos del emisor
+func NewService(repo *inMemoryRepository, issuer Party, userService users.Service, opts ...Option) Service {
+	s := &invoiceService{
+		repo:             repo,
+		userService:      userService,
+		issuer:           issuer,
+		invoiceSeries:    DefaultInvoiceSeries,
+		creditNoteSeries: DefaultCreditNoteSeries,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Factura la orden en cuanto queda pagada; una falla se registra para emitirla luego a mano
+// con POST /orders/{orderId}/invoices
+func (s *invoiceService) OrderPaid(ctx context.Context, o orders.Order) {
+	if _, err := s.IssueInvoice(ctx, o); err != nil {
+		log.Printf("No se pudo facturar la orden %s: %v\n", o.ID, err)
+	}
+}
+
+// Emitir la factura de una orden pagada copiando sus líneas, impuestos y datos del cliente
+func (s *invoiceService) IssueInvoice(ctx context.Context, o orders.Order) (*Invoice, error) {
+	if !o.IsPaid() {
+		return nil, ErrNotInvoiceable
+	}
+	inv := Invoice{
+		Type:          TypeInvoice,
+		OrderID:       o.ID,
+		Issuer:        s.issuer,
+		Customer:      s.customer(ctx, o),
+		ShippingTotal: o.ShippingTotal,
+	}
+	for _, item := range o.LineItems {
+		inv.Lines = append(inv.Lines, InvoiceLine{
+			Line:        item.Line,
+			Description: item.Product.Name,
+			SKU:         item.Product.SKU,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Discount:    item.Discount,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		})
+	}
+	inv.computeTotals()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.repo.invoices {
+		if existing.OrderID == o.ID && existing.Type == TypeInvoice {
+			return nil, ErrAlreadyInvoiced
+		}
+	}
+	s.issue(&inv, s.invoiceSeries)
+	return &inv, nil
+}
+
+// Emite una nota de crédito por cada reembolso de una orden facturada, para que lo acreditado coincida con
+// lo devuelto: las unidades reembolsadas y, si el monto es mayor (envío, ajustes), una línea por la diferencia
+func (s *invoiceService) OrderRefunded(ctx context.Context, o orders.Order, amount float64, quantities map[int]int) {
+	number := ""
+	for _, inv := range s.list(func(inv Invoice) bool { return inv.OrderID == o.ID && inv.Type == TypeInvoice }) {
+		number = inv.Number
+	}
+	if number == "" {
+		log.Printf("Reembolso de la orden %s sin factura que acreditar\n", o.ID)
+		return
+	}
+	req := CreditNoteRequest{Reason: "Reembolso de la orden " + o.ID}
+	for line, qty := range quantities {
+		req.Lines = append(req.Lines, CreditNoteLineRequest{Line: line, Quantity: qty})
+	}
+	sort.Slice(req.Lines, func(i, j int) bool { return req.Lines[i].Line < req.Lines[j].Line })
+	if _, err := s.creditNote(number, req, amount); err != nil {
+		log.Printf("No se pudo emitir la nota de crédito del reembolso de la orden %s: %v\n", o.ID, err)
+	}
+}
+
+// Emitir una nota de crédito sobre una factura: por líneas y unidades, o por todo lo que falta acreditar
+func (s *invoiceService) IssueCreditNote(ctx context.Context, number string, req CreditNoteRequest) (*Invoice, error) {
+	return s.creditNote(number, req, 0)
+}
+
+// Arma y emite la nota de crédito; con amount > 0 (y líneas) se agrega un ajuste hasta completar ese monto
+func (s *invoiceService) creditNote(number string, req CreditNoteRequest, amount float64) (*Invoice, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidCreditNote)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	original, ok := s.repo.invoices[number]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	if original.Type != TypeInvoice {
+		return nil, ErrNotAnInvoice
+	}
+
+	// Unidades, envío y total ya acreditados por notas anteriores
+	credited := make(map[int]int)
+	var creditedShipping, creditedTotal float64
+	for _, cn := range s.repo.invoices {
+		if cn.Corrects == number {
+			for _, l := range cn.Lines {
+				credited[l.Line] += l.Quantity
+			}
+			creditedShipping += cn.ShippingTotal
+			creditedTotal += cn.GrandTotal
+		}
+	}
+
+	note := Invoice{
+		Type:     TypeCreditNote,
+		OrderID:  original.OrderID,
+		Corrects: number,
+		Reason:   reason,
+		Issuer:   original.Issuer,
+		Customer: original.Customer,
+	}
+	requested := make(map[int]int)
+	if len(req.Lines) == 0 && amount <= 0 {
+		for _, l := range original.Lines {
+			requested[l.Line] = l.Quantity - credited[l.Line]
+		}
+		note.ShippingTotal = roundCents(original.ShippingTotal - creditedShipping) // La anulación total incluye el envío
+	}
+	for _, l := range req.Lines {
+		if l.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidCreditNote, l.Line)
+		}
+		requested[l.Line] += l.Quantity
+	}
+	for _, l := range original.Lines {
+		qty := requested[l.Line]
+		delete(requested, l.Line)
+		if qty <= 0 {
+			continue
+		}
+		if qty > l.Quantity-credited[l.Line] {
+			return nil, fmt.Errorf("%w: line %d exceeds the quantity left to credit", ErrInvalidCreditNote, l.Line)
+		}
+		share := float64(qty) / float64(l.Quantity) // Descuento e impuesto en proporción a las unidades
+		line := l
+		line.Quantity = qty
+		line.Discount = roundCents(l.Discount * share)
+		line.Tax = roundCents(l.Tax * share)
+		line.Total = roundCents(line.UnitPrice*float64(qty) - line.Discount + line.Tax)
+		note.Lines = append(note.Lines, line)
+	}
+	for line := range requested {
+		return nil, fmt.Errorf("%w: line %d is not on the invoice", ErrInvalidCreditNote, line)
+	}
+	if amount > 0 {
+		var linesTotal float64
+		for _, l := range note.Lines {
+			linesTotal += l.Total
+		}
+		// El ajuste nunca acredita más de lo que queda de la factura
+		adjustment := roundCents(math.Min(amount, original.GrandTotal-creditedTotal) - linesTotal)
+		if adjustment >= 0.01 {
+			note.Lines = append(note.Lines, InvoiceLine{Description: "Ajuste de reembolso", Quantity: 1, UnitPrice: adjustment, Total: adjustment})
+		}
+	}
+	if len(note.Lines) == 0 && note.ShippingTotal <= 0 {
+		return nil, ErrNothingToCredit
+	}
+	note.computeTotals()
+	s.issue(&note, s.creditNoteSeries)
+	return &note, nil
+}
+
+// Obtener un documento por su número
+func (s *invoiceService) GetInvoice(ctx context.Context, number string) (*Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv, ok := s.repo.invoices[number]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	return &inv, nil
+}
+
+// Listar documentos, opcionalmente de una serie
+func (s *invoiceService) ListInvoices(ctx context.Context, series string) ([]Invoice, error) {
+	return s.list(func(inv Invoice) bool { return series == "" || inv.Series == series }), nil
+}
+
+// Listar la factura y las notas de crédito de una orden
+func (s *invoiceService) ListByOrder(ctx context.Context, orderID string) ([]Invoice, error) {
+	return s.list(func(inv Invoice) bool { return inv.OrderID == orderID }), nil
+}
+
+// Asigna el siguiente correlativo de la serie y guarda el documento (requiere s.mu tomado)
+func (s *invoiceService) issue(inv *Invoice, series string) {
+	s.repo.sequences[series]++
+	inv.Series, inv.Sequence = series, s.repo.sequences[series]
+	inv.Number = formatNumber(series, inv.Sequence)
+	inv.IssuedAt = time.Now()
+	s.repo.invoices[inv.Number] = *inv
+}
+
+// Datos del cliente: perfil del usuario y dirección de facturación de la orden
+func (s *invoiceService) customer(ctx context.Context, o orders.Order) Party {
+	c := Party{UserID: o.UserID, Name: o.UserID}
+	if o.BillingAddress != nil {
+		address := *o.BillingAddress
+		c.Address, c.Name = &address, address.Name
+	}
+	if u, err := s.userService.GetUserByID(ctx, o.UserID); err == nil {
+		c.Email = u.Email
+		if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" && o.BillingAddress == nil {
+			c.Name = name
+		}
+	}
+	return c
+}
+
+// Documentos que cumplen el filtro, ordenados por serie y correlativo
+func (s *invoiceService) list(match func(Invoice) bool) []Invoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Invoice{}
+	for _, inv := range s.repo.invoices {
+		if match(inv) {
+			list = append(list, inv)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Series != list[j].Series {
+			return list[i].Series < list[j].Series
+		}
+		return list[i].Sequence < list[j].Sequence
+	})
+	return list
+}
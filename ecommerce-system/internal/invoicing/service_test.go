@@ -0,0 +1,186 @@
+// Pruebas de la numeración correlativa y de los topes de las notas de crédito
+package invoicing_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"fmt"     // Formateo de IDs de orden
+	"math"    // Comparación de montos
+	"sync"    // Emisión concurrente
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/invoicing"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/users"
+)
+
+// Crea el servicio de facturación con un emisor de prueba
+func newService() invoicing.Service {
+	issuer := invoicing.Party{Name: "Tienda Demo", TaxID: "1790000000001"}
+	return invoicing.NewService(invoicing.NewInMemoryRepository(), issuer, users.NewService(users.NewInMemoryRepository()))
+}
+
+// Orden pagada: 3 cafés a 10 y 1 té a 5 con 12% de impuesto, más 4 de envío (total 43.20)
+func paidOrder(id string) orders.Order {
+	return orders.Order{
+		ID:     id,
+		UserID: "user-1",
+		LineItems: []orders.LineItem{
+			{Line: 1, Product: orders.ProductSnapshot{Name: "Café", SKU: "CAF-1"}, Quantity: 3, Price: 10, Subtotal: 30, Tax: 3.6, Total: 33.6},
+			{Line: 2, Product: orders.ProductSnapshot{Name: "Té", SKU: "TE-1"}, Quantity: 1, Price: 5, Subtotal: 5, Tax: 0.6, Total: 5.6},
+		},
+		ShippingTotal: 4,
+		GrandTotal:    43.2,
+		PaidAmount:    43.2,
+	}
+}
+
+// Suma lo acreditado por las notas de crédito de una factura
+func credited(t *testing.T, svc invoicing.Service, orderID string) float64 {
+	t.Helper()
+	docs, err := svc.ListByOrder(context.Background(), orderID)
+	if err != nil {
+		t.Fatalf("ListByOrder: %v", err)
+	}
+	var total float64
+	for _, d := range docs {
+		if d.Type == invoicing.TypeCreditNote {
+			total += d.GrandTotal
+		}
+	}
+	return math.Round(total*100) / 100
+}
+
+func TestInvoiceNumberingIsGapFree(t *testing.T) {
+	ctx := context.Background()
+	svc := newService()
+
+	// Una orden sin pagar no consume correlativo
+	unpaid := paidOrder("ORD-UNPAID")
+	unpaid.PaidAmount = 0
+	if _, err := svc.IssueInvoice(ctx, unpaid); !errors.Is(err, invoicing.ErrNotInvoiceable) {
+		t.Fatalf("orden sin pagar: err = %v, se esperaba %v", err, invoicing.ErrNotInvoiceable)
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := svc.IssueInvoice(ctx, paidOrder(fmt.Sprintf("ORD-%02d", i))); err != nil {
+				t.Errorf("IssueInvoice: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if _, err := svc.IssueInvoice(ctx, paidOrder("ORD-00")); !errors.Is(err, invoicing.ErrAlreadyInvoiced) {
+		t.Fatalf("segunda factura: err = %v, se esperaba %v", err, invoicing.ErrAlreadyInvoiced)
+	}
+
+	list, _ := svc.ListInvoices(ctx, invoicing.DefaultInvoiceSeries)
+	if len(list) != n {
+		t.Fatalf("facturas = %d, se esperaba %d", len(list), n)
+	}
+	for i, inv := range list {
+		want := fmt.Sprintf("%s-%08d", invoicing.DefaultInvoiceSeries, i+1)
+		if inv.Sequence != i+1 || inv.Number != want {
+			t.Fatalf("documento %d = %s (correlativo %d), se esperaba %s", i, inv.Number, inv.Sequence, want)
+		}
+	}
+
+	// Las notas de crédito llevan su propia serie
+	note, err := svc.IssueCreditNote(ctx, list[0].Number, invoicing.CreditNoteRequest{Reason: "Anulación"})
+	if err != nil {
+		t.Fatalf("IssueCreditNote: %v", err)
+	}
+	if want := invoicing.DefaultCreditNoteSeries + "-00000001"; note.Number != want {
+		t.Fatalf("nota de crédito = %s, se esperaba %s", note.Number, want)
+	}
+}
+
+func TestCreditNoteCaps(t *testing.T) {
+	ctx := context.Background()
+	svc := newService()
+	inv, err := svc.IssueInvoice(ctx, paidOrder("ORD-1"))
+	if err != nil {
+		t.Fatalf("IssueInvoice: %v", err)
+	}
+
+	// Dos cafés con su impuesto proporcional
+	note, err := svc.IssueCreditNote(ctx, inv.Number, invoicing.CreditNoteRequest{
+		Reason: "Producto dañado",
+		Lines:  []invoicing.CreditNoteLineRequest{{Line: 1, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("IssueCreditNote: %v", err)
+	}
+	if note.GrandTotal != 22.4 || note.TaxTotal != 2.4 || note.Corrects != inv.Number {
+		t.Fatalf("nota = %.2f (impuesto %.2f, corrige %s), se esperaba 22.40 (2.40) sobre %s", note.GrandTotal, note.TaxTotal, note.Corrects, inv.Number)
+	}
+
+	tests := []struct {
+		name   string
+		number string
+		req    invoicing.CreditNoteRequest
+		want   error
+	}{
+		{name: "sin motivo", number: inv.Number, req: invoicing.CreditNoteRequest{Lines: []invoicing.CreditNoteLineRequest{{Line: 2, Quantity: 1}}}, want: invoicing.ErrInvalidCreditNote},
+		{name: "más unidades que las pendientes", number: inv.Number, req: invoicing.CreditNoteRequest{Reason: "x", Lines: []invoicing.CreditNoteLineRequest{{Line: 1, Quantity: 2}}}, want: invoicing.ErrInvalidCreditNote},
+		{name: "línea que no está en la factura", number: inv.Number, req: invoicing.CreditNoteRequest{Reason: "x", Lines: []invoicing.CreditNoteLineRequest{{Line: 9, Quantity: 1}}}, want: invoicing.ErrInvalidCreditNote},
+		{name: "cantidad cero", number: inv.Number, req: invoicing.CreditNoteRequest{Reason: "x", Lines: []invoicing.CreditNoteLineRequest{{Line: 2, Quantity: 0}}}, want: invoicing.ErrInvalidCreditNote},
+		{name: "corregir una nota de crédito", number: note.Number, req: invoicing.CreditNoteRequest{Reason: "x"}, want: invoicing.ErrNotAnInvoice},
+		{name: "factura inexistente", number: "F001-99999999", req: invoicing.CreditNoteRequest{Reason: "x"}, want: invoicing.ErrInvoiceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.IssueCreditNote(ctx, tt.number, tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+
+	// Sin líneas se acredita todo lo pendiente, envío incluido, y la factura queda saldada
+	rest, err := svc.IssueCreditNote(ctx, inv.Number, invoicing.CreditNoteRequest{Reason: "Anulación"})
+	if err != nil {
+		t.Fatalf("IssueCreditNote del resto: %v", err)
+	}
+	if rest.ShippingTotal != 4 || rest.GrandTotal != 20.8 {
+		t.Fatalf("resto = %.2f (envío %.2f), se esperaba 20.80 con 4 de envío", rest.GrandTotal, rest.ShippingTotal)
+	}
+	if got := credited(t, svc, "ORD-1"); got != inv.GrandTotal {
+		t.Fatalf("acreditado = %.2f, se esperaba %.2f", got, inv.GrandTotal)
+	}
+	if _, err := svc.IssueCreditNote(ctx, inv.Number, invoicing.CreditNoteRequest{Reason: "Anulación"}); !errors.Is(err, invoicing.ErrNothingToCredit) {
+		t.Fatalf("tercera nota: err = %v, se esperaba %v", err, invoicing.ErrNothingToCredit)
+	}
+}
+
+func TestRefundCreditNoteAdjustmentIsCapped(t *testing.T) {
+	ctx := context.Background()
+	svc := newService()
+	order := paidOrder("ORD-1")
+	svc.OrderPaid(ctx, order)
+
+	// Un café (11.20) reembolsado por 15: la diferencia va en una línea de ajuste
+	svc.OrderRefunded(ctx, order, 15, map[int]int{1: 1})
+	if got := credited(t, svc, order.ID); got != 15 {
+		t.Fatalf("acreditado = %.2f, se esperaba 15.00", got)
+	}
+
+	// Un reembolso mayor a lo que queda solo acredita el saldo de la factura
+	svc.OrderRefunded(ctx, order, 100, nil)
+	if got := credited(t, svc, order.ID); got != order.GrandTotal {
+		t.Fatalf("acreditado = %.2f, se esperaba %.2f", got, order.GrandTotal)
+	}
+	notes, _ := svc.ListInvoices(ctx, invoicing.DefaultCreditNoteSeries)
+	if len(notes) != 2 {
+		t.Fatalf("notas de crédito = %d, se esperaba 2", len(notes))
+	}
+
+	// Con la factura saldada no se emiten más notas
+	svc.OrderRefunded(ctx, order, 5, nil)
+	if notes, _ := svc.ListInvoices(ctx, invoicing.DefaultCreditNoteSeries); len(notes) != 2 {
+		t.Fatalf("notas de crédito = %d, se esperaba 2", len(notes))
+	}
+}
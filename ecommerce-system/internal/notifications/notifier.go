@@ -0,0 +1,67 @@
+// Paquete para el envío de notificaciones a través de canales intercambiables
+package notifications
+
+import (
+	"context"       // Manejo de contexto en funciones
+	"encoding/json" // Serialización de notificaciones
+	"log"           // Registro de eventos
+	"os"            // Manejo de archivos
+	"sync"          // Sincronización de escrituras al archivo
+	"time"          // Manejo de tiempos y fechas
+)
+
+// Notification representa un mensaje a enviar
+type Notification struct {
+	Kind      string            `json:"kind"`           // Tipo de notificación (ej. "low_stock")
+	Subject   string            `json:"subject"`        // Asunto breve
+	Message   string            `json:"message"`        // Mensaje detallado
+	Data      map[string]string `json:"data,omitempty"` // Datos adicionales para el destinatario
+	CreatedAt time.Time         `json:"created_at"`     // Fecha de la notificación
+}
+
+// Notifier es la interfaz que debe implementar cualquier canal de notificación (log, archivo, email, chat...)
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier escribe las notificaciones en el registro estándar
+type LogNotifier struct{}
+
+// Constructor para crear un notificador que escribe en el registro estándar
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Escribe la notificación en el registro
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("[%s] %s: %s %v\n", notification.Kind, notification.Subject, notification.Message, notification.Data)
+	return nil
+}
+
+// FileNotifier agrega cada notificación como una línea JSON en un archivo
+type FileNotifier struct {
+	mu   sync.Mutex // Evita que dos escrituras se intercalen
+	path string     // Ruta del archivo de notificaciones
+}
+
+// Constructor para crear un notificador que escribe en un archivo
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Agrega la notificación al final del archivo
+func (n *FileNotifier) Notify(ctx context.Context, notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,64 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons" // Motor de cupones
+)
+
+// CouponRedeemer valida y registra el uso de cupones de descuento
+type CouponRedeemer interface {
+	Evaluate(ctx context.Context, code, userID string, lines []coupons.Line) (*coupons.Application, error)
+	Redeem(ctx context.Context, code, userID, orderID string, lines []coupons.Line) (*coupons.Application, error)
+	ReleaseRedemption(ctx context.Context, code, userID, orderID string) error
+}
+
+// Error que indica que se envió un cupón pero el motor de cupones no está configurado
+var ErrCouponsDisabled = errors.New("coupons are not enabled")
+
+// Opción que habilita los cupones de descuento al crear órdenes
+func WithCoupons(redeemer CouponRedeemer) Option {
+	return func(s *orderService) { s.coupons = redeemer }
+}
+
+// couponStep calcula el descuento del cupón solicitado sin registrar su uso (el uso se registra al guardar la orden)
+type couponStep struct{ s *orderService }
+
+// Nombre del paso de cupones
+func (st couponStep) Name() string { return "coupon" }
+
+// Valida el cupón y reparte su descuento entre las líneas
+func (st couponStep) Apply(ctx context.Context, p *Pricing) error {
+	if p.CouponCode == "" {
+		return nil
+	}
+	if st.s.coupons == nil {
+		return ErrCouponsDisabled
+	}
+	p.couponLines = couponLines(p.Lines)
+	app, err := st.s.coupons.Evaluate(ctx, p.CouponCode, p.UserID, p.couponLines)
+	if err != nil {
+		return err
+	}
+	for i, d := range app.LineDiscounts {
+		p.AddLineDiscount(st.Name(), app.Code, i, d)
+	}
+	p.Coupon = app
+	return nil
+}
+
+// Convierte las líneas de la orden al formato que evalúa el motor de cupones (precio neto de promociones)
+func couponLines(items []LineItem) []coupons.Line {
+	lines := make([]coupons.Line, len(items))
+	for i, item := range items {
+		lines[i] = coupons.Line{
+			ProductID: item.ProductID,
+			Category:  item.Product.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: (item.Price*float64(item.Quantity) - item.Discount) / float64(item.Quantity),
+		}
+	}
+	return lines
+}
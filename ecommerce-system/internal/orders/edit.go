@@ -0,0 +1,321 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de errores
+	"sort"    // Orden estable de los ajustes de stock
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Movimientos de inventario
+)
+
+// EditAction es el tipo de cambio hecho sobre una orden pendiente
+type EditAction string
+
+// Constantes que definen los cambios posibles
+const (
+	EditAddItem        EditAction = "add_item"        // Se agregó un producto o unidades a una línea
+	EditRemoveItem     EditAction = "remove_item"     // Se quitó una línea
+	EditChangeQuantity EditAction = "change_quantity" // Se cambió la cantidad de una línea
+)
+
+// OrderChange es una entrada del historial de ediciones de la orden
+type OrderChange struct {
+	Action        EditAction `json:"action"`          // Tipo de cambio
+	Line          int        `json:"line,omitempty"`  // Línea afectada antes del cambio (vacío si es nueva)
+	ProductID     string     `json:"product_id"`      // Producto de la línea
+	FromQuantity  int        `json:"from_quantity"`   // Cantidad antes del cambio
+	ToQuantity    int        `json:"to_quantity"`     // Cantidad después del cambio
+	PreviousTotal float64    `json:"previous_total"`  // Total de la orden antes del cambio
+	NewTotal      float64    `json:"new_total"`       // Total de la orden después del cambio
+	Actor         string     `json:"actor,omitempty"` // Usuario que hizo el cambio
+	At            time.Time  `json:"at"`              // Fecha del cambio
+}
+
+// Errores de la edición de órdenes
+var (
+	ErrOrderNotEditable = errors.New("order can only be edited while pending and without payments")
+	ErrInvalidEdit      = errors.New("invalid order edit")
+)
+
+// Línea pedida durante una edición; from es la línea original (conserva su precio y datos del producto)
+type editItem struct {
+	ProductID string
+	Quantity  int
+	from      *LineItem
+}
+
+// Agregar un producto a una orden pendiente; si ya está en una línea se suman las unidades
+func (s *orderService) AddLineItem(ctx context.Context, orderID string, item LineItemRequest, actor string, version int) (*Order, error) {
+	return s.edit(ctx, orderID, actor, version, func(items []editItem) ([]editItem, OrderChange, error) {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return nil, OrderChange{}, fmt.Errorf("%w: product_id and a positive quantity are required", ErrInvalidEdit)
+		}
+		for i := range items {
+			if items[i].ProductID == item.ProductID {
+				change := OrderChange{Action: EditAddItem, ProductID: item.ProductID, FromQuantity: items[i].Quantity, ToQuantity: items[i].Quantity + item.Quantity}
+				if items[i].from != nil {
+					change.Line = items[i].from.Line
+				}
+				items[i].Quantity += item.Quantity
+				return items, change, nil
+			}
+		}
+		items = append(items, editItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		return items, OrderChange{Action: EditAddItem, ProductID: item.ProductID, ToQuantity: item.Quantity}, nil
+	})
+}
+
+// Cambiar la cantidad de una línea de una orden pendiente
+func (s *orderService) UpdateLineItem(ctx context.Context, orderID string, line, quantity int, actor string, version int) (*Order, error) {
+	return s.edit(ctx, orderID, actor, version, func(items []editItem) ([]editItem, OrderChange, error) {
+		if quantity <= 0 {
+			return nil, OrderChange{}, fmt.Errorf("%w: quantity must be positive (remove the line instead)", ErrInvalidEdit)
+		}
+		i, err := findEditLine(items, line)
+		if err != nil {
+			return nil, OrderChange{}, err
+		}
+		change := OrderChange{Action: EditChangeQuantity, Line: line, ProductID: items[i].ProductID, FromQuantity: items[i].Quantity, ToQuantity: quantity}
+		items[i].Quantity = quantity
+		return items, change, nil
+	})
+}
+
+// Quitar una línea de una orden pendiente
+func (s *orderService) RemoveLineItem(ctx context.Context, orderID string, line int, actor string, version int) (*Order, error) {
+	return s.edit(ctx, orderID, actor, version, func(items []editItem) ([]editItem, OrderChange, error) {
+		i, err := findEditLine(items, line)
+		if err != nil {
+			return nil, OrderChange{}, err
+		}
+		change := OrderChange{Action: EditRemoveItem, Line: line, ProductID: items[i].ProductID, FromQuantity: items[i].Quantity}
+		return append(items[:i], items[i+1:]...), change, nil
+	})
+}
+
+// Busca la línea editable con el número indicado (las líneas de regalo las maneja el motor de promociones)
+func findEditLine(items []editItem, line int) (int, error) {
+	for i, item := range items {
+		if item.from != nil && item.from.Line == line {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: line %d does not exist or is a promotional gift", ErrInvalidEdit, line)
+}
+
+// Aplica una edición: valida el estado, recalcula líneas y precios, ajusta el stock apartado, las bodegas
+// y el uso del cupón, y guarda la orden con el cambio en su historial. Si un paso falla se deshacen los anteriores.
+func (s *orderService) edit(ctx context.Context, orderID, actor string, version int,
+	apply func(items []editItem) ([]editItem, OrderChange, error)) (*Order, error) {
+	o, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Version != version {
+		return nil, ErrVersionConflict
+	}
+	if o.Status != StatusPending || o.PaidAmount > 0 || o.AuthorizedAmount > 0 {
+		return nil, ErrOrderNotEditable // Un pago autorizado cobraría el total anterior
+	}
+
+	// Líneas pedidas por el cliente (sin regalos) y stock que la orden ya tiene apartado
+	var items []editItem
+	reserved := make(map[string]int)
+	for i := range o.LineItems {
+		item := o.LineItems[i]
+		reserved[item.ProductID] += item.Quantity
+		if !item.Gift {
+			items = append(items, editItem{ProductID: item.ProductID, Quantity: item.Quantity, from: &item})
+		}
+	}
+	items, change, err := apply(items)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: an order needs at least one line (cancel it instead)", ErrInvalidEdit)
+	}
+
+	var undo []func() // Acciones para deshacer lo ya aplicado, en orden inverso
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	// El uso del cupón se libera para volver a evaluarlo con las líneas nuevas
+	if o.CouponCode != "" && s.coupons != nil {
+		s.coupons.ReleaseRedemption(ctx, o.CouponCode, o.UserID, o.ID)
+		undo = append(undo, func() {
+			s.coupons.Redeem(ctx, o.CouponCode, o.UserID, o.ID, couponLines(grossLines(o.LineItems)))
+		})
+	}
+
+	pricing, err := s.repriceEdit(ctx, o, items, reserved)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+
+	// Ajustar el inventario solo por la diferencia con lo ya apartado
+	if err := s.adjustReservation(ctx, o.ID, actor, reserved, pricing.Lines, &undo); err != nil {
+		rollback()
+		return nil, err
+	}
+	// Reasignar bodegas: se libera lo asignado antes y se asignan las líneas nuevas
+	if s.allocator != nil {
+		previous := append([]LineItem(nil), o.LineItems...)
+		for _, item := range previous {
+			if len(item.Allocations) > 0 {
+				s.allocator.Release(ctx, item.ProductID, item.Allocations)
+			}
+		}
+		undo = append(undo, func() { s.allocate(ctx, o.ID, previous, o.Destination) })
+		if err := s.allocate(ctx, o.ID, pricing.Lines, o.Destination); err != nil {
+			rollback()
+			return nil, err
+		}
+		undo = append(undo, func() {
+			for _, item := range pricing.Lines {
+				if len(item.Allocations) > 0 {
+					s.allocator.Release(ctx, item.ProductID, item.Allocations)
+				}
+			}
+		})
+	}
+	// Registrar el uso del cupón con el descuento recalculado
+	couponDiscount := 0.0
+	if pricing.Coupon != nil {
+		if _, err := s.coupons.Redeem(ctx, pricing.CouponCode, o.UserID, o.ID, pricing.couponLines); err != nil {
+			rollback()
+			return nil, err
+		}
+		undo = append(undo, func() { s.coupons.ReleaseRedemption(ctx, pricing.CouponCode, o.UserID, o.ID) })
+		couponDiscount = pricing.Coupon.Amount
+	}
+
+	totals := pricing.Totals()
+	now := time.Now()
+	change.PreviousTotal = o.GrandTotal
+	change.NewTotal = totals.GrandTotal
+	change.Actor = actor
+	change.At = now
+
+	o.LineItems = pricing.Lines
+	o.Promotions = pricing.Promotions
+	o.PromotionDiscount = pricing.promotionDiscount()
+	o.CouponDiscount = couponDiscount
+	o.Subtotal = totals.Subtotal
+	o.DiscountTotal = totals.DiscountTotal
+	o.TaxTotal = totals.TaxTotal
+	o.ShippingTotal = totals.ShippingTotal
+	o.ShippingZone = pricing.ShippingZone
+	o.GrandTotal = totals.GrandTotal
+	o.Total = totals.GrandTotal
+	o.PriceBreakdown = pricing.Adjustments
+	o.History = append(append([]OrderChange(nil), o.History...), change)
+	o.UpdatedAt = now
+	if err := s.repo.Update(ctx, *o); err != nil {
+		rollback() // Otra operación cambió la orden mientras se editaba
+		return nil, err
+	}
+	o.Version++ // Reflejar la versión asignada por el repositorio
+	return o, nil
+}
+
+// Construye las líneas editadas y ejecuta el pipeline de precios (promociones, cupón, impuestos y envío).
+// Las líneas que ya estaban conservan el precio acordado; las nuevas toman el precio actual del catálogo.
+func (s *orderService) repriceEdit(ctx context.Context, o *Order, items []editItem, reserved map[string]int) (*Pricing, error) {
+	needed := make(map[string]int)
+	lines := make([]LineItem, 0, len(items))
+	for i, item := range items {
+		prod, err := s.productService.GetProductByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, errors.New("product not found")
+		}
+		needed[item.ProductID] += item.Quantity
+		if prod.Stock+reserved[item.ProductID] < needed[item.ProductID] {
+			return nil, errors.New("insufficient stock") // Lo disponible más lo que la orden ya apartó
+		}
+		line := LineItem{
+			Line:      i + 1,
+			ProductID: item.ProductID,
+			Product:   snapshotOf(prod),
+			Quantity:  item.Quantity,
+			Price:     prod.Price,
+		}
+		if item.from != nil {
+			line.Product, line.Price = item.from.Product, item.from.Price
+		}
+		lines = append(lines, line)
+	}
+	pricing := &Pricing{
+		UserID:          o.UserID,
+		Destination:     o.Destination,
+		ShippingAddress: o.ShippingAddress,
+		BillingAddress:  o.BillingAddress,
+		CouponCode:      o.CouponCode,
+		Lines:           lines,
+		reserved:        reserved,
+	}
+	if err := s.price(ctx, pricing); err != nil {
+		return nil, err
+	}
+	return pricing, nil
+}
+
+// Descuenta o devuelve al inventario la diferencia entre lo apartado y lo que piden las líneas nuevas
+func (s *orderService) adjustReservation(ctx context.Context, orderID, actor string, reserved map[string]int, lines []LineItem, undo *[]func()) error {
+	wanted := make(map[string]int)
+	ids := make([]string, 0, len(reserved))
+	for id := range reserved {
+		ids = append(ids, id)
+	}
+	for _, item := range lines {
+		if _, ok := reserved[item.ProductID]; !ok && wanted[item.ProductID] == 0 {
+			ids = append(ids, item.ProductID)
+		}
+		wanted[item.ProductID] += item.Quantity
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		delta := reserved[id] - wanted[id] // Positivo: vuelve al inventario; negativo: se aparta
+		if delta == 0 {
+			continue
+		}
+		_, err := s.productService.AdjustStock(ctx, id, products.StockAdjustment{
+			Delta:       delta,
+			Reason:      products.ReasonOrderEdit,
+			Actor:       actor,
+			ReferenceID: orderID,
+		})
+		if errors.Is(err, products.ErrorStockInsuficiente) {
+			return errors.New("insufficient stock")
+		}
+		if err != nil {
+			return err
+		}
+		productID := id
+		*undo = append(*undo, func() {
+			s.productService.AdjustStock(ctx, productID, products.StockAdjustment{
+				Delta:       -delta,
+				Reason:      products.ReasonOrderEdit,
+				Actor:       actor,
+				ReferenceID: orderID,
+			})
+		})
+	}
+	return nil
+}
+
+// Copia de las líneas sin descuentos (precio de lista), para volver a registrar el uso del cupón original
+func grossLines(items []LineItem) []LineItem {
+	lines := append([]LineItem(nil), items...)
+	for i := range lines {
+		lines[i].Discount = 0
+	}
+	return lines
+}
@@ -0,0 +1,87 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo del motivo
+	"log"     // Registro de las cancelaciones
+	"time"    // Manejo de tiempos y fechas
+)
+
+// Cancelar las órdenes que siguen Pendientes sin ningún cobro y fueron creadas antes de createdBefore;
+// devuelve al inventario lo reservado y guarda el motivo y la fecha now (la del planificador) en cada orden
+func (s *orderService) CancelStalePending(ctx context.Context, createdBefore, now time.Time, reason string) ([]Order, error) {
+	filter := OrderFilter{
+		Statuses:  []OrderStatus{StatusPending},
+		CreatedTo: &createdBefore,
+		Limit:     MaxPageSize,
+	}
+	var stale []string
+	for {
+		page, _, err := s.repo.Search(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page {
+			if o.PaidAmount == 0 && o.AuthorizedAmount == 0 {
+				stale = append(stale, o.ID) // Una orden con un cobro parcial o un pago autorizado no se cancela sola
+			}
+		}
+		if len(page) < filter.Limit {
+			break
+		}
+		filter.Offset += len(page)
+	}
+
+	cancelled := []Order{}
+	for _, id := range stale {
+		o, err := s.expire(ctx, id, now, reason)
+		if err != nil {
+			return cancelled, fmt.Errorf("order %s: %w", id, err)
+		}
+		if o != nil {
+			cancelled = append(cancelled, *o)
+		}
+	}
+	return cancelled, nil
+}
+
+// Cancela una orden pendiente sin cobros; retorna nil si entretanto cambió de estado o recibió un pago o una autorización
+func (s *orderService) expire(ctx context.Context, orderID string, now time.Time, reason string) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if o.Status != StatusPending || o.PaidAmount > 0 || o.AuthorizedAmount > 0 {
+			return nil, nil
+		}
+		o.Status = StatusCancelled
+		o.CancelledAt = &now
+		o.CancelReason = reason
+		o.UpdatedAt = now
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y verificar
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++ // Reflejar la versión asignada por el repositorio
+		s.releaseOrder(ctx, o)
+		return o, nil
+	}
+}
+
+// Tarea periódica que cancela las órdenes pendientes sin pago con más de ttl de antigüedad (compatible con scheduler.JobFunc)
+func ExpirePendingJob(s Service, ttl time.Duration) func(ctx context.Context, now time.Time) error {
+	reason := fmt.Sprintf("Cancelada automáticamente: sin pago después de %s", ttl)
+	return func(ctx context.Context, now time.Time) error {
+		cancelled, err := s.CancelStalePending(ctx, now.Add(-ttl), now, reason)
+		for _, o := range cancelled {
+			log.Printf("Orden %s cancelada por falta de pago (creada %s)\n", o.ID, o.CreatedAt.Format(time.RFC3339))
+		}
+		return err
+	}
+}
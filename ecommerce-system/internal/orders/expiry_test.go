@@ -0,0 +1,106 @@
+// Pruebas de la cancelación automática de órdenes pendientes con el reloj falso del planificador
+package orders_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"testing" // Paquete de pruebas
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/scheduler"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// Dirección de entrega válida para las órdenes de prueba
+var testAddress = &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"}
+
+// Crea una orden pendiente de quantity unidades del producto
+func createOrder(t *testing.T, svc orders.Service, productID string, quantity int) *orders.Order {
+	t.Helper()
+	o, err := svc.CreateOrder(context.Background(), orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: productID, Quantity: quantity}},
+		ShippingAddress: testAddress,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	time.Sleep(time.Millisecond) // Los IDs de orden se basan en la hora
+	return o
+}
+
+// Espera a que la tarea termine su ejecución y vuelva a esperar al reloj
+func waitForJob(t *testing.T, clock *scheduler.FakeClock) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for clock.Waiters() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("la tarea programada no volvió a esperar")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExpirePendingJobCancelsStaleOrders(t *testing.T) {
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	svc := orders.NewService(orders.NewInMemoryRepository(), productService)
+
+	stale := createOrder(t, svc, prod.ID, 3)
+	partlyPaid := createOrder(t, svc, prod.ID, 2)
+	if _, err := svc.RecordPayment(ctx, partlyPaid.ID, 1); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+	authorized := createOrder(t, svc, prod.ID, 1)
+	if _, err := svc.RecordAuthorization(ctx, authorized.ID, authorized.GrandTotal); err != nil {
+		t.Fatalf("RecordAuthorization: %v", err)
+	}
+
+	clock := scheduler.NewFakeClock(time.Now())
+	jobs := scheduler.New(clock)
+	jobs.Every("cancelar órdenes pendientes", time.Minute, orders.ExpirePendingJob(svc, time.Hour))
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		jobs.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitForJob(t, clock)
+
+	// Antes de cumplirse el plazo no se cancela nada
+	clock.Advance(time.Minute)
+	waitForJob(t, clock)
+	if o, _ := svc.GetOrderByID(ctx, stale.ID); o.Status != orders.StatusPending {
+		t.Fatalf("estado antes del plazo = %s, se esperaba %s", o.Status, orders.StatusPending)
+	}
+
+	clock.Advance(time.Hour)
+	waitForJob(t, clock)
+	o, _ := svc.GetOrderByID(ctx, stale.ID)
+	if o.Status != orders.StatusCancelled {
+		t.Fatalf("estado = %s, se esperaba %s", o.Status, orders.StatusCancelled)
+	}
+	if o.CancelledAt == nil || !o.CancelledAt.Equal(clock.Now()) {
+		t.Fatalf("cancelled_at = %v, se esperaba la hora del planificador %v", o.CancelledAt, clock.Now())
+	}
+	if o.CancelReason == "" {
+		t.Fatal("la orden cancelada no guarda el motivo")
+	}
+	for _, id := range []string{partlyPaid.ID, authorized.ID} {
+		if o, _ := svc.GetOrderByID(ctx, id); o.Status != orders.StatusPending {
+			t.Fatalf("la orden %s con pago se canceló (estado %s)", id, o.Status)
+		}
+	}
+	if p, _ := productService.GetProductByID(ctx, prod.ID); p.Stock != 7 {
+		t.Fatalf("stock = %d, se esperaba 7 (se devuelven las 3 unidades de la orden vencida)", p.Stock)
+	}
+}
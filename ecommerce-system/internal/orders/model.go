@@ -1,19 +1,35 @@
 // Paquete para manejo de órdenes
 package orders
 
+import (
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Ubicaciones de bodegas
+)
+
 // Estructura para representar una solicitud de creación de una orden
 type OrderRequest struct {
-	UserID    string            `json:"user_id"`    // ID del usuario que realiza la orden
-	LineItems []LineItemRequest `json:"line_items"` // Lista de elementos que forman parte de la orden
+	UserID      string               `json:"user_id"`               // ID del usuario que realiza la orden
+	LineItems   []LineItemRequest    `json:"line_items"`            // Lista de elementos que forman parte de la orden
+	Destination *warehouses.Location `json:"destination,omitempty"` // Ubicación de entrega para elegir la bodega más cercana
+	CouponCode  string               `json:"coupon_code,omitempty"` // Código de descuento (opcional)
+	// Direcciones de entrega (obligatoria) y facturación (opcional)
+	ShippingAddress *shipping.Address `json:"shipping_address,omitempty"`
+	BillingAddress  *shipping.Address `json:"billing_address,omitempty"`
 }
 
 // Estructura para representar un elemento de línea en una solicitud de orden
 type LineItemRequest struct {
-	ProductID string `json:"product_id"` // ID del producto solicitado
-	Quantity  int    `json:"quantity"`   // Cantidad del producto solicitado
+	ProductID     string   `json:"product_id"`               // ID del producto solicitado
+	Quantity      int      `json:"quantity"`                 // Cantidad del producto solicitado
+	ExpectedPrice *float64 `json:"expected_price,omitempty"` // Precio que vio el cliente (opcional; la cotización avisa si cambió)
 }
 
 // Estructura para representar una solicitud de actualización del estado de una orden
 type UpdateOrderStatusRequest struct {
 	Status OrderStatus `json:"status"` // Nuevo estado de la orden
 }
+
+// Estructura para representar el cambio de cantidad de una línea de una orden pendiente
+type UpdateLineItemRequest struct {
+	Quantity int `json:"quantity"` // Nueva cantidad de la línea
+}
@@ -1,34 +1,188 @@
 // Paquete para manejo de órdenes
 package orders
 
-import "time" // Paquete para manejo de fechas y horas
+import (
+	"errors" // Paquete para manejo de errores
+	"time"   // Paquete para manejo de fechas y horas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Datos del producto comprado
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Asignación de bodegas
+)
 
 // OrderStatus representa los posibles estados de una orden
 type OrderStatus string
 
 // Constantes que definen los estados de una orden
 const (
-	StatusPending   OrderStatus = "Pendiente" // Orden pendiente
-	StatusProcessed OrderStatus = "Procesado" // Orden procesada
-	StatusShipped   OrderStatus = "Enviado"   // Orden enviada
-	StatusDelivered OrderStatus = "Entregado" // Orden entregada
-	StatusCancelled OrderStatus = "Cancelado" // Orden cancelada
+	StatusPending   OrderStatus = "Pendiente"            // Orden pendiente
+	StatusProcessed OrderStatus = "Procesado"            // Orden procesada
+	StatusShipped   OrderStatus = "Enviado"              // Orden enviada
+	StatusPartial   OrderStatus = "Enviado parcialmente" // Parte de las unidades ya salió en envíos
+	StatusDelivered OrderStatus = "Entregado"            // Orden entregada
+	StatusCancelled OrderStatus = "Cancelado"            // Orden cancelada
+	StatusRefunded  OrderStatus = "Reembolsado"          // Orden reembolsada por completo
 )
 
+// Método que valida si el estado es uno de los conocidos
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusProcessed, StatusShipped, StatusPartial, StatusDelivered, StatusCancelled, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+// ProductSnapshot guarda una copia inmutable de los datos del producto al momento de la compra
+type ProductSnapshot struct {
+	Name     string `json:"name"`     // Nombre del producto al crear la orden
+	SKU      string `json:"sku"`      // SKU del producto al crear la orden
+	Category string `json:"category"` // Categoría del producto al crear la orden
+	// Peso y medidas unitarias al crear la orden (base del costo de envío)
+	WeightKg   float64              `json:"weight_kg,omitempty"`
+	Dimensions *products.Dimensions `json:"dimensions,omitempty"`
+}
+
+// Función que copia los datos del producto que la orden conserva
+func snapshotOf(prod *products.Product) ProductSnapshot {
+	snap := ProductSnapshot{Name: prod.Name, SKU: prod.SKU, Category: prod.Category, WeightKg: prod.WeightKg}
+	if prod.Dimensions != (products.Dimensions{}) {
+		dims := prod.Dimensions
+		snap.Dimensions = &dims
+	}
+	return snap
+}
+
 // LineItem representa un elemento dentro de una orden
 type LineItem struct {
-	ProductID string  `json:"product_id"` // ID del producto asociado al elemento
-	Quantity  int     `json:"quantity"`   // Cantidad del producto
-	Price     float64 `json:"price"`      // Precio unitario del producto
+	Line              int             `json:"line"`                         // Número de línea dentro de la orden (1 = primera)
+	ProductID         string          `json:"product_id"`                   // ID del producto asociado al elemento
+	Product           ProductSnapshot `json:"product"`                      // Copia de los datos del producto (no cambia si el producto se edita o elimina)
+	Quantity          int             `json:"quantity"`                     // Cantidad del producto
+	Price             float64         `json:"price"`                        // Precio unitario del producto
+	Subtotal          float64         `json:"subtotal"`                     // Precio × cantidad
+	Discount          float64         `json:"discount"`                     // Descuentos de promociones y cupón sobre la línea
+	Tax               float64         `json:"tax"`                          // Impuesto de la línea (sobre el monto con descuento)
+	Total             float64         `json:"total"`                        // Subtotal - descuento + impuesto
+	Gift              bool            `json:"gift,omitempty"`               // Línea agregada como regalo por una promoción
+	RefundedQuantity  int             `json:"refunded_quantity,omitempty"`  // Unidades reembolsadas
+	ShippedQuantity   int             `json:"shipped_quantity,omitempty"`   // Unidades despachadas en envíos
+	DeliveredQuantity int             `json:"delivered_quantity,omitempty"` // Unidades entregadas
+	// Bodegas que despachan la línea (vacío si el producto no se gestiona por bodega)
+	Allocations []warehouses.Allocation `json:"allocations,omitempty"`
 }
 
 // Order representa una orden completa
 type Order struct {
-	ID        string      `json:"id"`         // ID único de la orden
-	UserID    string      `json:"user_id"`    // ID del usuario que realizó la orden
-	LineItems []LineItem  `json:"line_items"` // Lista de elementos incluidos en la orden
-	Total     float64     `json:"total"`      // Total calculado de la orden
-	Status    OrderStatus `json:"status"`     // Estado actual de la orden
-	CreatedAt time.Time   `json:"created_at"` // Fecha y hora de creación de la orden
-	UpdatedAt time.Time   `json:"updated_at"` // Fecha y hora de la última actualización de la orden
+	ID                string               `json:"id"`                          // ID único de la orden
+	UserID            string               `json:"user_id"`                     // ID del usuario que realizó la orden
+	LineItems         []LineItem           `json:"line_items"`                  // Lista de elementos incluidos en la orden
+	Promotions        []promotions.Applied `json:"promotions,omitempty"`        // Promociones automáticas aplicadas (en orden de evaluación)
+	PromotionDiscount float64              `json:"promotion_discount"`          // Monto descontado por promociones
+	ShippingAddress   *shipping.Address    `json:"shipping_address,omitempty"`  // Dirección de entrega
+	BillingAddress    *shipping.Address    `json:"billing_address,omitempty"`   // Dirección de facturación (igual a la de entrega si no se indica)
+	CouponCode        string               `json:"coupon_code,omitempty"`       // Código de cupón aplicado
+	CouponDiscount    float64              `json:"coupon_discount"`             // Monto descontado por el cupón
+	Subtotal          float64              `json:"subtotal"`                    // Suma de precio × cantidad
+	DiscountTotal     float64              `json:"discount_total"`              // Suma de descuentos
+	TaxTotal          float64              `json:"tax_total"`                   // Suma de impuestos
+	ShippingTotal     float64              `json:"shipping_total"`              // Costo de envío
+	ShippingZone      string               `json:"shipping_zone,omitempty"`     // Zona de envío aplicada
+	Destination       *warehouses.Location `json:"destination,omitempty"`       // Ubicación de entrega usada para asignar bodegas
+	GrandTotal        float64              `json:"grand_total"`                 // Total a pagar
+	Total             float64              `json:"total"`                       // Igual a GrandTotal (se conserva por compatibilidad)
+	PriceBreakdown    []Adjustment         `json:"price_breakdown"`             // Ajustes aplicados por el pipeline de precios
+	PaidAmount        float64              `json:"paid_amount"`                 // Monto cobrado (pagos capturados)
+	AuthorizedAmount  float64              `json:"authorized_amount,omitempty"` // Monto autorizado pendiente de cobro
+	PaidAt            *time.Time           `json:"paid_at,omitempty"`           // Fecha en que se completó el pago
+	RefundedAmount    float64              `json:"refunded_amount"`             // Monto reembolsado
+	DeliveredAt       *time.Time           `json:"delivered_at,omitempty"`      // Fecha de entrega (inicia el plazo de devolución)
+	CancelledAt       *time.Time           `json:"cancelled_at,omitempty"`      // Fecha de cancelación
+	CancelReason      string               `json:"cancel_reason,omitempty"`     // Motivo de la cancelación
+	History           []OrderChange        `json:"history,omitempty"`           // Ediciones hechas mientras estaba Pendiente
+	Status            OrderStatus          `json:"status"`                      // Estado actual de la orden
+	Version           int                  `json:"version"`                     // Versión para control de concurrencia optimista
+	CreatedAt         time.Time            `json:"created_at"`                  // Fecha y hora de creación de la orden
+	UpdatedAt         time.Time            `json:"updated_at"`                  // Fecha y hora de la última actualización de la orden
 }
+
+// Error que indica que la orden no existe
+var ErrOrderNotFound = errors.New("order not found")
+
+// Error que indica que la orden pertenece a otro usuario
+var ErrNotOrderOwner = errors.New("order belongs to another user")
+
+// Error que indica que la orden no puede avanzar de Pendiente sin un pago capturado
+var ErrPaymentRequired = errors.New("order must be paid before it can be processed")
+
+// Error que indica que la orden no puede pasar al estado pedido (estado final, cancelación con dinero
+// cobrado o autorizado, Reembolsado sin reembolso...)
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// Error que indica que el estado pedido no es uno de los conocidos
+var ErrInvalidStatus = errors.New("unknown order status")
+
+// Error que indica que la orden no admite pagos en su estado actual
+var ErrNotPayable = errors.New("order cannot receive payments in its current status")
+
+// Método que indica si la orden está en un estado final: su stock ya se liberó o se reembolsó
+func (o *Order) IsFinal() bool {
+	return o.Status == StatusCancelled || o.Status == StatusRefunded
+}
+
+// Método que retorna el monto cobrado que aún se puede reembolsar
+func (o *Order) Refundable() float64 {
+	return roundCents(o.PaidAmount - o.RefundedAmount)
+}
+
+// Método que busca una línea por su número
+func (o *Order) LineByNumber(line int) (*LineItem, bool) {
+	for i := range o.LineItems {
+		if o.LineItems[i].Line == line {
+			return &o.LineItems[i], true
+		}
+	}
+	return nil, false
+}
+
+// Método que indica si la orden ya se cobró por completo
+func (o *Order) IsPaid() bool {
+	return o.PaidAmount >= o.GrandTotal
+}
+
+// Método que deriva el estado de la orden a partir de las unidades despachadas y entregadas;
+// retorna false si los envíos no cambian el estado (orden sin despachar, cancelada o reembolsada)
+func (o *Order) fulfillmentStatus() (OrderStatus, bool) {
+	switch o.Status {
+	case StatusProcessed, StatusPartial, StatusShipped, StatusDelivered:
+	default:
+		return "", false
+	}
+	shipped, delivered, started := true, true, false
+	for _, item := range o.LineItems {
+		outstanding := item.Quantity - item.RefundedQuantity // Las unidades reembolsadas no se envían
+		if item.ShippedQuantity > 0 {
+			started = true
+		}
+		if item.ShippedQuantity < outstanding {
+			shipped = false
+		}
+		if item.DeliveredQuantity < outstanding {
+			delivered = false
+		}
+	}
+	switch {
+	case !started:
+		return "", false
+	case delivered:
+		return StatusDelivered, true
+	case shipped:
+		return StatusShipped, true
+	default:
+		return StatusPartial, true
+	}
+}
+
+// Error que indica que la orden fue modificada por otra operación (versión desactualizada)
+var ErrVersionConflict = errors.New("order version conflict")
@@ -0,0 +1,221 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de descripciones
+	"math"    // Redondeo de montos
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/coupons"    // Motor de cupones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions" // Motor de promociones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Tarifas de envío por zona
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Ubicación de entrega
+)
+
+// AdjustmentKind clasifica los ajustes del desglose de precios
+type AdjustmentKind string
+
+// Constantes que definen los tipos de ajuste
+const (
+	AdjustmentDiscount AdjustmentKind = "discount" // Resta del total
+	AdjustmentTax      AdjustmentKind = "tax"      // Suma al total
+	AdjustmentShipping AdjustmentKind = "shipping" // Suma al total
+)
+
+// Adjustment es una entrada auditable del desglose: qué paso la produjo, sobre qué línea y por cuánto
+type Adjustment struct {
+	Step        string         `json:"step"`           // Paso del pipeline que la registró
+	Kind        AdjustmentKind `json:"kind"`           // discount, tax o shipping
+	Description string         `json:"description"`    // Detalle legible (regla, código, tasa)
+	Line        int            `json:"line,omitempty"` // Línea afectada (1 = primera; vacío = toda la orden)
+	Amount      float64        `json:"amount"`         // Monto (siempre positivo)
+}
+
+// Totals es el resumen de montos de una orden
+type Totals struct {
+	Subtotal      float64 `json:"subtotal"`       // Suma de precio × cantidad
+	DiscountTotal float64 `json:"discount_total"` // Suma de descuentos
+	TaxTotal      float64 `json:"tax_total"`      // Suma de impuestos
+	ShippingTotal float64 `json:"shipping_total"` // Costo de envío
+	GrandTotal    float64 `json:"grand_total"`    // Subtotal - descuentos + impuestos + envío
+}
+
+// Pricing es el estado que recorre el pipeline de precios; cada paso lo modifica y deja su registro
+type Pricing struct {
+	UserID          string               // Usuario que compra
+	Destination     *warehouses.Location // Ubicación de entrega (opcional)
+	ShippingAddress *shipping.Address    // Dirección de entrega validada
+	BillingAddress  *shipping.Address    // Dirección de facturación validada (opcional)
+	CouponCode      string               // Código de cupón solicitado
+	Lines           []LineItem           // Líneas de la orden (los pasos pueden agregar regalos)
+	Promotions      []promotions.Applied // Promociones aplicadas
+	Coupon          *coupons.Application // Cupón aplicado
+	Shipping        float64              // Costo de envío
+	ShippingZone    string               // Zona de envío aplicada (tarifas por zona)
+	Adjustments     []Adjustment         // Registro de ajustes en el orden en que se aplicaron
+
+	couponLines []coupons.Line // Líneas con las que se calculó el cupón (se reutilizan al registrar el uso)
+	reserved    map[string]int // Stock ya apartado por la orden que se edita, por producto
+}
+
+// PricingStep es un paso del pipeline de precios
+type PricingStep interface {
+	Name() string                                // Nombre que aparece en el desglose
+	Apply(ctx context.Context, p *Pricing) error // Modifica el estado; un error detiene la orden
+}
+
+// Opción que agrega pasos al final del pipeline (después de promociones y cupones)
+func WithPricingSteps(steps ...PricingStep) Option {
+	return func(s *orderService) { s.pricingSteps = append(s.pricingSteps, steps...) }
+}
+
+// Método que registra un descuento sobre una línea (i empieza en 0)
+func (p *Pricing) AddLineDiscount(step, description string, i int, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	p.Lines[i].Discount = roundCents(p.Lines[i].Discount + amount)
+	p.Adjustments = append(p.Adjustments, Adjustment{Step: step, Kind: AdjustmentDiscount, Description: description, Line: i + 1, Amount: amount})
+}
+
+// Método que registra un impuesto sobre una línea (i empieza en 0)
+func (p *Pricing) AddLineTax(step, description string, i int, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	p.Lines[i].Tax = roundCents(p.Lines[i].Tax + amount)
+	p.Adjustments = append(p.Adjustments, Adjustment{Step: step, Kind: AdjustmentTax, Description: description, Line: i + 1, Amount: amount})
+}
+
+// Método que registra el costo de envío de la orden
+func (p *Pricing) AddShipping(step, description string, amount float64) {
+	p.Shipping = roundCents(p.Shipping + amount)
+	p.Adjustments = append(p.Adjustments, Adjustment{Step: step, Kind: AdjustmentShipping, Description: description, Amount: amount})
+}
+
+// Método que calcula el monto neto de una línea (precio × cantidad menos descuentos)
+func (p *Pricing) NetAmount(i int) float64 {
+	l := p.Lines[i]
+	return l.Price*float64(l.Quantity) - l.Discount
+}
+
+// Método que calcula los totales de línea y de la orden
+func (p *Pricing) Totals() Totals {
+	var t Totals
+	for i := range p.Lines {
+		l := &p.Lines[i]
+		l.Subtotal = roundCents(l.Price * float64(l.Quantity))
+		l.Total = roundCents(l.Subtotal - l.Discount + l.Tax)
+		t.Subtotal += l.Subtotal
+		t.DiscountTotal += l.Discount
+		t.TaxTotal += l.Tax
+	}
+	t.ShippingTotal = p.Shipping
+	t.Subtotal, t.DiscountTotal, t.TaxTotal = roundCents(t.Subtotal), roundCents(t.DiscountTotal), roundCents(t.TaxTotal)
+	t.GrandTotal = roundCents(t.Subtotal - t.DiscountTotal + t.TaxTotal + t.ShippingTotal)
+	return t
+}
+
+// Método que suma el descuento de las promociones aplicadas
+func (p *Pricing) promotionDiscount() float64 {
+	var total float64
+	for _, a := range p.Promotions {
+		total += a.Amount
+	}
+	return roundCents(total)
+}
+
+// Ejecuta el pipeline: promociones, cupón y luego los pasos configurados (impuestos, envío...)
+func (s *orderService) price(ctx context.Context, p *Pricing) error {
+	steps := append([]PricingStep{promotionStep{s}, couponStep{s}}, s.pricingSteps...)
+	for _, step := range steps {
+		if err := step.Apply(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TaxStep aplica impuestos por línea sobre el monto neto de descuentos
+type TaxStep struct {
+	DefaultRate   float64            // Tasa general (ej. 0.15)
+	CategoryRates map[string]float64 // Tasas por categoría que reemplazan la general
+}
+
+// Nombre del paso de impuestos
+func (t TaxStep) Name() string { return "tax" }
+
+// Calcula el impuesto de cada línea
+func (t TaxStep) Apply(ctx context.Context, p *Pricing) error {
+	for i, l := range p.Lines {
+		rate, ok := t.CategoryRates[l.Product.Category]
+		if !ok {
+			rate = t.DefaultRate
+		}
+		p.AddLineTax(t.Name(), fmt.Sprintf("%.2f%%", rate*100), i, roundCents(p.NetAmount(i)*rate))
+	}
+	return nil
+}
+
+// ShippingStep cobra una tarifa fija de envío, gratis desde cierto monto
+type ShippingStep struct {
+	FlatRate  float64 // Tarifa por orden
+	FreeAbove float64 // Monto neto desde el que el envío es gratis (0 = nunca)
+}
+
+// Nombre del paso de envío
+func (sh ShippingStep) Name() string { return "shipping" }
+
+// Calcula el costo de envío de la orden
+func (sh ShippingStep) Apply(ctx context.Context, p *Pricing) error {
+	if sh.FlatRate <= 0 {
+		return nil // Envío sin costo configurado
+	}
+	var net float64
+	for i := range p.Lines {
+		net += p.NetAmount(i)
+	}
+	if sh.FreeAbove > 0 && net >= sh.FreeAbove {
+		p.AddShipping(sh.Name(), fmt.Sprintf("free above %.2f", sh.FreeAbove), 0)
+		return nil
+	}
+	p.AddShipping(sh.Name(), "flat rate", sh.FlatRate)
+	return nil
+}
+
+// ZoneShippingStep cobra el envío según la zona del país de entrega y el peso facturable de la orden
+type ZoneShippingStep struct {
+	Rates *shipping.RateTable // Zonas y tablas de tarifas
+}
+
+// Nombre del paso de envío por zonas
+func (z ZoneShippingStep) Name() string { return "shipping" }
+
+// Calcula el costo de envío con la tabla de la zona; exige dirección de entrega
+func (z ZoneShippingStep) Apply(ctx context.Context, p *Pricing) error {
+	if p.ShippingAddress == nil {
+		return shipping.ErrAddressRequired
+	}
+	var net float64
+	parcels := make([]shipping.Parcel, 0, len(p.Lines))
+	for i, l := range p.Lines {
+		net += p.NetAmount(i)
+		parcel := shipping.Parcel{WeightKg: l.Product.WeightKg, Quantity: l.Quantity}
+		if d := l.Product.Dimensions; d != nil {
+			parcel.LengthCm, parcel.WidthCm, parcel.HeightCm = d.LengthCm, d.WidthCm, d.HeightCm
+		}
+		parcels = append(parcels, parcel)
+	}
+	q, err := z.Rates.Quote(p.ShippingAddress.Country, parcels, net)
+	if err != nil {
+		return err
+	}
+	p.ShippingZone = q.Zone
+	p.AddShipping(z.Name(), q.Description, q.Amount)
+	return nil
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
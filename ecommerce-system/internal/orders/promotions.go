@@ -0,0 +1,78 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions" // Motor de promociones
+)
+
+// PromotionEngine calcula las promociones automáticas que aplican a una orden
+type PromotionEngine interface {
+	Evaluate(ctx context.Context, lines []promotions.Line) ([]promotions.Applied, error)
+}
+
+// Opción que habilita las promociones automáticas al crear órdenes
+func WithPromotions(engine PromotionEngine) Option {
+	return func(s *orderService) { s.promotions = engine }
+}
+
+// promotionStep aplica las promociones automáticas: registra el descuento de cada línea y agrega los regalos con stock
+type promotionStep struct{ s *orderService }
+
+// Nombre del paso de promociones
+func (st promotionStep) Name() string { return "promotions" }
+
+// Evalúa las reglas vigentes sobre las líneas
+func (st promotionStep) Apply(ctx context.Context, p *Pricing) error {
+	if st.s.promotions == nil {
+		return nil
+	}
+	lines := make([]promotions.Line, len(p.Lines))
+	for i, item := range p.Lines {
+		lines[i] = promotions.Line{ProductID: item.ProductID, Category: item.Product.Category, Quantity: item.Quantity, UnitPrice: item.Price}
+	}
+	applied, err := st.s.promotions.Evaluate(ctx, lines)
+	if err != nil {
+		return err
+	}
+	for _, a := range applied {
+		if a.GiftProductID != "" {
+			gift, ok := st.s.giftLine(ctx, p.Lines, p.reserved, a.GiftProductID, a.GiftQuantity)
+			if !ok {
+				continue // Sin stock para el regalo: la promoción no se registra
+			}
+			gift.Line = len(p.Lines) + 1
+			p.Lines = append(p.Lines, gift)
+		}
+		for i, d := range a.LineDiscounts {
+			p.AddLineDiscount(st.Name(), a.Name, i, d)
+		}
+		p.Promotions = append(p.Promotions, a)
+	}
+	return nil
+}
+
+// Construye la línea de regalo si el producto tiene stock además de lo ya pedido (reserved: lo que la orden ya apartó)
+func (s *orderService) giftLine(ctx context.Context, items []LineItem, reserved map[string]int, productID string, quantity int) (LineItem, bool) {
+	prod, err := s.productService.GetProductByID(ctx, productID)
+	if err != nil {
+		return LineItem{}, false
+	}
+	needed := quantity
+	for _, item := range items {
+		if item.ProductID == productID {
+			needed += item.Quantity
+		}
+	}
+	if prod.Stock+reserved[productID] < needed {
+		return LineItem{}, false
+	}
+	return LineItem{
+		ProductID: productID,
+		Product:   snapshotOf(prod),
+		Quantity:  quantity,
+		Price:     0,
+		Gift:      true,
+	}, true
+}
@@ -0,0 +1,144 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de avisos
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions" // Promociones aplicadas
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+)
+
+// Códigos de aviso por línea en una cotización
+const (
+	WarningLowStock     = "low_stock"     // El pedido deja el producto en o bajo su umbral de reposición
+	WarningPriceChanged = "price_changed" // El precio actual difiere del que vio el cliente
+)
+
+// LineWarning es un aviso sobre una línea que no impide comprar
+type LineWarning struct {
+	Line      int    `json:"line"`       // Línea afectada (1 = primera)
+	ProductID string `json:"product_id"` // Producto de la línea
+	Code      string `json:"code"`       // low_stock o price_changed
+	Message   string `json:"message"`    // Detalle legible
+}
+
+// Quote es el precio de una orden calculado sin crearla
+type Quote struct {
+	UserID            string               `json:"user_id"`               // Usuario que cotiza
+	LineItems         []LineItem           `json:"line_items"`            // Líneas con su desglose (incluye regalos)
+	Promotions        []promotions.Applied `json:"promotions,omitempty"`  // Promociones que se aplicarían
+	PromotionDiscount float64              `json:"promotion_discount"`    // Monto descontado por promociones
+	CouponCode        string               `json:"coupon_code,omitempty"` // Código de cupón aplicado
+	CouponDiscount    float64              `json:"coupon_discount"`       // Monto descontado por el cupón
+	Totals                                 // Subtotal, descuentos, impuestos, envío y total
+	ShippingZone      string               `json:"shipping_zone,omitempty"` // Zona de envío aplicada
+	PriceBreakdown    []Adjustment         `json:"price_breakdown"`         // Ajustes aplicados por el pipeline de precios
+	Warnings          []LineWarning        `json:"warnings,omitempty"`      // Avisos por línea
+}
+
+// Cotizar una orden: valida y calcula precios igual que CreateOrder, sin apartar stock ni guardar nada.
+// Con bodegas también simula la asignación, de modo que falla igual que CreateOrder si no alcanzan.
+func (s *orderService) QuoteOrder(ctx context.Context, req OrderRequest) (*Quote, error) {
+	pricing, warnings, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.planAllocation(ctx, pricing.Lines, req.Destination); err != nil {
+		return nil, err
+	}
+	q := &Quote{
+		UserID:         req.UserID,
+		Promotions:     pricing.Promotions,
+		Totals:         pricing.Totals(),
+		PriceBreakdown: pricing.Adjustments,
+		ShippingZone:   pricing.ShippingZone,
+		Warnings:       warnings,
+	}
+	q.LineItems = pricing.Lines
+	q.PromotionDiscount = pricing.promotionDiscount()
+	if pricing.Coupon != nil {
+		q.CouponCode, q.CouponDiscount = pricing.Coupon.Code, pricing.Coupon.Amount
+	}
+	return q, nil
+}
+
+// Valida la solicitud, construye las líneas con los datos actuales de cada producto y ejecuta el pipeline de precios.
+// Retorna también los avisos por línea (stock bajo, precio distinto al esperado).
+func (s *orderService) prepare(ctx context.Context, req OrderRequest) (*Pricing, []LineWarning, error) {
+	if req.UserID == "" || len(req.LineItems) == 0 {
+		return nil, nil, errors.New("invalid order data") // Validación básica de entrada
+	}
+
+	var lines []LineItem
+	var warnings []LineWarning
+	needed := make(map[string]int) // Unidades pedidas por producto (puede repetirse en varias líneas)
+	for i, itemReq := range req.LineItems {
+		// Obtener producto para validar existencia y stock
+		prod, err := s.productService.GetProductByID(ctx, itemReq.ProductID)
+		if err != nil {
+			return nil, nil, errors.New("product not found")
+		}
+		needed[itemReq.ProductID] += itemReq.Quantity
+		if itemReq.Quantity <= 0 || prod.Stock < needed[itemReq.ProductID] {
+			return nil, nil, errors.New("insufficient stock") // Validar stock suficiente para todas las líneas del producto
+		}
+		// Construir LineItem para la orden
+		lines = append(lines, LineItem{
+			Line:      i + 1,
+			ProductID: itemReq.ProductID,
+			Product:   snapshotOf(prod),
+			Quantity:  itemReq.Quantity,
+			Price:     prod.Price,
+		})
+
+		if itemReq.ExpectedPrice != nil && *itemReq.ExpectedPrice != prod.Price {
+			warnings = append(warnings, LineWarning{Line: i + 1, ProductID: prod.ID, Code: WarningPriceChanged,
+				Message: fmt.Sprintf("price changed from %.2f to %.2f", *itemReq.ExpectedPrice, prod.Price)})
+		}
+		if left := prod.Stock - needed[itemReq.ProductID]; prod.ReorderThreshold > 0 && left <= prod.ReorderThreshold {
+			warnings = append(warnings, LineWarning{Line: i + 1, ProductID: prod.ID, Code: WarningLowStock,
+				Message: fmt.Sprintf("only %d units left after this order", left)})
+		}
+	}
+
+	// Validar las direcciones (la de entrega es obligatoria); la de facturación es la de entrega si no se indica
+	shippingAddress, billingAddress, err := normalizeAddresses(req.ShippingAddress, req.BillingAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Calcular descuentos, impuestos y envío antes de tocar el inventario
+	pricing := &Pricing{
+		UserID:          req.UserID,
+		Destination:     req.Destination,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+		CouponCode:      req.CouponCode,
+		Lines:           lines,
+	}
+	if err := s.price(ctx, pricing); err != nil {
+		return nil, nil, err
+	}
+	return pricing, warnings, nil
+}
+
+// Normaliza y valida las direcciones; exige la de entrega y la de facturación toma la de entrega si no se indica
+func normalizeAddresses(ship, bill *shipping.Address) (*shipping.Address, *shipping.Address, error) {
+	if ship == nil {
+		return nil, nil, shipping.ErrAddressRequired // Sin dirección la orden no se podría enviar después de pagarla
+	}
+	shippingAddress := ship.Normalized()
+	if err := shippingAddress.Validate(); err != nil {
+		return nil, nil, err
+	}
+	billingAddress := shippingAddress
+	if bill != nil {
+		billingAddress = bill.Normalized()
+		if err := billingAddress.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("billing address: %w", err)
+		}
+	}
+	return &shippingAddress, &billingAddress, nil
+}
@@ -0,0 +1,75 @@
+// Pruebas de la validación de stock al cotizar y crear órdenes
+package orders_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses"
+)
+
+func TestQuoteSumsQuantitiesOfRepeatedProduct(t *testing.T) {
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 5})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	svc := orders.NewService(orders.NewInMemoryRepository(), productService)
+
+	// Cada línea por separado cabe en el stock, pero juntas lo superan
+	req := orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 3}, {ProductID: prod.ID, Quantity: 3}},
+		ShippingAddress: testAddress,
+	}
+	if _, err := svc.QuoteOrder(ctx, req); err == nil {
+		t.Fatal("la cotización aceptó 6 unidades con stock 5")
+	}
+	if _, err := svc.CreateOrder(ctx, req); err == nil {
+		t.Fatal("la orden aceptó 6 unidades con stock 5")
+	}
+
+	req.LineItems[1].Quantity = 2
+	if _, err := svc.QuoteOrder(ctx, req); err != nil {
+		t.Fatalf("QuoteOrder con 5 unidades: %v", err)
+	}
+}
+
+func TestQuotePlansWarehouseAllocationWithoutReserving(t *testing.T) {
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 4})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	warehouseService := warehouses.NewService(warehouses.NewInMemoryRepository(), productService, warehouses.PriorityStrategy{})
+	wh, err := warehouseService.CreateWarehouse(ctx, warehouses.WarehouseRequest{Code: "UIO", Name: "Quito"})
+	if err != nil {
+		t.Fatalf("CreateWarehouse: %v", err)
+	}
+	adj := warehouses.StockAdjustmentRequest{ProductID: prod.ID, StockAdjustment: products.StockAdjustment{Delta: 1, Reason: products.ReasonRestock}}
+	if _, err := warehouseService.AdjustStock(ctx, wh.ID, adj); err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	svc := orders.NewService(orders.NewInMemoryRepository(), productService, orders.WithStockAllocator(warehouseService))
+
+	req := orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 3}},
+		ShippingAddress: testAddress,
+	}
+	q, err := svc.QuoteOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("QuoteOrder: %v", err)
+	}
+	if got := q.LineItems[0].Allocations; len(got) != 1 || got[0] != (warehouses.Allocation{WarehouseID: wh.ID, Quantity: 3}) {
+		t.Fatalf("asignaciones cotizadas = %v, se esperaba 3 unidades de %s", got, wh.ID)
+	}
+	levels, _ := warehouseService.ListStockByWarehouse(ctx, wh.ID)
+	if len(levels) != 1 || levels[0].Quantity != 5 {
+		t.Fatalf("stock en bodega tras cotizar = %v, se esperaba 5 sin apartar", levels)
+	}
+}
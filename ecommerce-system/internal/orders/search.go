@@ -0,0 +1,205 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de errores
+	"sort"    // Ordenamiento de resultados
+	"time"    // Rangos de fechas
+)
+
+// SortField es el campo por el que se ordena una búsqueda
+type SortField string
+
+// Constantes que definen los campos de ordenamiento
+const (
+	SortByCreated SortField = "created_at" // Fecha de creación
+	SortByUpdated SortField = "updated_at" // Fecha de última actualización
+)
+
+// Tamaños de página de la búsqueda
+const (
+	DefaultPageSize = 50  // Órdenes por página si no se indica
+	MaxPageSize     = 200 // Máximo de órdenes por página
+)
+
+// OrderFilter son los criterios de búsqueda de órdenes; los campos vacíos no filtran
+type OrderFilter struct {
+	UserID      string        // Órdenes de un usuario
+	Statuses    []OrderStatus // Órdenes en cualquiera de estos estados
+	ProductID   string        // Órdenes que contienen el producto
+	CreatedFrom *time.Time    // Creadas desde (incluido)
+	CreatedTo   *time.Time    // Creadas antes de (excluido)
+	MinTotal    *float64      // Total mínimo (incluido)
+	MaxTotal    *float64      // Total máximo (incluido)
+	SortBy      SortField     // Campo de ordenamiento (por defecto created_at)
+	Descending  bool          // Orden descendente
+	Limit       int           // Tamaño de página (0 = DefaultPageSize)
+	Offset      int           // Órdenes a saltar
+}
+
+// OrderPage es una página de resultados con el total de coincidencias
+type OrderPage struct {
+	Orders []Order `json:"orders"` // Órdenes de la página
+	Total  int     `json:"total"`  // Total de órdenes que cumplen el filtro
+	Limit  int     `json:"limit"`  // Tamaño de página aplicado
+	Offset int     `json:"offset"` // Desplazamiento aplicado
+}
+
+// Error que indica que los criterios de búsqueda no son válidos
+var ErrInvalidFilter = errors.New("invalid order filter")
+
+// Método que indica si una orden cumple los criterios que no resuelven los índices
+func (f *OrderFilter) matches(o *Order) bool {
+	if f.UserID != "" && o.UserID != f.UserID {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, st := range f.Statuses {
+			found = found || o.Status == st
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.CreatedFrom != nil && o.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !o.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	if f.MinTotal != nil && o.GrandTotal < *f.MinTotal {
+		return false
+	}
+	if f.MaxTotal != nil && o.GrandTotal > *f.MaxTotal {
+		return false
+	}
+	if f.ProductID != "" {
+		for _, item := range o.LineItems {
+			if item.ProductID == f.ProductID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Busca órdenes usando los índices por usuario, estado y producto para no recorrer todo el repositorio
+func (r *inMemoryRepository) Search(ctx context.Context, f OrderFilter) ([]Order, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := []Order{}
+	for id := range r.candidates(f) {
+		o := r.data[id]
+		if f.matches(&o) {
+			matches = append(matches, o)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i].CreatedAt, matches[j].CreatedAt
+		if f.SortBy == SortByUpdated {
+			a, b = matches[i].UpdatedAt, matches[j].UpdatedAt
+		}
+		if a.Equal(b) {
+			return (matches[i].ID < matches[j].ID) != f.Descending // Desempate estable por ID
+		}
+		return a.Before(b) != f.Descending
+	})
+
+	total := len(matches)
+	if f.Offset >= total {
+		return []Order{}, total, nil
+	}
+	end := f.Offset + f.Limit
+	if end > total {
+		end = total
+	}
+	return matches[f.Offset:end], total, nil
+}
+
+// Elige el conjunto de IDs más pequeño entre los índices que aplican al filtro (requiere r.mu tomado)
+func (r *inMemoryRepository) candidates(f OrderFilter) map[string]bool {
+	var best map[string]bool
+	consider := func(set map[string]bool) {
+		if best == nil || len(set) < len(best) {
+			best = set
+		}
+	}
+	if f.UserID != "" {
+		consider(r.byUser[f.UserID])
+	}
+	if f.ProductID != "" {
+		consider(r.byProduct[f.ProductID])
+	}
+	if len(f.Statuses) > 0 {
+		union := make(map[string]bool)
+		for _, st := range f.Statuses {
+			for id := range r.byStatus[string(st)] {
+				union[id] = true
+			}
+		}
+		consider(union)
+	}
+	if best == nil {
+		best = make(map[string]bool, len(r.data)) // Sin criterios indexados se recorren todas
+		for id := range r.data {
+			best[id] = true
+		}
+	}
+	return best
+}
+
+// Agrega la orden a los índices (requiere r.mu tomado para escritura)
+func (r *inMemoryRepository) index(o Order) {
+	addTo(r.byUser, o.UserID, o.ID)
+	addTo(r.byStatus, string(o.Status), o.ID)
+	for _, item := range o.LineItems {
+		addTo(r.byProduct, item.ProductID, o.ID)
+	}
+}
+
+// Quita la orden de los índices (requiere r.mu tomado para escritura)
+func (r *inMemoryRepository) unindex(o Order) {
+	delete(r.byUser[o.UserID], o.ID)
+	delete(r.byStatus[string(o.Status)], o.ID)
+	for _, item := range o.LineItems {
+		delete(r.byProduct[item.ProductID], o.ID)
+	}
+}
+
+// Agrega un ID al conjunto de la clave, creándolo si no existe
+func addTo(idx map[string]map[string]bool, key, id string) {
+	if idx[key] == nil {
+		idx[key] = make(map[string]bool)
+	}
+	idx[key][id] = true
+}
+
+// Buscar órdenes con filtros, orden y paginación
+func (s *orderService) SearchOrders(ctx context.Context, f OrderFilter) (*OrderPage, error) {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.SortBy == "" {
+		f.SortBy = SortByCreated
+	}
+	switch {
+	case f.Limit < 0 || f.Limit > MaxPageSize || f.Offset < 0:
+		return nil, fmt.Errorf("%w: limit must be between 1 and 200 and offset non-negative", ErrInvalidFilter)
+	case f.SortBy != SortByCreated && f.SortBy != SortByUpdated:
+		return nil, fmt.Errorf("%w: sort must be created_at or updated_at", ErrInvalidFilter)
+	case f.MinTotal != nil && f.MaxTotal != nil && *f.MinTotal > *f.MaxTotal:
+		return nil, fmt.Errorf("%w: min_total is greater than max_total", ErrInvalidFilter)
+	case f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo):
+		return nil, fmt.Errorf("%w: created_from must be before created_to", ErrInvalidFilter)
+	}
+	list, total, err := s.repo.Search(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderPage{Orders: list, Total: total, Limit: f.Limit, Offset: f.Offset}, nil
+}
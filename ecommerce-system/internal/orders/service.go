@@ -4,6 +4,9 @@ package orders
 import (
 	"context" // Manejo de contexto en funciones
 	"errors"  // Manejo de errores
+	"fmt"     // Formateo de errores
+	"math"    // Comparación de montos
+	"sync"    // Sincronización de acceso concurrente
 	"time"    // Manejo de tiempos y fechas
 
 	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Servicio productos
@@ -11,50 +14,84 @@ import (
 
 // Interfaz que define las funciones que debe implementar el servicio de órdenes
 type Service interface {
-	CreateOrder(ctx context.Context, userID string, items []LineItemRequest) (*Order, error)   // Crear orden
-	GetOrdersByUserID(ctx context.Context, userID string) ([]Order, error)                     // Obtener órdenes por usuario
-	UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) // Actualizar estado de orden
-	ListAllOrders(ctx context.Context) []Order                                                 // Listar todas las órdenes
+	CreateOrder(ctx context.Context, req OrderRequest) (*Order, error)                                                            // Crear orden
+	QuoteOrder(ctx context.Context, req OrderRequest) (*Quote, error)                                                             // Cotizar orden sin crearla
+	GetOrderByID(ctx context.Context, orderID string) (*Order, error)                                                             // Obtener orden por ID
+	GetOwnedOrder(ctx context.Context, orderID, userID string) (*Order, error)                                                    // Obtener orden por ID si pertenece al usuario
+	RecordPayment(ctx context.Context, orderID string, amount float64) (*Order, error)                                            // Registrar un cobro capturado
+	RecordAuthorization(ctx context.Context, orderID string, amount float64) (*Order, error)                                      // Registrar (monto positivo) o liberar (negativo) una autorización sin cobrar
+	RecordRefund(ctx context.Context, orderID string, amount float64, quantities map[int]int) (*Order, error)                     // Registrar un reembolso (unidades por número de línea)
+	RevertRefund(ctx context.Context, orderID string, amount float64, quantities map[int]int, status OrderStatus) (*Order, error) // Deshacer un reembolso que no se pudo devolver
+	RecordFulfillment(ctx context.Context, orderID string, shipped, delivered map[int]int) (*Order, error)                        // Registrar unidades despachadas y entregadas (por número de línea)
+	GetOrdersByUserID(ctx context.Context, userID string) ([]Order, error)                                                        // Obtener órdenes por usuario
+	UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus, version int) (*Order, error)                       // Actualizar estado de orden si la versión coincide
+	ListAllOrders(ctx context.Context) []Order                                                                                    // Listar todas las órdenes
+	SearchOrders(ctx context.Context, f OrderFilter) (*OrderPage, error)                                                          // Buscar órdenes con filtros, orden y paginación
+	CancelStalePending(ctx context.Context, createdBefore, now time.Time, reason string) ([]Order, error)                         // Cancelar órdenes pendientes sin pago creadas antes de una fecha
+	AddLineItem(ctx context.Context, orderID string, item LineItemRequest, actor string, version int) (*Order, error)             // Agregar un producto a una orden pendiente
+	UpdateLineItem(ctx context.Context, orderID string, line, quantity int, actor string, version int) (*Order, error)            // Cambiar la cantidad de una línea de una orden pendiente
+	RemoveLineItem(ctx context.Context, orderID string, line int, actor string, version int) (*Order, error)                      // Quitar una línea de una orden pendiente
 }
 
 // Implementación en memoria del repositorio de órdenes
 type inMemoryRepository struct {
-	data map[string]Order // Mapa que almacena órdenes indexadas por ID
+	mu        sync.RWMutex               // Mutex para sincronizar acceso concurrente
+	data      map[string]Order           // Mapa que almacena órdenes indexadas por ID
+	byUser    map[string]map[string]bool // Índice de IDs por usuario
+	byStatus  map[string]map[string]bool // Índice de IDs por estado
+	byProduct map[string]map[string]bool // Índice de IDs por producto contenido
 }
 
 // Constructor para crear un nuevo repositorio en memoria
 func NewInMemoryRepository() *inMemoryRepository {
-	return &inMemoryRepository{data: make(map[string]Order)} // Inicializa mapa vacío
+	return &inMemoryRepository{
+		data:      make(map[string]Order), // Inicializa mapa vacío
+		byUser:    make(map[string]map[string]bool),
+		byStatus:  make(map[string]map[string]bool),
+		byProduct: make(map[string]map[string]bool),
+	}
 }
 
-// Guarda una orden en el repositorio (inserta o actualiza)
+// Guarda una orden nueva en el repositorio con la versión inicial
 func (r *inMemoryRepository) Save(ctx context.Context, o Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o.Version = 1 // Toda orden nueva empieza en la versión 1
 	r.data[o.ID] = o
+	r.index(o)
 	return nil
 }
 
 // Obtiene una orden por ID, devuelve error si no existe
 func (r *inMemoryRepository) GetByID(ctx context.Context, id string) (*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	o, ok := r.data[id]
 	if !ok {
-		return nil, errors.New("order not found")
+		return nil, ErrOrderNotFound
 	}
 	return &o, nil
 }
 
 // Obtiene todas las órdenes asociadas a un usuario
 func (r *inMemoryRepository) GetByUserID(ctx context.Context, userID string) ([]Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	orders := []Order{}
-	for _, o := range r.data {
-		if o.UserID == userID {
-			orders = append(orders, o)
-		}
+	for id := range r.byUser[userID] {
+		orders = append(orders, r.data[id])
 	}
 	return orders, nil
 }
 
 // Obtiene todas las órdenes del repositorio
 func (r *inMemoryRepository) GetAll(ctx context.Context) []Order {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	orders := make([]Order, 0, len(r.data))
 	for _, o := range r.data {
 		orders = append(orders, o)
@@ -62,9 +99,22 @@ func (r *inMemoryRepository) GetAll(ctx context.Context) []Order {
 	return orders
 }
 
-// Actualiza una orden existente en el repositorio
+// Actualiza una orden existente solo si su versión coincide con la almacenada
 func (r *inMemoryRepository) Update(ctx context.Context, o Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.data[o.ID]
+	if !ok {
+		return ErrOrderNotFound
+	}
+	if current.Version != o.Version {
+		return ErrVersionConflict // Otra operación modificó la orden antes
+	}
+	o.Version++ // Nueva versión tras la escritura
+	r.unindex(current)
 	r.data[o.ID] = o
+	r.index(o)
 	return nil
 }
 
@@ -74,64 +124,105 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (*Order, error)
 	GetByUserID(ctx context.Context, userID string) ([]Order, error)
 	GetAll(ctx context.Context) []Order
-	Update(ctx context.Context, o Order) error
+	Update(ctx context.Context, o Order) error                       // Falla con ErrVersionConflict si la versión no coincide
+	Search(ctx context.Context, f OrderFilter) ([]Order, int, error) // Página de órdenes que cumplen el filtro y total de coincidencias
 }
 
 // Implementación del servicio de órdenes que usa un repositorio y servicio de productos
 type orderService struct {
-	repo           Repository       // Repositorio de órdenes
-	productService products.Service // Servicio de productos para validar stock y datos
+	repo           Repository        // Repositorio de órdenes
+	productService products.Service  // Servicio de productos para validar stock y datos
+	allocator      StockAllocator    // Asignación de bodegas (opcional)
+	coupons        CouponRedeemer    // Motor de cupones (opcional)
+	promotions     PromotionEngine   // Promociones automáticas (opcional)
+	pricingSteps   []PricingStep     // Pasos adicionales del pipeline de precios (impuestos, envío...)
+	payObservers   []PaymentObserver // Interesados en las órdenes que quedan pagadas
 }
 
+// PaymentObserver recibe la orden en el momento en que queda pagada por completo
+type PaymentObserver interface {
+	OrderPaid(ctx context.Context, o Order)
+}
+
+// Opción que registra un observador de órdenes pagadas (ej. facturación)
+func WithPaymentObserver(observer PaymentObserver) Option {
+	return func(s *orderService) { s.payObservers = append(s.payObservers, observer) }
+}
+
+// Option configura dependencias opcionales del servicio de órdenes
+type Option func(*orderService)
+
 // Constructor para crear un nuevo servicio de órdenes
-func NewService(repo Repository, prodService products.Service) Service {
-	return &orderService{repo: repo, productService: prodService}
+func NewService(repo Repository, prodService products.Service, opts ...Option) Service {
+	s := &orderService{repo: repo, productService: prodService}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Crear una orden nueva validando los productos y stock disponible
-func (s *orderService) CreateOrder(ctx context.Context, userID string, itemRequests []LineItemRequest) (*Order, error) {
-	if userID == "" || len(itemRequests) == 0 {
-		return nil, errors.New("invalid order data") // Validación básica de entrada
+func (s *orderService) CreateOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	pricing, _, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	userID := req.UserID
+	processedLineItems := pricing.Lines // Incluye los regalos de las promociones
 
-	var processedLineItems []LineItem
-	var orderTotal float64
+	// Generar ID basado en timestamp para orden
+	id := time.Now().Format("20060102150405.000000")
 
-	for _, itemReq := range itemRequests {
-		// Obtener producto para validar existencia y stock
-		prod, err := s.productService.GetProductByID(ctx, itemReq.ProductID)
-		if err != nil {
-			return nil, errors.New("product not found")
-		}
-		if prod.Stock < itemReq.Quantity {
-			return nil, errors.New("insufficient stock") // Validar stock suficiente
-		}
-		// Construir LineItem para la orden
-		processedItem := LineItem{
-			ProductID: itemReq.ProductID,
-			Quantity:  itemReq.Quantity,
-			Price:     prod.Price,
-		}
-		processedLineItems = append(processedLineItems, processedItem)
-		orderTotal += prod.Price * float64(itemReq.Quantity) // Calcular total acumulado
+	// Descontar el stock registrando la venta en el libro de inventario
+	if err := s.takeStock(ctx, id, userID, processedLineItems); err != nil {
+		return nil, err
+	}
+	// Asignar la bodega que despacha cada línea
+	if err := s.allocate(ctx, id, processedLineItems, req.Destination); err != nil {
+		s.releaseStock(ctx, id, processedLineItems)
+		return nil, err
 	}
 
-	// Generar ID basado en timestamp para orden
-	id := time.Now().Format("20060102150405.000000")
+	// Registrar el uso del cupón ya calculado
+	var couponCode string
+	var couponDiscount float64
+	if pricing.Coupon != nil {
+		if _, err := s.coupons.Redeem(ctx, pricing.CouponCode, userID, id, pricing.couponLines); err != nil {
+			s.releaseStock(ctx, id, processedLineItems) // Otro pedido agotó el cupón entre la validación y el uso
+			return nil, err
+		}
+		couponCode, couponDiscount = pricing.Coupon.Code, pricing.Coupon.Amount
+	}
+	totals := pricing.Totals()
 
 	// Crear instancia de Order completa
 	o := Order{
-		ID:        id,
-		UserID:    userID,
-		LineItems: processedLineItems,
-		Total:     orderTotal,
-		Status:    StatusPending, // Estado inicial Pendiente
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                id,
+		UserID:            userID,
+		LineItems:         processedLineItems,
+		ShippingAddress:   pricing.ShippingAddress,
+		BillingAddress:    pricing.BillingAddress,
+		Promotions:        pricing.Promotions,
+		PromotionDiscount: pricing.promotionDiscount(),
+		CouponCode:        couponCode,
+		CouponDiscount:    couponDiscount,
+		Subtotal:          totals.Subtotal,
+		DiscountTotal:     totals.DiscountTotal,
+		TaxTotal:          totals.TaxTotal,
+		ShippingTotal:     totals.ShippingTotal,
+		ShippingZone:      pricing.ShippingZone,
+		Destination:       req.Destination,
+		GrandTotal:        totals.GrandTotal,
+		Total:             totals.GrandTotal,
+		PriceBreakdown:    pricing.Adjustments,
+		Status:            StatusPending, // Estado inicial Pendiente
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
 	// Guardar la orden en el repositorio
 	s.repo.Save(ctx, o)
+	o.Version = 1 // Versión inicial asignada por el repositorio
 	return &o, nil
 }
 
@@ -140,18 +231,257 @@ func (s *orderService) GetOrdersByUserID(ctx context.Context, userID string) ([]
 	return s.repo.GetByUserID(ctx, userID)
 }
 
-// Actualizar el estado de una orden por su ID
-func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) {
+// Actualizar el estado de una orden por su ID; version es la versión que el cliente leyó.
+// Solo se cancela una orden Pendiente sin cobros ni autorizaciones (lo cobrado se devuelve con un reembolso)
+// y Reembolsado lo fija únicamente el registro de reembolsos.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus, version int) (*Order, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
 	o, err := s.repo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
+	if o.IsFinal() {
+		return nil, ErrInvalidTransition // Reabrir la orden volvería a liberar su stock al cancelarla
+	}
+	switch {
+	case status == StatusRefunded:
+		return nil, ErrInvalidTransition // Sin dinero devuelto no hay reembolso
+	case status == StatusCancelled && (o.Status != StatusPending || o.PaidAmount > 0 || o.AuthorizedAmount > 0):
+		return nil, ErrInvalidTransition // Liberar el stock sin devolver lo cobrado lo repondría dos veces al reembolsar
+	case status == StatusPending && o.Status != StatusPending:
+		return nil, ErrInvalidTransition // Una orden pagada no vuelve a Pendiente
+	}
+	if o.Status == StatusPending && status != StatusPending && status != StatusCancelled && !o.IsPaid() {
+		return nil, ErrPaymentRequired // Solo un pago capturado saca a la orden de Pendiente
+	}
 	o.Status = status        // Cambiar estado
 	o.UpdatedAt = time.Now() // Actualizar timestamp
-	s.repo.Update(ctx, *o)   // Guardar cambios en repositorio
+	if status == StatusDelivered && o.DeliveredAt == nil {
+		deliveredAt := o.UpdatedAt
+		o.DeliveredAt = &deliveredAt
+	}
+	if status == StatusCancelled {
+		cancelledAt := o.UpdatedAt
+		o.CancelledAt = &cancelledAt
+	}
+	o.Version = version // El repositorio rechaza la escritura si la versión ya cambió
+	if err := s.repo.Update(ctx, *o); err != nil {
+		return nil, err
+	}
+	o.Version++ // Reflejar la versión asignada por el repositorio
+	if status == StatusCancelled {
+		s.releaseOrder(ctx, o)
+	}
+	return o, nil
+}
+
+// Devuelve al inventario lo reservado por una orden cancelada y libera el uso de su cupón
+func (s *orderService) releaseOrder(ctx context.Context, o *Order) {
+	s.releaseStock(ctx, o.ID, o.LineItems)
+	if o.CouponCode != "" && s.coupons != nil {
+		s.coupons.ReleaseRedemption(ctx, o.CouponCode, o.UserID, o.ID) // El uso del cupón vuelve a estar disponible
+	}
+}
+
+// Obtener una orden por su ID
+func (s *orderService) GetOrderByID(ctx context.Context, orderID string) (*Order, error) {
+	return s.repo.GetByID(ctx, orderID)
+}
+
+// Obtener una orden por su ID verificando que pertenezca al usuario que la pide
+func (s *orderService) GetOwnedOrder(ctx context.Context, orderID, userID string) (*Order, error) {
+	o, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.UserID != userID {
+		return nil, ErrNotOrderOwner
+	}
 	return o, nil
 }
 
+// Registrar un cobro capturado; cuando la orden queda pagada pasa de Pendiente a Procesado
+func (s *orderService) RecordPayment(ctx context.Context, orderID string, amount float64) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if o.Status == StatusCancelled {
+			return nil, ErrNotPayable
+		}
+		o.PaidAmount = roundCents(o.PaidAmount + amount)
+		justPaid := o.IsPaid() && o.PaidAt == nil
+		if justPaid {
+			now := time.Now()
+			o.PaidAt = &now
+			if o.Status == StatusPending {
+				o.Status = StatusProcessed
+			}
+		}
+		o.UpdatedAt = time.Now()
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y aplicar el cobro
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++ // Reflejar la versión asignada por el repositorio
+		if justPaid {
+			for _, observer := range s.payObservers {
+				observer.OrderPaid(ctx, *o)
+			}
+		}
+		return o, nil
+	}
+}
+
+// Registrar una autorización de pago pendiente de cobro (amount positivo) o liberarla al cobrarla, anularla
+// o fallar (negativo). Mientras haya una autorización la orden no se edita ni se cancela por vencimiento,
+// y solo se registra si cubre exactamente el saldo pendiente de una orden Pendiente
+func (s *orderService) RecordAuthorization(ctx context.Context, orderID string, amount float64) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if amount > 0 && (o.Status != StatusPending || math.Abs(o.GrandTotal-o.PaidAmount-amount) >= 0.005) {
+			return nil, ErrNotPayable // La orden cambió mientras se autorizaba el pago
+		}
+		if amount > 0 && o.AuthorizedAmount > 0 {
+			return nil, ErrNotPayable // Ya hay otra autorización sin cobrar por el saldo
+		}
+		o.AuthorizedAmount = math.Max(0, roundCents(o.AuthorizedAmount+amount))
+		o.UpdatedAt = time.Now()
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y verificar
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++ // Reflejar la versión asignada por el repositorio
+		return o, nil
+	}
+}
+
+// Registrar un reembolso: suma el monto y las unidades devueltas por línea; la orden pasa a Reembolsado
+// cuando se devuelve todo lo cobrado
+func (s *orderService) RecordRefund(ctx context.Context, orderID string, amount float64, quantities map[int]int) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		o.LineItems = append([]LineItem(nil), o.LineItems...) // Copia para no modificar la orden guardada
+		for line, qty := range quantities {
+			item, ok := o.LineByNumber(line)
+			if !ok || item.RefundedQuantity+qty > item.Quantity {
+				return nil, fmt.Errorf("invalid refund quantity for line %d", line)
+			}
+			item.RefundedQuantity += qty
+		}
+		o.RefundedAmount = roundCents(o.RefundedAmount + amount)
+		if o.PaidAmount > 0 && o.Refundable() <= 0 {
+			o.Status = StatusRefunded
+		} else if status, ok := o.fulfillmentStatus(); ok {
+			o.Status = status // Las unidades reembolsadas ya no quedan pendientes de envío
+		}
+		o.UpdatedAt = time.Now()
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y aplicar el reembolso
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++ // Reflejar la versión asignada por el repositorio
+		return o, nil
+	}
+}
+
+// Deshacer un reembolso registrado con RecordRefund cuyo dinero no se pudo devolver; status es el estado que
+// tenía la orden antes del reembolso y se restaura si el reembolso la dejó como Reembolsado
+func (s *orderService) RevertRefund(ctx context.Context, orderID string, amount float64, quantities map[int]int, status OrderStatus) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		o.LineItems = append([]LineItem(nil), o.LineItems...) // Copia para no modificar la orden guardada
+		for line, qty := range quantities {
+			item, ok := o.LineByNumber(line)
+			if !ok || item.RefundedQuantity < qty {
+				return nil, fmt.Errorf("invalid refund quantity for line %d", line)
+			}
+			item.RefundedQuantity -= qty
+		}
+		o.RefundedAmount = roundCents(o.RefundedAmount - amount)
+		if o.Status == StatusRefunded {
+			o.Status = status
+		}
+		if status, ok := o.fulfillmentStatus(); ok {
+			o.Status = status // Las unidades vuelven a quedar pendientes de envío
+		}
+		o.UpdatedAt = time.Now()
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++
+		return o, nil
+	}
+}
+
+// Registrar unidades despachadas y entregadas por línea; el estado de la orden se deriva de ellas
+// (Enviado parcialmente, Enviado o Entregado)
+func (s *orderService) RecordFulfillment(ctx context.Context, orderID string, shipped, delivered map[int]int) (*Order, error) {
+	for {
+		o, err := s.repo.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		o.LineItems = append([]LineItem(nil), o.LineItems...) // Copia para no modificar la orden guardada
+		for line, qty := range shipped {
+			item, ok := o.LineByNumber(line)
+			if !ok || item.ShippedQuantity+qty > item.Quantity-item.RefundedQuantity {
+				return nil, fmt.Errorf("invalid shipped quantity for line %d", line)
+			}
+			item.ShippedQuantity += qty
+		}
+		for line, qty := range delivered {
+			item, ok := o.LineByNumber(line)
+			if !ok || item.DeliveredQuantity+qty > item.ShippedQuantity {
+				return nil, fmt.Errorf("invalid delivered quantity for line %d", line)
+			}
+			item.DeliveredQuantity += qty
+		}
+		o.UpdatedAt = time.Now()
+		if status, ok := o.fulfillmentStatus(); ok {
+			o.Status = status
+			if status == StatusDelivered && o.DeliveredAt == nil {
+				deliveredAt := o.UpdatedAt
+				o.DeliveredAt = &deliveredAt
+			}
+		}
+		err = s.repo.Update(ctx, *o)
+		if errors.Is(err, ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y aplicar el envío
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.Version++ // Reflejar la versión asignada por el repositorio
+		return o, nil
+	}
+}
+
 // Listar todas las órdenes existentes
 func (s *orderService) ListAllOrders(ctx context.Context) []Order {
 	return s.repo.GetAll(ctx)
@@ -0,0 +1,65 @@
+// Pruebas de las transiciones de estado permitidas al actualizar una orden
+package orders_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+)
+
+func TestUpdateOrderStatusTransitions(t *testing.T) {
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 20})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	svc := orders.NewService(orders.NewInMemoryRepository(), productService)
+
+	tests := []struct {
+		name    string
+		prepare func(o *orders.Order) // Pagos previos sobre la orden recién creada
+		status  orders.OrderStatus
+		want    error
+	}{
+		{name: "estado desconocido", status: orders.OrderStatus("Perdido"), want: orders.ErrInvalidStatus},
+		{name: "reembolsado a mano", status: orders.StatusRefunded, want: orders.ErrInvalidTransition},
+		{name: "cancelar pendiente sin pagos", status: orders.StatusCancelled},
+		{
+			name: "cancelar con pago cobrado",
+			prepare: func(o *orders.Order) {
+				if _, err := svc.RecordPayment(ctx, o.ID, 1); err != nil {
+					t.Fatalf("RecordPayment: %v", err)
+				}
+			},
+			status: orders.StatusCancelled,
+			want:   orders.ErrInvalidTransition,
+		},
+		{
+			name: "cancelar con pago autorizado",
+			prepare: func(o *orders.Order) {
+				if _, err := svc.RecordAuthorization(ctx, o.ID, o.GrandTotal); err != nil {
+					t.Fatalf("RecordAuthorization: %v", err)
+				}
+			},
+			status: orders.StatusCancelled,
+			want:   orders.ErrInvalidTransition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := createOrder(t, svc, prod.ID, 1)
+			if tt.prepare != nil {
+				tt.prepare(o)
+			}
+			current, _ := svc.GetOrderByID(ctx, o.ID)
+			_, err := svc.UpdateOrderStatus(ctx, o.ID, tt.status, current.Version)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
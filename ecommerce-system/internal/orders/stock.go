@@ -0,0 +1,95 @@
+// Paquete para manejo de órdenes
+package orders
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"   // Servicio productos
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Asignación de bodegas
+)
+
+// StockAllocator asigna y aparta stock por bodega para las líneas de una orden
+type StockAllocator interface {
+	Reserve(ctx context.Context, lines []warehouses.AllocationLine, dest warehouses.Destination) ([][]warehouses.Allocation, error)
+	Release(ctx context.Context, productID string, allocations []warehouses.Allocation) error
+	Plan(ctx context.Context, lines []warehouses.AllocationLine, dest warehouses.Destination) ([][]warehouses.Allocation, error) // Asignación sin apartar stock
+}
+
+// Opción que habilita la asignación de bodegas al crear órdenes
+func WithStockAllocator(allocator StockAllocator) Option {
+	return func(s *orderService) { s.allocator = allocator }
+}
+
+// Descuenta del inventario las cantidades de cada línea; si alguna falla revierte las anteriores
+func (s *orderService) takeStock(ctx context.Context, orderID, userID string, items []LineItem) error {
+	for i, item := range items {
+		_, err := s.productService.AdjustStock(ctx, item.ProductID, products.StockAdjustment{
+			Delta:       -item.Quantity,
+			Reason:      products.ReasonSale,
+			Actor:       userID,
+			ReferenceID: orderID,
+		})
+		if err != nil {
+			s.releaseStock(ctx, orderID, items[:i]) // Revertir lo ya descontado
+			if errors.Is(err, products.ErrorStockInsuficiente) {
+				return errors.New("insufficient stock")
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Devuelve al inventario (y a sus bodegas) las cantidades de las líneas indicadas
+func (s *orderService) releaseStock(ctx context.Context, orderID string, items []LineItem) {
+	for _, item := range items {
+		s.productService.AdjustStock(ctx, item.ProductID, products.StockAdjustment{
+			Delta:       item.Quantity,
+			Reason:      products.ReasonCancellation,
+			ReferenceID: orderID,
+		})
+		if s.allocator != nil && len(item.Allocations) > 0 {
+			s.allocator.Release(ctx, item.ProductID, item.Allocations)
+		}
+	}
+}
+
+// Líneas de asignación de bodega para los ítems de una orden
+func allocationLines(items []LineItem) []warehouses.AllocationLine {
+	lines := make([]warehouses.AllocationLine, len(items))
+	for i, item := range items {
+		lines[i] = warehouses.AllocationLine{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	return lines
+}
+
+// Calcula sin apartar stock las bodegas que despacharían cada línea y las guarda en los ítems (para cotizar)
+func (s *orderService) planAllocation(ctx context.Context, items []LineItem, destination *warehouses.Location) error {
+	if s.allocator == nil {
+		return nil
+	}
+	allocations, err := s.allocator.Plan(ctx, allocationLines(items), warehouses.Destination{Location: destination})
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Allocations = allocations[i]
+	}
+	return nil
+}
+
+// Asigna bodegas a las líneas de la orden y guarda el resultado en cada línea
+func (s *orderService) allocate(ctx context.Context, orderID string, items []LineItem, destination *warehouses.Location) error {
+	if s.allocator == nil {
+		return nil // Sin bodegas configuradas el stock es general
+	}
+	allocations, err := s.allocator.Reserve(ctx, allocationLines(items), warehouses.Destination{Location: destination})
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Allocations = allocations[i]
+	}
+	return nil
+}
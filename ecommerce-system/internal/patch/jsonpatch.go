@@ -0,0 +1,256 @@
+// Paquete para aplicar actualizaciones parciales sobre documentos JSON
+package patch
+
+import (
+	"encoding/json" // Serialización y deserialización JSON
+	"fmt"           // Formateo de errores
+	"reflect"       // Comparación profunda de valores para "test"
+	"strconv"       // Conversión de índices de arreglos
+	"strings"       // Manipulación de punteros JSON
+)
+
+// Operation representa una operación de JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string          `json:"op"`              // add, remove, replace, move, copy o test
+	Path  string          `json:"path"`            // Puntero JSON al destino
+	From  string          `json:"from,omitempty"`  // Puntero JSON de origen (move y copy)
+	Value json.RawMessage `json:"value,omitempty"` // Valor para add, replace y test
+}
+
+// Aplica una lista de operaciones JSON Patch sobre el documento
+func JSONPatch(doc, patchDoc []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patchDoc, &ops); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		var err error
+		root, err = applyOperation(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// Aplica una operación individual y retorna el nuevo documento raíz
+func applyOperation(root interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, ErrInvalidPatch // Estas operaciones requieren "value"
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, ErrInvalidPatch
+		}
+		switch op.Op {
+		case "add":
+			return add(root, op.Path, value)
+		case "replace":
+			if op.Path == "" {
+				return value, nil // Reemplaza el documento completo
+			}
+			root, err := remove(root, op.Path) // Falla si el destino no existe
+			if err != nil {
+				return nil, err
+			}
+			return add(root, op.Path, value)
+		default:
+			current, err := get(root, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return root, nil
+		}
+	case "remove":
+		return remove(root, op.Path)
+	case "move", "copy":
+		value, err := get(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, ErrInvalidPatch // No se puede mover un valor dentro de sí mismo
+			}
+			if root, err = remove(root, op.From); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return add(root, op.Path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// Divide un puntero JSON (RFC 6901) en sus segmentos ya decodificados
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil // Puntero a la raíz
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid pointer %q", ErrInvalidPatch, pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "~1", "/")
+		parts[i] = strings.ReplaceAll(part, "~0", "~")
+	}
+	return parts, nil
+}
+
+// Obtiene el valor al que apunta el puntero
+func get(root interface{}, pointer string) (interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := root
+	for _, part := range parts {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("%w: path %q not found", ErrInvalidPatch, pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(part, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%w: path %q not found", ErrInvalidPatch, pointer)
+		}
+	}
+	return current, nil
+}
+
+// Inserta o reemplaza un valor en la ubicación indicada
+func add(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil // Reemplaza el documento completo
+	}
+	parent, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return root, nil
+	case []interface{}:
+		index := len(node)
+		if last != "-" {
+			if index, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		grown := append(node[:index:index], append([]interface{}{value}, node[index:]...)...)
+		return replaceAt(root, parts[:len(parts)-1], grown)
+	default:
+		return nil, fmt.Errorf("%w: cannot add at %q", ErrInvalidPatch, pointer)
+	}
+}
+
+// Elimina el valor en la ubicación indicada
+func remove(root interface{}, pointer string) (interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the root", ErrInvalidPatch)
+	}
+	parent, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("%w: path %q not found", ErrInvalidPatch, pointer)
+		}
+		delete(node, last)
+		return root, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		shrunk := append(node[:index:index], node[index+1:]...)
+		return replaceAt(root, parts[:len(parts)-1], shrunk)
+	default:
+		return nil, fmt.Errorf("%w: path %q not found", ErrInvalidPatch, pointer)
+	}
+}
+
+// Sustituye el arreglo ubicado en parts por uno nuevo (los slices cambian de cabecera al crecer)
+func replaceAt(root interface{}, parts []string, value interface{}) (interface{}, error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	parent, err := get(root, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	}
+	return root, nil
+}
+
+// Convierte un segmento en índice de arreglo validando el rango [0, max]
+func arrayIndex(part string, max int) (int, error) {
+	if part != "0" && strings.HasPrefix(part, "0") {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, part)
+	}
+	index, err := strconv.Atoi(part)
+	if err != nil || index < 0 || index > max {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, part)
+	}
+	return index, nil
+}
+
+// Reconstruye un puntero JSON a partir de sus segmentos
+func joinPointer(parts []string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		part = strings.ReplaceAll(part, "~", "~0")
+		b.WriteString("/" + strings.ReplaceAll(part, "/", "~1"))
+	}
+	return b.String()
+}
+
+// Copia profunda de un valor JSON decodificado
+func deepCopy(value interface{}) interface{} {
+	raw, _ := json.Marshal(value)
+	var out interface{}
+	json.Unmarshal(raw, &out)
+	return out
+}
@@ -0,0 +1,69 @@
+// Paquete para aplicar actualizaciones parciales sobre documentos JSON
+package patch
+
+import (
+	"encoding/json" // Serialización y deserialización JSON
+	"errors"        // Manejo de errores
+	"mime"          // Interpretación del encabezado Content-Type
+)
+
+// Tipos de contenido soportados para solicitudes PATCH
+const (
+	MediaTypeMergePatch = "application/merge-patch+json" // JSON Merge Patch (RFC 7396)
+	MediaTypeJSONPatch  = "application/json-patch+json"  // JSON Patch (RFC 6902)
+)
+
+// Errores generales del paquete
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported patch media type") // Content-Type no soportado
+	ErrInvalidPatch         = errors.New("invalid patch document")       // Documento de parche mal formado
+	ErrTestFailed           = errors.New("patch test operation failed")  // Falló una operación "test"
+)
+
+// Aplica un parche según su Content-Type y retorna el documento resultante
+func Apply(contentType string, doc, patchDoc []byte) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	switch mediaType {
+	case MediaTypeMergePatch, "application/json": // application/json se trata como merge patch
+		return MergePatch(doc, patchDoc)
+	case MediaTypeJSONPatch:
+		return JSONPatch(doc, patchDoc)
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+}
+
+// Aplica un JSON Merge Patch (RFC 7396) sobre el documento
+func MergePatch(doc, patchDoc []byte) ([]byte, error) {
+	var target, patchValue interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patchDoc, &patchValue); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	return json.Marshal(mergeValue(target, patchValue))
+}
+
+// Combina recursivamente el parche con el valor destino
+func mergeValue(target, patchValue interface{}) interface{} {
+	patchObj, ok := patchValue.(map[string]interface{})
+	if !ok {
+		return patchValue // Un valor que no es objeto reemplaza al destino completo
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key) // null elimina el miembro
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,169 @@
+// Pruebas de JSON Patch (RFC 6902) y JSON Merge Patch (RFC 7396)
+package patch_test
+
+import (
+	"encoding/json" // Comparación de documentos JSON
+	"errors"        // Comparación de errores
+	"reflect"       // Comparación profunda de valores
+	"testing"       // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/patch"
+)
+
+// Compara dos documentos JSON sin importar el orden de las claves
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("resultado no es JSON: %v (%s)", err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("esperado no es JSON: %v", err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("resultado = %s, se esperaba %s", got, want)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	const doc = `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"a/b":1,"m~n":2}`
+	tests := []struct {
+		name  string
+		patch string
+		want  string // Documento esperado; vacío si se espera un error
+		err   error
+	}{
+		{name: "add miembro", patch: `[{"op":"add","path":"/price","value":10}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"a/b":1,"m~n":2,"price":10}`},
+		{name: "add reemplaza miembro existente", patch: `[{"op":"add","path":"/name","value":"Té"}]`,
+			want: `{"name":"Té","tags":["a","b","c"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "add en índice", patch: `[{"op":"add","path":"/tags/1","value":"x"}]`,
+			want: `{"name":"Café","tags":["a","x","b","c"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "add al final con -", patch: `[{"op":"add","path":"/tags/-","value":"z"}]`,
+			want: `{"name":"Café","tags":["a","b","c","z"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "add en índice igual al largo", patch: `[{"op":"add","path":"/tags/3","value":"z"}]`,
+			want: `{"name":"Café","tags":["a","b","c","z"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "add fuera de rango", patch: `[{"op":"add","path":"/tags/4","value":"z"}]`, err: patch.ErrInvalidPatch},
+		{name: "add con índice con cero inicial", patch: `[{"op":"add","path":"/tags/01","value":"z"}]`, err: patch.ErrInvalidPatch},
+		{name: "add sin padre", patch: `[{"op":"add","path":"/missing/x","value":1}]`, err: patch.ErrInvalidPatch},
+		{name: "remove miembro", patch: `[{"op":"remove","path":"/stock/uio"}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{},"a/b":1,"m~n":2}`},
+		{name: "remove elemento", patch: `[{"op":"remove","path":"/tags/0"}]`,
+			want: `{"name":"Café","tags":["b","c"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "remove fuera de rango", patch: `[{"op":"remove","path":"/tags/3"}]`, err: patch.ErrInvalidPatch},
+		{name: "remove con - no es válido", patch: `[{"op":"remove","path":"/tags/-"}]`, err: patch.ErrInvalidPatch},
+		{name: "remove inexistente", patch: `[{"op":"remove","path":"/price"}]`, err: patch.ErrInvalidPatch},
+		{name: "replace miembro", patch: `[{"op":"replace","path":"/stock/uio","value":7}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":7},"a/b":1,"m~n":2}`},
+		{name: "replace elemento", patch: `[{"op":"replace","path":"/tags/2","value":"y"}]`,
+			want: `{"name":"Café","tags":["a","b","y"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "replace inexistente", patch: `[{"op":"replace","path":"/price","value":1}]`, err: patch.ErrInvalidPatch},
+		{name: "replace sin value", patch: `[{"op":"replace","path":"/name"}]`, err: patch.ErrInvalidPatch},
+		{name: "move entre objetos", patch: `[{"op":"move","from":"/stock/uio","path":"/stock/gye"}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"gye":5},"a/b":1,"m~n":2}`},
+		{name: "move dentro del arreglo", patch: `[{"op":"move","from":"/tags/0","path":"/tags/-"}]`,
+			want: `{"name":"Café","tags":["b","c","a"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "move dentro de sí mismo", patch: `[{"op":"move","from":"/stock","path":"/stock/inner"}]`, err: patch.ErrInvalidPatch},
+		{name: "copy", patch: `[{"op":"copy","from":"/stock","path":"/backup"}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"backup":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "copy es independiente del original", patch: `[{"op":"copy","from":"/stock","path":"/backup"},{"op":"replace","path":"/backup/uio","value":0}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"backup":{"uio":0},"a/b":1,"m~n":2}`},
+		{name: "test exitoso", patch: `[{"op":"test","path":"/tags","value":["a","b","c"]},{"op":"replace","path":"/name","value":"Té"}]`,
+			want: `{"name":"Té","tags":["a","b","c"],"stock":{"uio":5},"a/b":1,"m~n":2}`},
+		{name: "test fallido", patch: `[{"op":"test","path":"/name","value":"Té"}]`, err: patch.ErrTestFailed},
+		{name: "escape ~1", patch: `[{"op":"replace","path":"/a~1b","value":9}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"a/b":9,"m~n":2}`},
+		{name: "escape ~0", patch: `[{"op":"remove","path":"/m~0n"}]`,
+			want: `{"name":"Café","tags":["a","b","c"],"stock":{"uio":5},"a/b":1}`},
+		{name: "puntero sin barra inicial", patch: `[{"op":"remove","path":"name"}]`, err: patch.ErrInvalidPatch},
+		{name: "operación desconocida", patch: `[{"op":"merge","path":"/name","value":1}]`, err: patch.ErrInvalidPatch},
+		{name: "parche que no es lista", patch: `{"op":"remove","path":"/name"}`, err: patch.ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.JSONPatch([]byte(doc), []byte(tt.patch))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, se esperaba %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONPatch: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestJSONPatchFailedTestLeavesDocumentUnchanged(t *testing.T) {
+	doc := []byte(`{"name":"Café","stock":5}`)
+	original := string(doc)
+	// La primera operación se aplicaría, pero el test posterior falla y no se devuelve ningún documento
+	got, err := patch.JSONPatch(doc, []byte(`[{"op":"replace","path":"/stock","value":0},{"op":"test","path":"/name","value":"Té"}]`))
+	if !errors.Is(err, patch.ErrTestFailed) {
+		t.Fatalf("err = %v, se esperaba %v", err, patch.ErrTestFailed)
+	}
+	if got != nil {
+		t.Fatalf("resultado = %s, no se esperaba documento", got)
+	}
+	if string(doc) != original {
+		t.Fatalf("documento original modificado: %s", doc)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	const doc = `{"name":"Café","price":10,"tags":["a","b"],"stock":{"uio":5,"gye":2}}`
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{name: "reemplaza miembro", patch: `{"price":12}`, want: `{"name":"Café","price":12,"tags":["a","b"],"stock":{"uio":5,"gye":2}}`},
+		{name: "null elimina el miembro", patch: `{"price":null}`, want: `{"name":"Café","tags":["a","b"],"stock":{"uio":5,"gye":2}}`},
+		{name: "null anidado", patch: `{"stock":{"gye":null}}`, want: `{"name":"Café","price":10,"tags":["a","b"],"stock":{"uio":5}}`},
+		{name: "null de miembro inexistente", patch: `{"color":null}`, want: doc},
+		{name: "los arreglos se reemplazan completos", patch: `{"tags":["c"]}`, want: `{"name":"Café","price":10,"tags":["c"],"stock":{"uio":5,"gye":2}}`},
+		{name: "objeto nuevo", patch: `{"dims":{"w":1}}`, want: `{"name":"Café","price":10,"tags":["a","b"],"stock":{"uio":5,"gye":2},"dims":{"w":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.MergePatch([]byte(doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyByContentType(t *testing.T) {
+	doc := []byte(`{"name":"Café"}`)
+	tests := []struct {
+		contentType string
+		patch       string
+		want        string
+		err         error
+	}{
+		{contentType: patch.MediaTypeMergePatch, patch: `{"name":"Té"}`, want: `{"name":"Té"}`},
+		{contentType: "application/json; charset=utf-8", patch: `{"name":"Té"}`, want: `{"name":"Té"}`},
+		{contentType: patch.MediaTypeJSONPatch, patch: `[{"op":"replace","path":"/name","value":"Té"}]`, want: `{"name":"Té"}`},
+		{contentType: "text/plain", patch: `{"name":"Té"}`, err: patch.ErrUnsupportedMediaType},
+		{contentType: "", patch: `{"name":"Té"}`, err: patch.ErrUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, err := patch.Apply(tt.contentType, doc, []byte(tt.patch))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, se esperaba %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Paquete para manejo de pagos y pasarelas de pago
+package payments
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de IDs
+	"sync"    // Sincronización de acceso concurrente
+)
+
+// Gateway es una pasarela de pago externa
+type Gateway interface {
+	Authorize(ctx context.Context, method string, amount float64, reference string) (string, error) // Reserva el monto y retorna el ID de transacción
+	Capture(ctx context.Context, transactionID string, amount float64) error                        // Cobra un monto autorizado
+	Void(ctx context.Context, transactionID string) error                                           // Anula una autorización no cobrada
+	Refund(ctx context.Context, transactionID string, amount float64) error                         // Devuelve parte o todo lo cobrado
+}
+
+// Errores que reporta una pasarela
+var (
+	ErrDeclined           = errors.New("payment declined")
+	ErrGatewayTimeout     = errors.New("payment gateway timeout")
+	ErrUnknownTransaction = errors.New("unknown gateway transaction")
+)
+
+// Métodos de pago especiales que reconoce la pasarela simulada
+const (
+	FakeMethodDecline = "tok_decline" // La autorización se rechaza
+	FakeMethodTimeout = "tok_timeout" // La pasarela no responde hasta que vence el contexto
+)
+
+// Estado de una transacción en la pasarela simulada
+type fakeTransaction struct {
+	authorized float64 // Monto autorizado
+	captured   float64 // Monto cobrado
+	refunded   float64 // Monto devuelto
+	voided     bool    // Autorización anulada
+}
+
+// FakeGateway simula una pasarela en el mismo proceso: aprueba todo salvo los métodos tok_decline y tok_timeout
+type FakeGateway struct {
+	mu           sync.Mutex                  // Sincroniza el acceso a las transacciones
+	transactions map[string]*fakeTransaction // Transacciones por ID
+	seq          int                         // Secuencia de IDs
+}
+
+// Constructor para crear una pasarela simulada
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{transactions: make(map[string]*fakeTransaction)}
+}
+
+// Autoriza un monto según el método de pago recibido
+func (g *FakeGateway) Authorize(ctx context.Context, method string, amount float64, reference string) (string, error) {
+	switch method {
+	case FakeMethodDecline:
+		return "", ErrDeclined
+	case FakeMethodTimeout:
+		<-ctx.Done() // La pasarela nunca responde
+		return "", ErrGatewayTimeout
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.seq++
+	id := fmt.Sprintf("fake_tx_%06d", g.seq)
+	g.transactions[id] = &fakeTransaction{authorized: amount}
+	return id, nil
+}
+
+// Cobra un monto autorizado
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string, amount float64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tx, ok := g.transactions[transactionID]
+	if !ok || tx.voided || tx.captured+amount > tx.authorized {
+		return ErrUnknownTransaction
+	}
+	tx.captured += amount
+	return nil
+}
+
+// Anula una autorización que no se ha cobrado
+func (g *FakeGateway) Void(ctx context.Context, transactionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tx, ok := g.transactions[transactionID]
+	if !ok || tx.captured > 0 {
+		return ErrUnknownTransaction
+	}
+	tx.voided = true
+	return nil
+}
+
+// Devuelve parte o todo lo cobrado
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amount float64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tx, ok := g.transactions[transactionID]
+	if !ok || tx.refunded+amount > tx.captured+0.005 {
+		return ErrUnknownTransaction
+	}
+	tx.refunded += amount
+	return nil
+}
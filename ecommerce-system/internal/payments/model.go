@@ -0,0 +1,8 @@
+// Paquete para manejo de pagos y pasarelas de pago
+package payments
+
+// Estructura que representa la solicitud para pagar una orden
+type PaymentRequest struct {
+	Method  string `json:"method"`  // Token del método de pago
+	Capture bool   `json:"capture"` // Cobrar inmediatamente después de autorizar
+}
@@ -0,0 +1,54 @@
+// Paquete para manejo de pagos y pasarelas de pago
+package payments
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+)
+
+// Status representa el estado de un pago
+type Status string
+
+// Constantes que definen los estados de un pago
+const (
+	StatusPending           Status = "pending"            // Registrado, esperando la respuesta de la pasarela
+	StatusAuthorized        Status = "authorized"         // Monto reservado, pendiente de cobro
+	StatusCaptured          Status = "captured"           // Monto cobrado
+	StatusVoided            Status = "voided"             // Autorización anulada
+	StatusFailed            Status = "failed"             // Rechazado o sin respuesta de la pasarela
+	StatusPartiallyRefunded Status = "partially_refunded" // Cobrado con devoluciones parciales
+	StatusRefunded          Status = "refunded"           // Devuelto por completo
+)
+
+// Payment es un pago asociado a una orden
+type Payment struct {
+	ID             string     `json:"id"`                       // ID único del pago
+	OrderID        string     `json:"order_id"`                 // Orden que se paga
+	Method         string     `json:"method"`                   // Token del método de pago
+	Amount         float64    `json:"amount"`                   // Monto autorizado
+	CapturedAmount float64    `json:"captured_amount"`          // Monto cobrado
+	RefundedAmount float64    `json:"refunded_amount"`          // Monto devuelto
+	Status         Status     `json:"status"`                   // Estado actual
+	TransactionID  string     `json:"transaction_id,omitempty"` // ID de la transacción en la pasarela
+	FailureReason  string     `json:"failure_reason,omitempty"` // Motivo del rechazo o falla
+	CreatedAt      time.Time  `json:"created_at"`               // Fecha de creación
+	AuthorizedAt   *time.Time `json:"authorized_at,omitempty"`  // Fecha de autorización
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`    // Fecha de cobro
+	VoidedAt       *time.Time `json:"voided_at,omitempty"`      // Fecha de anulación
+	UpdatedAt      time.Time  `json:"updated_at"`               // Fecha de última actualización
+}
+
+// Método que retorna el monto que aún se puede devolver
+func (p *Payment) Refundable() float64 {
+	return p.CapturedAmount - p.RefundedAmount
+}
+
+// Errores del servicio de pagos
+var (
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrInvalidTransition   = errors.New("operation not allowed in the current payment status")
+	ErrAlreadyPaid         = errors.New("order already has an active payment")
+	ErrInvalidRefund       = errors.New("invalid refund amount")
+	ErrInvalidPaymentData  = errors.New("invalid payment data")
+	ErrOperationInProgress = errors.New("another operation on this payment is in progress")
+)
@@ -0,0 +1,298 @@
+// Paquete para manejo de pagos y pasarelas de pago
+package payments
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de IDs
+	"log"     // Registro de fallas al compensar en la pasarela
+	"math"    // Redondeo de montos
+	"sort"    // Ordenamiento de resultados
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders" // Servicio de órdenes
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de pagos
+type Service interface {
+	Authorize(ctx context.Context, orderID string, req PaymentRequest) (*Payment, error) // Autorizar (y opcionalmente cobrar) el total de una orden
+	Capture(ctx context.Context, paymentID string) (*Payment, error)                     // Cobrar un pago autorizado
+	Void(ctx context.Context, paymentID string) (*Payment, error)                        // Anular un pago autorizado
+	Refund(ctx context.Context, paymentID string, amount float64) (*Payment, error)      // Devolver en la pasarela (la orden lo registra a través de refunds)
+	GetPayment(ctx context.Context, paymentID string) (*Payment, error)                  // Obtener pago por ID
+	ListByOrder(ctx context.Context, orderID string) ([]Payment, error)                  // Pagos de una orden
+}
+
+// Implementación en memoria del repositorio de pagos
+type inMemoryRepository struct {
+	payments map[string]Payment // Pagos indexados por ID
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{payments: make(map[string]Payment)}
+}
+
+// Implementación del servicio de pagos
+type paymentService struct {
+	mu           sync.Mutex          // Serializa los cambios de estado de los pagos
+	repo         *inMemoryRepository // Repositorio interno
+	gateway      Gateway             // Pasarela de pago
+	orderService orders.Service      // Órdenes que se cobran
+	timeout      time.Duration       // Tiempo máximo de espera de la pasarela
+	inFlight     map[string]bool     // Pagos con una llamada a la pasarela en curso
+}
+
+// Option configura parámetros opcionales del servicio de pagos
+type Option func(*paymentService)
+
+// Opción que fija el tiempo máximo de espera de cada llamada a la pasarela
+func WithGatewayTimeout(d time.Duration) Option {
+	return func(s *paymentService) { s.timeout = d }
+}
+
+// Constructor para crear un nuevo servicio de pagos (por defecto espera 10 segundos a la pasarela)
+func NewService(repo *inMemoryRepository, gateway Gateway, ordService orders.Service, opts ...Option) Service {
+	s := &paymentService{repo: repo, gateway: gateway, orderService: ordService, timeout: 10 * time.Second, inFlight: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Autorizar el saldo pendiente de una orden; con Capture se cobra en la misma operación
+func (s *paymentService) Authorize(ctx context.Context, orderID string, req PaymentRequest) (*Payment, error) {
+	if req.Method == "" {
+		return nil, ErrInvalidPaymentData
+	}
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orders.StatusPending {
+		return nil, orders.ErrNotPayable
+	}
+	if order.IsPaid() {
+		return nil, ErrAlreadyPaid
+	}
+
+	s.mu.Lock()
+	for _, p := range s.repo.payments {
+		if p.OrderID == orderID && (p.Status == StatusPending || p.Status == StatusAuthorized || p.Status == StatusCaptured) {
+			s.mu.Unlock()
+			return nil, ErrAlreadyPaid
+		}
+	}
+	now := time.Now()
+	p := Payment{
+		ID:        fmt.Sprintf("PAY-%06d", len(s.repo.payments)+1),
+		OrderID:   orderID,
+		Method:    req.Method,
+		Amount:    order.GrandTotal - order.PaidAmount,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.repo.payments[p.ID] = p // Se registra antes de llamar a la pasarela para que ningún otro pago la duplique
+	s.mu.Unlock()
+
+	gwCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	txID, err := s.gateway.Authorize(gwCtx, req.Method, p.Amount, orderID)
+	cancel()
+
+	s.mu.Lock()
+	now = time.Now()
+	p.UpdatedAt = now
+	if err != nil {
+		p.Status, p.FailureReason = StatusFailed, err.Error()
+		if errors.Is(gwCtx.Err(), context.DeadlineExceeded) {
+			err = ErrGatewayTimeout
+			p.FailureReason = err.Error()
+		}
+		s.repo.payments[p.ID] = p
+		s.mu.Unlock()
+		return &p, err
+	}
+	s.mu.Unlock()
+
+	// La orden guarda la autorización para no editarse ni vencer antes del cobro
+	if _, err := s.orderService.RecordAuthorization(ctx, orderID, p.Amount); err != nil {
+		if voidErr := s.call(ctx, func(gwCtx context.Context) error { return s.gateway.Void(gwCtx, txID) }); voidErr != nil {
+			log.Printf("Autorización %s del pago %s sin anular: %v\n", txID, p.ID, voidErr)
+		}
+		p.Status, p.TransactionID, p.FailureReason = StatusFailed, txID, err.Error()
+		s.save(p)
+		return &p, err
+	}
+	p.Status, p.TransactionID, p.AuthorizedAt = StatusAuthorized, txID, &now
+	s.save(p)
+
+	if req.Capture {
+		return s.Capture(ctx, p.ID)
+	}
+	return &p, nil
+}
+
+// Cobrar un pago autorizado; la orden pasa a Procesado cuando queda pagada.
+// Si la orden ya no admite el cobro no se llama a la pasarela; si deja de admitirlo durante la llamada
+// (por ejemplo, la canceló la tarea de órdenes vencidas) se devuelve lo cobrado en la pasarela
+func (s *paymentService) Capture(ctx context.Context, paymentID string) (*Payment, error) {
+	p, err := s.begin(paymentID, StatusAuthorized)
+	if err != nil {
+		return nil, err
+	}
+	defer s.finish(p.ID)
+	order, err := s.orderService.GetOrderByID(ctx, p.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orders.StatusPending {
+		return nil, orders.ErrNotPayable
+	}
+	if err := s.call(ctx, func(gwCtx context.Context) error { return s.gateway.Capture(gwCtx, p.TransactionID, p.Amount) }); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p.Status, p.CapturedAmount, p.CapturedAt, p.UpdatedAt = StatusCaptured, p.Amount, &now, now
+	_, recordErr := s.orderService.RecordPayment(ctx, p.OrderID, p.CapturedAmount)
+	s.releaseAuthorization(ctx, p)
+	if recordErr != nil {
+		// La orden no recibió el cobro: se devuelve para no cobrar algo que no se acreditó
+		p.FailureReason = "order not credited: " + recordErr.Error()
+		if err := s.call(ctx, func(gwCtx context.Context) error { return s.gateway.Refund(gwCtx, p.TransactionID, p.CapturedAmount) }); err != nil {
+			log.Printf("Pago %s cobrado sin acreditar en la orden %s; reembolso pendiente: %v\n", p.ID, p.OrderID, err)
+		} else {
+			p.Status, p.RefundedAmount = StatusRefunded, p.CapturedAmount
+		}
+		s.save(p)
+		return &p, recordErr
+	}
+	s.save(p)
+	return &p, nil
+}
+
+// Anular un pago autorizado que no se ha cobrado
+func (s *paymentService) Void(ctx context.Context, paymentID string) (*Payment, error) {
+	p, err := s.begin(paymentID, StatusAuthorized)
+	if err != nil {
+		return nil, err
+	}
+	defer s.finish(p.ID)
+	if err := s.call(ctx, func(gwCtx context.Context) error { return s.gateway.Void(gwCtx, p.TransactionID) }); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p.Status, p.VoidedAt, p.UpdatedAt = StatusVoided, &now, now
+	s.releaseAuthorization(ctx, p)
+	s.save(p)
+	return &p, nil
+}
+
+// Devolver parte o todo lo cobrado de un pago
+func (s *paymentService) Refund(ctx context.Context, paymentID string, amount float64) (*Payment, error) {
+	p, err := s.begin(paymentID, StatusCaptured, StatusPartiallyRefunded)
+	if err != nil {
+		return nil, err
+	}
+	defer s.finish(p.ID)
+	if amount <= 0 || amount > p.Refundable()+0.005 {
+		return nil, ErrInvalidRefund
+	}
+	if err := s.call(ctx, func(gwCtx context.Context) error { return s.gateway.Refund(gwCtx, p.TransactionID, amount) }); err != nil {
+		return nil, err
+	}
+	p.RefundedAmount = roundCents(p.RefundedAmount + amount)
+	p.Status = StatusPartiallyRefunded
+	if p.Refundable() < 0.005 {
+		p.Status = StatusRefunded
+	}
+	p.UpdatedAt = time.Now()
+	s.save(p)
+	return &p, nil
+}
+
+// Reserva un pago en alguno de los estados indicados para operar sobre él en la pasarela sin mantener el
+// bloqueo durante la llamada; otra operación sobre el mismo pago falla con ErrOperationInProgress hasta finish
+func (s *paymentService) begin(paymentID string, allowed ...Status) (Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo.payments[paymentID]
+	if !ok {
+		return p, ErrPaymentNotFound
+	}
+	if s.inFlight[paymentID] {
+		return p, ErrOperationInProgress
+	}
+	for _, status := range allowed {
+		if p.Status == status {
+			s.inFlight[paymentID] = true
+			return p, nil
+		}
+	}
+	return p, ErrInvalidTransition
+}
+
+// Libera un pago reservado con begin
+func (s *paymentService) finish(paymentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, paymentID)
+}
+
+// Quita de la orden la autorización del pago una vez cobrado o anulado
+func (s *paymentService) releaseAuthorization(ctx context.Context, p Payment) {
+	if _, err := s.orderService.RecordAuthorization(ctx, p.OrderID, -p.Amount); err != nil {
+		log.Printf("No se pudo liberar la autorización del pago %s en la orden %s: %v\n", p.ID, p.OrderID, err)
+	}
+}
+
+// Guarda el nuevo estado de un pago
+func (s *paymentService) save(p Payment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo.payments[p.ID] = p
+}
+
+// Obtener un pago por su ID
+func (s *paymentService) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	return &p, nil
+}
+
+// Listar los pagos de una orden en orden de creación
+func (s *paymentService) ListByOrder(ctx context.Context, orderID string) ([]Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Payment{}
+	for _, p := range s.repo.payments {
+		if p.OrderID == orderID {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// Llama a la pasarela con el tiempo máximo configurado
+func (s *paymentService) call(ctx context.Context, fn func(context.Context) error) error {
+	gwCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	if err := fn(gwCtx); err != nil {
+		if errors.Is(gwCtx.Err(), context.DeadlineExceeded) {
+			return ErrGatewayTimeout
+		}
+		return err
+	}
+	return nil
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
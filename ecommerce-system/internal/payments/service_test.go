@@ -0,0 +1,221 @@
+// Pruebas del servicio de pagos con la pasarela simulada
+package payments_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"sync"    // Llamadas concurrentes
+	"testing" // Paquete de pruebas
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// Pasarela simulada que retiene las autorizaciones hasta que se cierre release
+type slowGateway struct {
+	*payments.FakeGateway
+	release chan struct{}
+}
+
+func (g *slowGateway) Authorize(ctx context.Context, method string, amount float64, reference string) (string, error) {
+	<-g.release
+	return g.FakeGateway.Authorize(ctx, method, amount, reference)
+}
+
+// Crea una orden pendiente de 2 unidades a 10 y el servicio de órdenes que la guarda
+func newOrder(t *testing.T) (orders.Service, *orders.Order) {
+	t.Helper()
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	orderService := orders.NewService(orders.NewInMemoryRepository(), productService)
+	order, err := orderService.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 2}},
+		ShippingAddress: &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return orderService, order
+}
+
+func TestConcurrentAuthorizeChargesOnce(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	gateway := &slowGateway{FakeGateway: payments.NewFakeGateway(), release: make(chan struct{})}
+	svc := payments.NewService(payments.NewInMemoryRepository(), gateway, orderService)
+
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true})
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond) // Ambas solicitudes llegan mientras la pasarela no responde
+	close(gateway.release)
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, payments.ErrAlreadyPaid):
+			t.Fatalf("error inesperado: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("pagos exitosos = %d, se esperaba 1", succeeded)
+	}
+	got, _ := orderService.GetOrderByID(ctx, order.ID)
+	if got.PaidAmount != order.GrandTotal || got.AuthorizedAmount != 0 {
+		t.Fatalf("pagado %.2f y autorizado %.2f, se esperaba pagado %.2f una sola vez", got.PaidAmount, got.AuthorizedAmount, order.GrandTotal)
+	}
+}
+
+func TestRecordAuthorizationRejectsSecondAuthorization(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	if _, err := orderService.RecordAuthorization(ctx, order.ID, order.GrandTotal); err != nil {
+		t.Fatalf("RecordAuthorization: %v", err)
+	}
+	if _, err := orderService.RecordAuthorization(ctx, order.ID, order.GrandTotal); !errors.Is(err, orders.ErrNotPayable) {
+		t.Fatalf("segunda autorización: err = %v, se esperaba %v", err, orders.ErrNotPayable)
+	}
+	// Al liberar la primera se puede volver a autorizar
+	if _, err := orderService.RecordAuthorization(ctx, order.ID, -order.GrandTotal); err != nil {
+		t.Fatalf("liberar autorización: %v", err)
+	}
+	if _, err := orderService.RecordAuthorization(ctx, order.ID, order.GrandTotal); err != nil {
+		t.Fatalf("autorizar tras liberar: %v", err)
+	}
+}
+
+func TestAuthorizeDeclined(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	svc := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService)
+
+	p, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: payments.FakeMethodDecline})
+	if !errors.Is(err, payments.ErrDeclined) {
+		t.Fatalf("err = %v, se esperaba %v", err, payments.ErrDeclined)
+	}
+	if p.Status != payments.StatusFailed || p.FailureReason == "" {
+		t.Fatalf("pago %s (%q), se esperaba %s con motivo", p.Status, p.FailureReason, payments.StatusFailed)
+	}
+	if got, _ := orderService.GetOrderByID(ctx, order.ID); got.AuthorizedAmount != 0 || got.PaidAmount != 0 {
+		t.Fatal("un pago rechazado quedó registrado en la orden")
+	}
+	// Un pago fallido no impide intentar con otro método
+	if _, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa"}); err != nil {
+		t.Fatalf("Authorize tras el rechazo: %v", err)
+	}
+}
+
+func TestAuthorizeTimeout(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	svc := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService, payments.WithGatewayTimeout(20*time.Millisecond))
+
+	p, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: payments.FakeMethodTimeout})
+	if !errors.Is(err, payments.ErrGatewayTimeout) {
+		t.Fatalf("err = %v, se esperaba %v", err, payments.ErrGatewayTimeout)
+	}
+	if p.Status != payments.StatusFailed {
+		t.Fatalf("estado = %s, se esperaba %s", p.Status, payments.StatusFailed)
+	}
+	if got, _ := orderService.GetOrderByID(ctx, order.ID); got.AuthorizedAmount != 0 {
+		t.Fatal("un pago sin respuesta quedó autorizado en la orden")
+	}
+}
+
+func TestAuthorizeThenCapture(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	svc := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService)
+
+	p, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if got, _ := orderService.GetOrderByID(ctx, order.ID); got.AuthorizedAmount != order.GrandTotal || got.PaidAmount != 0 {
+		t.Fatalf("autorizado %.2f y pagado %.2f, se esperaba solo la autorización de %.2f", got.AuthorizedAmount, got.PaidAmount, order.GrandTotal)
+	}
+
+	captured, err := svc.Capture(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if captured.Status != payments.StatusCaptured || captured.CapturedAmount != order.GrandTotal {
+		t.Fatalf("pago %s con %.2f cobrado, se esperaba %s con %.2f", captured.Status, captured.CapturedAmount, payments.StatusCaptured, order.GrandTotal)
+	}
+	got, _ := orderService.GetOrderByID(ctx, order.ID)
+	if got.Status != orders.StatusProcessed || got.PaidAmount != order.GrandTotal || got.AuthorizedAmount != 0 {
+		t.Fatalf("orden %s con pagado %.2f y autorizado %.2f", got.Status, got.PaidAmount, got.AuthorizedAmount)
+	}
+	if _, err := svc.Capture(ctx, p.ID); !errors.Is(err, payments.ErrInvalidTransition) {
+		t.Fatalf("segundo cobro: err = %v, se esperaba %v", err, payments.ErrInvalidTransition)
+	}
+	if _, err := svc.Void(ctx, p.ID); !errors.Is(err, payments.ErrInvalidTransition) {
+		t.Fatalf("anular un pago cobrado: err = %v, se esperaba %v", err, payments.ErrInvalidTransition)
+	}
+}
+
+func TestVoidReleasesAuthorization(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	svc := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService)
+
+	p, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	voided, err := svc.Void(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	if voided.Status != payments.StatusVoided || voided.VoidedAt == nil {
+		t.Fatalf("estado = %s, se esperaba %s con fecha", voided.Status, payments.StatusVoided)
+	}
+	got, _ := orderService.GetOrderByID(ctx, order.ID)
+	if got.AuthorizedAmount != 0 || got.Status != orders.StatusPending {
+		t.Fatalf("orden %s con %.2f autorizado, se esperaba %s sin autorización", got.Status, got.AuthorizedAmount, orders.StatusPending)
+	}
+	if _, err := svc.Capture(ctx, p.ID); !errors.Is(err, payments.ErrInvalidTransition) {
+		t.Fatalf("cobrar un pago anulado: err = %v, se esperaba %v", err, payments.ErrInvalidTransition)
+	}
+	// Anulada la autorización, la orden se puede volver a pagar
+	if _, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true}); err != nil {
+		t.Fatalf("Authorize tras anular: %v", err)
+	}
+}
+
+func TestRefundPartialThenFull(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t)
+	svc := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService)
+
+	p, err := svc.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if p, err = svc.Refund(ctx, p.ID, 5); err != nil || p.Status != payments.StatusPartiallyRefunded {
+		t.Fatalf("Refund parcial: %v (estado %s)", err, p.Status)
+	}
+	if _, err := svc.Refund(ctx, p.ID, p.Refundable()+1); !errors.Is(err, payments.ErrInvalidRefund) {
+		t.Fatalf("devolver de más: err = %v, se esperaba %v", err, payments.ErrInvalidRefund)
+	}
+	if p, err = svc.Refund(ctx, p.ID, p.Refundable()); err != nil || p.Status != payments.StatusRefunded {
+		t.Fatalf("Refund del resto: %v (estado %s)", err, p.Status)
+	}
+}
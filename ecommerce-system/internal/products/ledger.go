@@ -0,0 +1,125 @@
+// Paquete para manejo de productos
+package products
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de IDs
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+)
+
+// MovementReason representa el motivo de un movimiento de inventario
+type MovementReason string
+
+// Constantes que definen los motivos de un movimiento de inventario
+const (
+	ReasonSale             MovementReason = "sale"              // Venta (salida por una orden)
+	ReasonRestock          MovementReason = "restock"           // Reposición de mercadería
+	ReasonReturn           MovementReason = "return"            // Devolución de un cliente
+	ReasonShrinkage        MovementReason = "shrinkage"         // Merma: robo, daño o pérdida
+	ReasonManualCorrection MovementReason = "manual_correction" // Corrección manual de inventario
+	ReasonCancellation     MovementReason = "cancellation"      // Liberación de stock de una orden cancelada
+	ReasonOrderEdit        MovementReason = "order_edit"        // Ajuste por la edición de una orden pendiente
+)
+
+// Método que indica si el motivo es uno de los reconocidos
+func (r MovementReason) IsValid() bool {
+	switch r {
+	case ReasonSale, ReasonRestock, ReasonReturn, ReasonShrinkage, ReasonManualCorrection, ReasonCancellation, ReasonOrderEdit:
+		return true
+	}
+	return false
+}
+
+// Método que indica si el motivo puede registrarse a mano; el resto lo generan las órdenes y devoluciones
+func (r MovementReason) IsManual() bool {
+	switch r {
+	case ReasonRestock, ReasonShrinkage, ReasonManualCorrection:
+		return true
+	}
+	return false
+}
+
+// StockMovement es una entrada inmutable del libro de inventario
+type StockMovement struct {
+	ID           string         `json:"id"`                     // ID único del movimiento
+	ProductID    string         `json:"product_id"`             // Producto afectado
+	Delta        int            `json:"delta"`                  // Cambio de stock (negativo para salidas)
+	Reason       MovementReason `json:"reason"`                 // Motivo del movimiento
+	Actor        string         `json:"actor"`                  // Quién realizó el movimiento (usuario o sistema)
+	ReferenceID  string         `json:"reference_id,omitempty"` // Documento relacionado (ID de orden, guía de recepción, etc.)
+	BalanceAfter int            `json:"balance_after"`          // Stock resultante tras aplicar el movimiento
+	CreatedAt    time.Time      `json:"created_at"`             // Fecha del movimiento
+}
+
+// StockAdjustment describe un cambio de stock solicitado al servicio de productos
+type StockAdjustment struct {
+	Delta       int            `json:"delta"`        // Cambio de stock (negativo para salidas)
+	Reason      MovementReason `json:"reason"`       // Motivo del movimiento
+	Actor       string         `json:"actor"`        // Quién solicita el movimiento
+	ReferenceID string         `json:"reference_id"` // Documento relacionado
+}
+
+// Actor usado cuando el movimiento no indica quién lo realizó
+const SystemActor = "system"
+
+// Error que indica que el ajuste de stock no es válido
+var ErrInvalidStockAdjustment = errors.New("invalid stock adjustment")
+
+// Ledger es el libro de inventario de solo anexado; el stock actual es la suma de sus movimientos
+type Ledger interface {
+	Append(ctx context.Context, m StockMovement) (StockMovement, error)           // Registrar un movimiento
+	ListByProduct(ctx context.Context, productID string) ([]StockMovement, error) // Historial de un producto en orden cronológico
+	Balance(ctx context.Context, productID string) (int, error)                   // Stock derivado de los movimientos
+}
+
+// Implementación en memoria del libro de inventario
+type inMemoryLedger struct {
+	mu        sync.RWMutex     // Mutex para sincronizar acceso concurrente
+	entries   []StockMovement  // Movimientos en orden de registro
+	byProduct map[string][]int // Índices de movimientos por producto
+	balances  map[string]int   // Saldo acumulado por producto
+}
+
+// Constructor para crear un nuevo libro de inventario en memoria
+func NewInMemoryLedger() *inMemoryLedger {
+	return &inMemoryLedger{byProduct: make(map[string][]int), balances: make(map[string]int)}
+}
+
+// Registra un movimiento asignándole ID, saldo resultante y fecha
+func (l *inMemoryLedger) Append(ctx context.Context, m StockMovement) (StockMovement, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if m.Actor == "" {
+		m.Actor = SystemActor
+	}
+	m.ID = fmt.Sprintf("MOV-%06d", len(l.entries)+1) // IDs secuenciales
+	m.BalanceAfter = l.balances[m.ProductID] + m.Delta
+	m.CreatedAt = time.Now()
+	l.balances[m.ProductID] = m.BalanceAfter
+	l.byProduct[m.ProductID] = append(l.byProduct[m.ProductID], len(l.entries))
+	l.entries = append(l.entries, m)
+	return m, nil
+}
+
+// Retorna los movimientos de un producto en orden cronológico
+func (l *inMemoryLedger) ListByProduct(ctx context.Context, productID string) ([]StockMovement, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	movements := make([]StockMovement, 0, len(l.byProduct[productID]))
+	for _, i := range l.byProduct[productID] {
+		movements = append(movements, l.entries[i])
+	}
+	return movements, nil
+}
+
+// Retorna el stock de un producto derivado de sus movimientos
+func (l *inMemoryLedger) Balance(ctx context.Context, productID string) (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.balances[productID], nil
+}
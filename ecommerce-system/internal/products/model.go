@@ -3,9 +3,13 @@ package products
 
 // Estructura que representa la solicitud para crear o actualizar un producto
 type ProductRequest struct {
-	Name        string  `json:"name"`        // Nombre del producto
-	Description string  `json:"description"` // Descripción del producto
-	Price       float64 `json:"price"`       // Precio del producto
-	Stock       int     `json:"stock"`       // Cantidad disponible en inventario
-	Category    string  `json:"category"`    // Categoría a la que pertenece el producto
+	Name             string     `json:"name"`              // Nombre del producto
+	SKU              string     `json:"sku"`               // Código de referencia (SKU) del producto
+	Description      string     `json:"description"`       // Descripción del producto
+	Price            float64    `json:"price"`             // Precio del producto
+	Stock            int        `json:"stock"`             // Cantidad disponible en inventario
+	Category         string     `json:"category"`          // Categoría a la que pertenece el producto
+	ReorderThreshold int        `json:"reorder_threshold"` // Umbral de reposición (opcional)
+	WeightKg         float64    `json:"weight_kg"`         // Peso unitario en kg (opcional)
+	Dimensions       Dimensions `json:"dimensions"`        // Medidas unitarias del empaque (opcional)
 }
@@ -9,14 +9,26 @@ import (
 
 // Estructura que representa un producto
 type Product struct {
-	ID          string    `json:"id"`          // ID único del producto
-	Name        string    `json:"name"`        // Nombre del producto
-	Description string    `json:"description"` // Descripción detallada
-	Price       float64   `json:"price"`       // Precio unitario
-	Stock       int       `json:"stock"`       // Cantidad disponible en inventario
-	Category    string    `json:"category"`    // Categoría del producto
-	CreatedAt   time.Time `json:"created_at"`  // Fecha de creación
-	UpdatedAt   time.Time `json:"updated_at"`  // Fecha de última actualización
+	ID               string     `json:"id"`                // ID único del producto
+	Name             string     `json:"name"`              // Nombre del producto
+	SKU              string     `json:"sku"`               // Código de referencia (SKU) del producto
+	Description      string     `json:"description"`       // Descripción detallada
+	Price            float64    `json:"price"`             // Precio unitario
+	Stock            int        `json:"stock"`             // Cantidad disponible en inventario
+	Category         string     `json:"category"`          // Categoría del producto
+	ReorderThreshold int        `json:"reorder_threshold"` // Umbral de reposición: se alerta al llegar a este stock (0 desactiva)
+	WeightKg         float64    `json:"weight_kg"`         // Peso unitario en kg (para el costo de envío)
+	Dimensions       Dimensions `json:"dimensions"`        // Medidas unitarias del empaque
+	Version          int        `json:"version"`           // Versión para control de concurrencia optimista
+	CreatedAt        time.Time  `json:"created_at"`        // Fecha de creación
+	UpdatedAt        time.Time  `json:"updated_at"`        // Fecha de última actualización
+}
+
+// Dimensions son las medidas del empaque en centímetros
+type Dimensions struct {
+	LengthCm float64 `json:"length_cm"` // Largo
+	WidthCm  float64 `json:"width_cm"`  // Ancho
+	HeightCm float64 `json:"height_cm"` // Alto
 }
 
 // Constructor para crear un nuevo producto inicializando fechas
@@ -24,10 +36,23 @@ func NewProduct(id, name, description string, price float64, stock int, category
 	now := time.Now()
 	return Product{
 		ID: id, Name: name, Description: description, Price: price, Stock: stock, Category: category,
-		CreatedAt: now, UpdatedAt: now,
+		Version: 1, CreatedAt: now, UpdatedAt: now,
+	}
+}
+
+// Método que convierte el producto en una solicitud editable (base para actualizaciones parciales)
+func (p *Product) ToRequest() ProductRequest {
+	return ProductRequest{
+		Name: p.Name, SKU: p.SKU, Description: p.Description, Price: p.Price, Stock: p.Stock, Category: p.Category,
+		ReorderThreshold: p.ReorderThreshold, WeightKg: p.WeightKg, Dimensions: p.Dimensions,
 	}
 }
 
+// Método que indica si el stock llegó al umbral de reposición
+func (p *Product) IsLowStock() bool {
+	return p.ReorderThreshold > 0 && p.Stock <= p.ReorderThreshold
+}
+
 // Método para obtener el precio del producto incluyendo el IVA (impuesto)
 func (p *Product) GetPrecioConIVA(ivaRate float64) float64 {
 	return p.Price * (1 + ivaRate)
@@ -37,10 +62,19 @@ func (p *Product) GetPrecioConIVA(ivaRate float64) float64 {
 type Repository interface {
 	Save(ctx context.Context, product Product) error                // Guardar un producto nuevo
 	GetByID(ctx context.Context, id string) (*Product, error)       // Obtener un producto por ID
-	Update(ctx context.Context, product Product) error              // Actualizar un producto
+	Update(ctx context.Context, product Product) error              // Actualizar un producto (falla si la versión no coincide)
 	UpdateStock(ctx context.Context, id string, quantity int) error // Actualizar stock de un producto
 	GetAll(ctx context.Context) ([]Product, error)                  // Obtener todos los productos
 }
 
 // Error que indica que el stock es insuficiente para una operación
 var ErrorStockInsuficiente = errors.New("stock insuficiente")
+
+// Error que indica que los datos del producto no son válidos
+var ErrInvalidProductData = errors.New("invalid product data")
+
+// Error que indica que el producto no existe
+var ErrProductNotFound = errors.New("product not found")
+
+// Error que indica que el producto fue modificado por otra operación (versión desactualizada)
+var ErrVersionConflict = errors.New("product version conflict")
@@ -3,21 +3,26 @@ package products
 
 import (
 	"context" // Manejo de contexto en funciones
-	"errors"  // Manejo de errores
+	"sort"    // Ordenamiento de resultados
+	"sync"    // Sincronización de acceso concurrente
 	"time"    // Manejo de tiempos y fechas
 )
 
 // Interfaz que define las operaciones disponibles en el servicio de productos
 type Service interface {
-	CreateProduct(ctx context.Context, name, description string, price float64, stock int, category string) (*Product, error)     // Crear producto
-	ListProducts(ctx context.Context) ([]Product, error)                                                                          // Listar productos
-	GetProductByID(ctx context.Context, id string) (*Product, error)                                                              // Obtener producto por ID
-	UpdateProduct(ctx context.Context, id, name, description string, price float64, stock int, category string) (*Product, error) // Actualizar producto
-	DeleteProduct(ctx context.Context, id string) error                                                                           // Eliminar producto
+	CreateProduct(ctx context.Context, req ProductRequest) (*Product, error)                         // Crear producto
+	ListProducts(ctx context.Context) ([]Product, error)                                             // Listar productos
+	GetProductByID(ctx context.Context, id string) (*Product, error)                                 // Obtener producto por ID
+	UpdateProduct(ctx context.Context, id string, version int, req ProductRequest) (*Product, error) // Actualizar producto si la versión coincide
+	DeleteProduct(ctx context.Context, id string, version int) error                                 // Eliminar producto si la versión coincide
+	AdjustStock(ctx context.Context, id string, adj StockAdjustment) (*Product, error)               // Cambiar el stock registrando el movimiento
+	ListStockMovements(ctx context.Context, id string) ([]StockMovement, error)                      // Historial de movimientos de stock
+	ListLowStock(ctx context.Context) ([]Product, error)                                             // Productos en o bajo su umbral de reposición
 }
 
 // Implementación en memoria del repositorio de productos
 type inMemoryRepository struct {
+	mu   sync.RWMutex       // Mutex para sincronizar acceso concurrente
 	data map[string]Product // Mapa que almacena productos indexados por ID
 }
 
@@ -26,35 +31,86 @@ func NewInMemoryRepository() *inMemoryRepository {
 	return &inMemoryRepository{data: make(map[string]Product)} // Inicializa mapa vacío
 }
 
-// Guarda un producto en el repositorio (inserta o actualiza)
+// Guarda un producto nuevo en el repositorio con la versión inicial
 func (r *inMemoryRepository) Save(ctx context.Context, p Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.Version = 1 // Todo producto nuevo empieza en la versión 1
 	r.data[p.ID] = p
 	return nil
 }
 
 // Obtiene un producto por ID, error si no existe
 func (r *inMemoryRepository) GetByID(ctx context.Context, id string) (*Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	p, ok := r.data[id]
 	if !ok {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
 	return &p, nil
 }
 
-// Actualiza un producto existente
+// Actualiza un producto existente solo si su versión coincide con la almacenada
 func (r *inMemoryRepository) Update(ctx context.Context, p Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.data[p.ID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	if current.Version != p.Version {
+		return ErrVersionConflict // Otro cliente modificó el producto antes
+	}
+	p.Version++ // Nueva versión tras la escritura
 	r.data[p.ID] = p
 	return nil
 }
 
-// Elimina un producto por ID
-func (r *inMemoryRepository) Delete(ctx context.Context, id string) error {
+// Elimina un producto por ID solo si su versión coincide con la almacenada
+func (r *inMemoryRepository) Delete(ctx context.Context, id string, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.data[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	if current.Version != version {
+		return ErrVersionConflict
+	}
 	delete(r.data, id)
 	return nil
 }
 
+// Actualiza el stock de un producto sumando quantityChange (puede ser negativo)
+func (r *inMemoryRepository) UpdateStock(ctx context.Context, id string, quantityChange int) (*Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.data[id]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	newStock := p.Stock + quantityChange
+	if newStock < 0 {
+		return nil, ErrorStockInsuficiente // Validación de stock insuficiente
+	}
+	p.Stock = newStock
+	p.Version++ // El cambio de stock también es una nueva versión
+	p.UpdatedAt = time.Now()
+	r.data[id] = p
+	return &p, nil
+}
+
 // Retorna todos los productos almacenados
 func (r *inMemoryRepository) GetAll(ctx context.Context) ([]Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	products := make([]Product, 0, len(r.data))
 	for _, p := range r.data {
 		products = append(products, p)
@@ -64,31 +120,63 @@ func (r *inMemoryRepository) GetAll(ctx context.Context) ([]Product, error) {
 
 // Implementación del servicio de productos que usa un repositorio
 type productService struct {
-	repo *inMemoryRepository // Repositorio interno
+	repo      *inMemoryRepository // Repositorio interno
+	ledger    Ledger              // Libro de movimientos de inventario
+	stockMu   sync.Mutex          // Serializa los cambios de stock para mantener el libro y el producto alineados
+	observers []StockObserver     // Interesados en los cambios de stock
+}
+
+// StockObserver recibe el producto actualizado cada vez que cambia su stock; no debe bloquear
+type StockObserver interface {
+	StockChanged(p Product)
+}
+
+// Option configura dependencias opcionales del servicio de productos
+type Option func(*productService)
+
+// Opción que registra un observador de cambios de stock
+func WithStockObserver(observer StockObserver) Option {
+	return func(s *productService) { s.observers = append(s.observers, observer) }
 }
 
 // Constructor para crear un nuevo servicio de productos
-func NewService(repo *inMemoryRepository) Service {
-	return &productService{repo: repo}
+func NewService(repo *inMemoryRepository, ledger Ledger, opts ...Option) Service {
+	s := &productService{repo: repo, ledger: ledger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Crear un producto nuevo validando datos básicos
-func (s *productService) CreateProduct(ctx context.Context, name, description string, price float64, stock int, category string) (*Product, error) {
-	if name == "" || price <= 0 || stock < 0 {
-		return nil, errors.New("invalid product data") // Validación de campos obligatorios
+func (s *productService) CreateProduct(ctx context.Context, req ProductRequest) (*Product, error) {
+	if err := validateProductRequest(req); err != nil {
+		return nil, err
 	}
 	id := time.Now().Format("20060102150405.000000") // Generar ID basado en timestamp
 	p := Product{
-		ID:          id,
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Stock:       stock,
-		Category:    category,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:               id,
+		Name:             req.Name,
+		SKU:              req.SKU,
+		Description:      req.Description,
+		Price:            req.Price,
+		Stock:            req.Stock,
+		Category:         req.Category,
+		ReorderThreshold: req.ReorderThreshold,
+		WeightKg:         req.WeightKg,
+		Dimensions:       req.Dimensions,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
+	s.stockMu.Lock()
+	defer s.stockMu.Unlock()
 	s.repo.Save(ctx, p) // Guardar producto
+	p.Version = 1       // Versión inicial asignada por el repositorio
+	if p.Stock > 0 {
+		// El stock inicial también queda registrado en el libro
+		s.ledger.Append(ctx, StockMovement{ProductID: id, Delta: p.Stock, Reason: ReasonRestock, ReferenceID: "initial"})
+	}
+	s.notifyStockChanged(p)
 	return &p, nil
 }
 
@@ -102,23 +190,104 @@ func (s *productService) GetProductByID(ctx context.Context, id string) (*Produc
 	return s.repo.GetByID(ctx, id)
 }
 
-// Actualizar un producto existente con nuevos datos
-func (s *productService) UpdateProduct(ctx context.Context, id, name, description string, price float64, stock int, category string) (*Product, error) {
+// Actualizar un producto existente con nuevos datos; version es la versión que el cliente leyó
+func (s *productService) UpdateProduct(ctx context.Context, id string, version int, req ProductRequest) (*Product, error) {
+	if err := validateProductRequest(req); err != nil {
+		return nil, err // Mismas reglas que al crear el producto
+	}
+	s.stockMu.Lock()
+	defer s.stockMu.Unlock()
 	p, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	p.Name = name
-	p.Description = description
-	p.Price = price
-	p.Stock = stock
-	p.Category = category
+	delta := req.Stock - p.Stock // Un cambio de stock se registra como corrección manual
+	p.Version = version          // El repositorio rechaza la escritura si la versión ya cambió
+	p.Name = req.Name
+	p.SKU = req.SKU
+	p.Description = req.Description
+	p.Price = req.Price
+	p.Stock = req.Stock
+	p.Category = req.Category
+	p.ReorderThreshold = req.ReorderThreshold
+	p.WeightKg = req.WeightKg
+	p.Dimensions = req.Dimensions
 	p.UpdatedAt = time.Now() // Actualizar timestamp
-	s.repo.Update(ctx, *p)   // Guardar cambios
+	if err := s.repo.Update(ctx, *p); err != nil {
+		return nil, err
+	}
+	p.Version++ // Reflejar la versión asignada por el repositorio
+	if delta != 0 {
+		s.ledger.Append(ctx, StockMovement{ProductID: id, Delta: delta, Reason: ReasonManualCorrection})
+	}
+	s.notifyStockChanged(*p) // El umbral también pudo cambiar
+	return p, nil
+}
+
+// Eliminar un producto por su ID si la versión coincide
+func (s *productService) DeleteProduct(ctx context.Context, id string, version int) error {
+	return s.repo.Delete(ctx, id, version)
+}
+
+// Cambiar el stock de un producto; el movimiento queda registrado en el libro de inventario
+func (s *productService) AdjustStock(ctx context.Context, id string, adj StockAdjustment) (*Product, error) {
+	if adj.Delta == 0 || !adj.Reason.IsValid() {
+		return nil, ErrInvalidStockAdjustment
+	}
+	s.stockMu.Lock()
+	defer s.stockMu.Unlock()
+	p, err := s.repo.UpdateStock(ctx, id, adj.Delta) // Falla si el stock quedaría negativo
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.ledger.Append(ctx, StockMovement{
+		ProductID:   id,
+		Delta:       adj.Delta,
+		Reason:      adj.Reason,
+		Actor:       adj.Actor,
+		ReferenceID: adj.ReferenceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStockChanged(*p)
 	return p, nil
 }
 
-// Eliminar un producto por su ID
-func (s *productService) DeleteProduct(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// Listar los productos cuyo stock llegó a su umbral de reposición
+func (s *productService) ListLowStock(ctx context.Context) ([]Product, error) {
+	all, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	low := []Product{}
+	for _, p := range all {
+		if p.IsLowStock() {
+			low = append(low, p)
+		}
+	}
+	sort.Slice(low, func(i, j int) bool { return low[i].ID < low[j].ID })
+	return low, nil
+}
+
+// Avisa a los observadores que cambió el stock de un producto
+func (s *productService) notifyStockChanged(p Product) {
+	for _, o := range s.observers {
+		o.StockChanged(p)
+	}
+}
+
+// Obtener el historial de movimientos de stock de un producto
+func (s *productService) ListStockMovements(ctx context.Context, id string) ([]StockMovement, error) {
+	return s.ledger.ListByProduct(ctx, id)
+}
+
+// Valida los datos de un producto; se usa tanto al crear como al actualizar
+func validateProductRequest(req ProductRequest) error {
+	d := req.Dimensions
+	if req.Name == "" || req.Price <= 0 || req.Stock < 0 || req.ReorderThreshold < 0 ||
+		req.WeightKg < 0 || d.LengthCm < 0 || d.WidthCm < 0 || d.HeightCm < 0 {
+		return ErrInvalidProductData // Validación de campos obligatorios
+	}
+	return nil
 }
@@ -0,0 +1,23 @@
+// Paquete para manejo de promociones automáticas basadas en reglas
+package promotions
+
+// Estructura que representa la solicitud para crear o reemplazar una regla de promoción
+type RuleRequest struct {
+	ID            string   `json:"id,omitempty"`              // ID opcional (se genera si falta)
+	Name          string   `json:"name"`                      // Nombre visible en la orden
+	Type          RuleType `json:"type"`                      // buy_x_get_y, spend_threshold, free_gift o bundle
+	Priority      int      `json:"priority"`                  // Mayor prioridad se evalúa primero
+	Exclusive     bool     `json:"exclusive"`                 // Detiene las reglas siguientes si se aplica
+	Disabled      bool     `json:"disabled"`                  // Regla desactivada
+	Categories    []string `json:"categories,omitempty"`      // Categorías aplicables
+	ProductIDs    []string `json:"product_ids,omitempty"`     // Productos aplicables
+	BuyQuantity   int      `json:"buy_quantity,omitempty"`    // Unidades que se pagan
+	FreeQuantity  int      `json:"free_quantity,omitempty"`   // Unidades gratis por grupo
+	MinSubtotal   float64  `json:"min_subtotal,omitempty"`    // Monto mínimo
+	PercentOff    float64  `json:"percent_off,omitempty"`     // Porcentaje de descuento
+	AmountOff     float64  `json:"amount_off,omitempty"`      // Monto fijo de descuento
+	Tiers         []Tier   `json:"tiers,omitempty"`           // Escalones de descuento por monto
+	BundlePrice   float64  `json:"bundle_price,omitempty"`    // Precio del combo
+	GiftProductID string   `json:"gift_product_id,omitempty"` // Producto de regalo
+	GiftQuantity  int      `json:"gift_quantity,omitempty"`   // Unidades de regalo
+}
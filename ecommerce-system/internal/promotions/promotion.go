@@ -0,0 +1,341 @@
+// Paquete para manejo de promociones automáticas basadas en reglas
+package promotions
+
+import (
+	"errors" // Manejo de errores
+	"math"   // Redondeo de montos
+	"sort"   // Orden determinista de reglas y unidades
+	"time"   // Manejo de tiempos y fechas
+)
+
+// RuleType representa el tipo de promoción
+type RuleType string
+
+// Constantes que definen los tipos de promoción
+const (
+	TypeBuyXGetY       RuleType = "buy_x_get_y"     // Lleva X y paga menos: las unidades más baratas salen gratis (ej. 3x2)
+	TypeSpendThreshold RuleType = "spend_threshold" // Descuento porcentual o fijo al superar un monto
+	TypeFreeGift       RuleType = "free_gift"       // Producto de regalo al superar un monto
+	TypeBundle         RuleType = "bundle"          // Precio fijo por llevar juntos una unidad de cada producto
+)
+
+// Tier es un escalón de una regla spend_threshold: mientras más se gasta, mayor el descuento
+type Tier struct {
+	MinSubtotal float64 `json:"min_subtotal"`          // Monto mínimo de las líneas aplicables para el escalón
+	PercentOff  float64 `json:"percent_off,omitempty"` // Porcentaje de descuento del escalón
+	AmountOff   float64 `json:"amount_off,omitempty"`  // Monto fijo de descuento del escalón
+}
+
+// Rule representa una promoción declarativa
+type Rule struct {
+	ID            string    `json:"id"`                        // ID único de la regla
+	Name          string    `json:"name"`                      // Nombre visible en la orden
+	Type          RuleType  `json:"type"`                      // Tipo de promoción
+	Priority      int       `json:"priority"`                  // Mayor prioridad se evalúa primero
+	Exclusive     bool      `json:"exclusive"`                 // Si se aplica, no se evalúan las reglas siguientes
+	Disabled      bool      `json:"disabled"`                  // Regla desactivada
+	Categories    []string  `json:"categories,omitempty"`      // Solo líneas de estas categorías (vacío = todas)
+	ProductIDs    []string  `json:"product_ids,omitempty"`     // Solo estos productos (vacío = todos)
+	BuyQuantity   int       `json:"buy_quantity,omitempty"`    // buy_x_get_y: unidades que se pagan
+	FreeQuantity  int       `json:"free_quantity,omitempty"`   // buy_x_get_y: unidades gratis por grupo
+	MinSubtotal   float64   `json:"min_subtotal,omitempty"`    // spend_threshold / free_gift: monto mínimo de las líneas aplicables
+	PercentOff    float64   `json:"percent_off,omitempty"`     // spend_threshold: porcentaje de descuento
+	AmountOff     float64   `json:"amount_off,omitempty"`      // spend_threshold: monto fijo de descuento
+	Tiers         []Tier    `json:"tiers,omitempty"`           // spend_threshold: escalones en lugar de un único descuento
+	BundlePrice   float64   `json:"bundle_price,omitempty"`    // bundle: precio de una unidad de cada producto de product_ids
+	GiftProductID string    `json:"gift_product_id,omitempty"` // free_gift: producto que se regala
+	GiftQuantity  int       `json:"gift_quantity,omitempty"`   // free_gift: unidades de regalo
+	CreatedAt     time.Time `json:"created_at"`                // Fecha de creación
+	UpdatedAt     time.Time `json:"updated_at"`                // Fecha de última actualización
+}
+
+// Line es una línea de orden evaluada por las promociones
+type Line struct {
+	ProductID string  // Producto
+	Category  string  // Categoría del producto
+	Quantity  int     // Cantidad
+	UnitPrice float64 // Precio unitario
+}
+
+// Applied es una promoción aplicada a la orden
+type Applied struct {
+	RuleID        string    `json:"rule_id"`                   // Regla aplicada
+	Name          string    `json:"name"`                      // Nombre de la regla
+	Type          RuleType  `json:"type"`                      // Tipo de promoción
+	Amount        float64   `json:"amount"`                    // Monto descontado
+	LineDiscounts []float64 `json:"-"`                         // Descuento por línea (mismo orden que las líneas evaluadas)
+	GiftProductID string    `json:"gift_product_id,omitempty"` // Producto regalado
+	GiftQuantity  int       `json:"gift_quantity,omitempty"`   // Unidades regaladas
+}
+
+// Errores del paquete
+var (
+	ErrInvalidRule  = errors.New("invalid promotion rule")
+	ErrRuleNotFound = errors.New("promotion rule not found")
+)
+
+// Método que valida los campos requeridos por el tipo de regla
+func (r *Rule) Validate() error {
+	if r.Name == "" || r.MinSubtotal < 0 {
+		return ErrInvalidRule
+	}
+	switch r.Type {
+	case TypeBuyXGetY:
+		if r.BuyQuantity <= 0 || r.FreeQuantity <= 0 {
+			return ErrInvalidRule
+		}
+	case TypeSpendThreshold:
+		if len(r.Tiers) > 0 {
+			// Con escalones el descuento sale de ellos, no de la regla
+			if r.PercentOff != 0 || r.AmountOff != 0 {
+				return ErrInvalidRule
+			}
+			seen := make(map[float64]bool)
+			for _, t := range r.Tiers {
+				if t.MinSubtotal < 0 || seen[t.MinSubtotal] || !validDiscount(t.PercentOff, t.AmountOff) {
+					return ErrInvalidRule
+				}
+				seen[t.MinSubtotal] = true
+			}
+		} else if !validDiscount(r.PercentOff, r.AmountOff) {
+			return ErrInvalidRule
+		}
+	case TypeFreeGift:
+		if r.GiftProductID == "" || r.GiftQuantity < 0 {
+			return ErrInvalidRule
+		}
+	case TypeBundle:
+		if r.BundlePrice <= 0 || len(r.ProductIDs) < 2 {
+			return ErrInvalidRule
+		}
+		seen := make(map[string]bool)
+		for _, id := range r.ProductIDs {
+			if id == "" || seen[id] {
+				return ErrInvalidRule
+			}
+			seen[id] = true
+		}
+	default:
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// Método que indica si la regla aplica a una línea
+func (r *Rule) AppliesTo(l Line) bool {
+	if len(r.ProductIDs) > 0 && !contains(r.ProductIDs, l.ProductID) {
+		return false
+	}
+	if len(r.Categories) > 0 && !contains(r.Categories, l.Category) {
+		return false
+	}
+	return true
+}
+
+// Evaluate aplica las reglas activas a las líneas en orden determinista: prioridad descendente y luego ID.
+// Cada regla ve los montos que dejaron las anteriores, de modo que los descuentos se acumulan sin
+// superar el valor de la línea. Una regla exclusiva que se aplica detiene la evaluación.
+func Evaluate(rules []Rule, lines []Line) []Applied {
+	ordered := append([]Rule(nil), rules...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	remaining := make([]float64, len(lines)) // Monto de cada línea aún sin descontar
+	for i, l := range lines {
+		remaining[i] = l.UnitPrice * float64(l.Quantity)
+	}
+
+	applied := []Applied{}
+	for _, r := range ordered {
+		if r.Disabled {
+			continue
+		}
+		a, ok := r.apply(lines, remaining)
+		if !ok {
+			continue
+		}
+		for i, d := range a.LineDiscounts {
+			remaining[i] -= d
+		}
+		applied = append(applied, a)
+		if r.Exclusive {
+			break
+		}
+	}
+	return applied
+}
+
+// Aplica una regla sobre los montos restantes; ok es false si la regla no se cumple
+func (r *Rule) apply(lines []Line, remaining []float64) (Applied, bool) {
+	a := Applied{RuleID: r.ID, Name: r.Name, Type: r.Type, LineDiscounts: make([]float64, len(lines))}
+	var eligible float64
+	for i, l := range lines {
+		if r.AppliesTo(l) {
+			eligible += remaining[i]
+		}
+	}
+
+	switch r.Type {
+	case TypeBuyXGetY:
+		// Cada grupo de BuyQuantity+FreeQuantity unidades regala las FreeQuantity más baratas
+		type unit struct {
+			line  int
+			price float64
+		}
+		var units []unit
+		for i, l := range lines {
+			if !r.AppliesTo(l) || l.Quantity == 0 {
+				continue
+			}
+			for q := 0; q < l.Quantity; q++ {
+				units = append(units, unit{line: i, price: remaining[i] / float64(l.Quantity)})
+			}
+		}
+		free := len(units) / (r.BuyQuantity + r.FreeQuantity) * r.FreeQuantity
+		if free == 0 {
+			return a, false
+		}
+		sort.SliceStable(units, func(i, j int) bool { return units[i].price < units[j].price })
+		for _, u := range units[:free] {
+			a.LineDiscounts[u.line] += u.price
+		}
+		for i := range a.LineDiscounts {
+			a.LineDiscounts[i] = roundCents(a.LineDiscounts[i])
+			a.Amount += a.LineDiscounts[i]
+		}
+	case TypeSpendThreshold:
+		if eligible == 0 || eligible < r.MinSubtotal {
+			return a, false
+		}
+		percent, amount := r.PercentOff, r.AmountOff
+		if len(r.Tiers) > 0 {
+			tier, ok := r.tierFor(eligible)
+			if !ok {
+				return a, false
+			}
+			percent, amount = tier.PercentOff, tier.AmountOff
+		}
+		discount := amount
+		if percent > 0 {
+			discount = eligible * percent / 100
+		}
+		a.Amount = roundCents(math.Min(discount, eligible))
+		r.split(a.Amount, eligible, lines, remaining, a.LineDiscounts)
+	case TypeFreeGift:
+		if eligible == 0 || eligible < r.MinSubtotal {
+			return a, false
+		}
+		a.GiftProductID, a.GiftQuantity = r.GiftProductID, r.GiftQuantity
+		if a.GiftQuantity == 0 {
+			a.GiftQuantity = 1
+		}
+	case TypeBundle:
+		if !r.applyBundle(lines, remaining, &a) {
+			return a, false
+		}
+	}
+	a.Amount = roundCents(a.Amount)
+	return a, true
+}
+
+// Devuelve el escalón más alto alcanzado por el monto aplicable
+func (r *Rule) tierFor(eligible float64) (Tier, bool) {
+	var best Tier
+	found := false
+	for _, t := range r.Tiers {
+		if eligible >= t.MinSubtotal && (!found || t.MinSubtotal > best.MinSubtotal) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+// Arma conjuntos de una unidad de cada producto del combo y descuenta la diferencia con BundlePrice.
+// Devuelve false si no se completa ningún conjunto o el combo no resulta más barato.
+func (r *Rule) applyBundle(lines []Line, remaining []float64, a *Applied) bool {
+	type unit struct {
+		line  int
+		price float64
+	}
+	units := make(map[string][]unit) // Unidades disponibles de cada producto, en el orden de las líneas
+	for i, l := range lines {
+		if !r.AppliesTo(l) || l.Quantity == 0 {
+			continue
+		}
+		for q := 0; q < l.Quantity; q++ {
+			units[l.ProductID] = append(units[l.ProductID], unit{line: i, price: remaining[i] / float64(l.Quantity)})
+		}
+	}
+	sets := -1
+	for _, id := range r.ProductIDs {
+		if n := len(units[id]); sets < 0 || n < sets {
+			sets = n
+		}
+	}
+	for s := 0; s < sets; s++ {
+		var full float64
+		for _, id := range r.ProductIDs {
+			full += units[id][s].price
+		}
+		if full <= r.BundlePrice {
+			continue
+		}
+		// La rebaja del conjunto se reparte entre sus unidades en proporción a su precio
+		for _, id := range r.ProductIDs {
+			u := units[id][s]
+			a.LineDiscounts[u.line] += (full - r.BundlePrice) * u.price / full
+		}
+	}
+	for i := range a.LineDiscounts {
+		a.LineDiscounts[i] = roundCents(a.LineDiscounts[i])
+		a.Amount += a.LineDiscounts[i]
+	}
+	return a.Amount > 0
+}
+
+// Reparte un descuento entre las líneas aplicables en proporción a su monto restante
+func (r *Rule) split(discount, eligible float64, lines []Line, remaining, out []float64) {
+	left := discount
+	lastEligible := -1
+	for i, l := range lines {
+		if r.AppliesTo(l) && remaining[i] > 0 {
+			lastEligible = i
+		}
+	}
+	for i, l := range lines {
+		if !r.AppliesTo(l) || remaining[i] <= 0 {
+			continue
+		}
+		share := roundCents(discount * remaining[i] / eligible)
+		if i == lastEligible {
+			share = roundCents(left) // La última línea absorbe la diferencia de redondeo
+		}
+		out[i] = share
+		left -= share
+	}
+}
+
+// Indica si se pide exactamente uno de los dos descuentos y es válido
+func validDiscount(percent, amount float64) bool {
+	hasPercent, hasAmount := percent > 0, amount > 0
+	return hasPercent != hasAmount && percent <= 100 && amount >= 0
+}
+
+// Indica si un valor está en la lista
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,125 @@
+// Pruebas de la evaluación de reglas de promoción
+package promotions_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/promotions"
+)
+
+// Suma los descuentos aplicados
+func total(applied []promotions.Applied) float64 {
+	var sum float64
+	for _, a := range applied {
+		sum += a.Amount
+	}
+	return sum
+}
+
+func TestEvaluateRules(t *testing.T) {
+	tiers := []promotions.Tier{{MinSubtotal: 50, PercentOff: 5}, {MinSubtotal: 100, PercentOff: 10}}
+	tests := []struct {
+		name  string
+		rules []promotions.Rule
+		lines []promotions.Line
+		want  float64
+	}{
+		{
+			name:  "3x2 regala la unidad más barata",
+			rules: []promotions.Rule{{ID: "a", Name: "3x2", Type: promotions.TypeBuyXGetY, BuyQuantity: 2, FreeQuantity: 1}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 2, UnitPrice: 10}, {ProductID: "p2", Quantity: 1, UnitPrice: 4}},
+			want:  4,
+		},
+		{
+			name:  "umbral no alcanzado",
+			rules: []promotions.Rule{{ID: "a", Name: "10 off", Type: promotions.TypeSpendThreshold, MinSubtotal: 100, AmountOff: 10}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 1, UnitPrice: 99}},
+			want:  0,
+		},
+		{
+			name:  "escalón más alto alcanzado",
+			rules: []promotions.Rule{{ID: "a", Name: "escalones", Type: promotions.TypeSpendThreshold, Tiers: tiers}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 3, UnitPrice: 40}},
+			want:  12,
+		},
+		{
+			name:  "escalón intermedio",
+			rules: []promotions.Rule{{ID: "a", Name: "escalones", Type: promotions.TypeSpendThreshold, Tiers: tiers}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 2, UnitPrice: 30}},
+			want:  3,
+		},
+		{
+			name:  "combo completo una vez",
+			rules: []promotions.Rule{{ID: "a", Name: "combo", Type: promotions.TypeBundle, ProductIDs: []string{"p1", "p2"}, BundlePrice: 25}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 2, UnitPrice: 20}, {ProductID: "p2", Quantity: 1, UnitPrice: 10}},
+			want:  5,
+		},
+		{
+			name:  "combo incompleto",
+			rules: []promotions.Rule{{ID: "a", Name: "combo", Type: promotions.TypeBundle, ProductIDs: []string{"p1", "p2"}, BundlePrice: 25}},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 3, UnitPrice: 20}},
+			want:  0,
+		},
+		{
+			name: "la regla exclusiva detiene las siguientes",
+			rules: []promotions.Rule{
+				{ID: "a", Name: "exclusiva", Type: promotions.TypeSpendThreshold, Priority: 2, Exclusive: true, PercentOff: 10},
+				{ID: "b", Name: "otra", Type: promotions.TypeSpendThreshold, Priority: 1, AmountOff: 5},
+			},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 1, UnitPrice: 50}},
+			want:  5,
+		},
+		{
+			name: "las reglas se acumulan sobre el monto restante",
+			rules: []promotions.Rule{
+				{ID: "a", Name: "primera", Type: promotions.TypeSpendThreshold, Priority: 2, PercentOff: 50},
+				{ID: "b", Name: "segunda", Type: promotions.TypeSpendThreshold, Priority: 1, PercentOff: 50},
+			},
+			lines: []promotions.Line{{ProductID: "p1", Quantity: 1, UnitPrice: 40}},
+			want:  30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := total(promotions.Evaluate(tt.rules, tt.lines)); got != tt.want {
+				t.Fatalf("descuento = %.2f, se esperaba %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleDiscountSplitsByPrice(t *testing.T) {
+	rule := promotions.Rule{ID: "a", Name: "combo", Type: promotions.TypeBundle, ProductIDs: []string{"p1", "p2"}, BundlePrice: 24}
+	applied := promotions.Evaluate([]promotions.Rule{rule}, []promotions.Line{
+		{ProductID: "p1", Quantity: 1, UnitPrice: 20},
+		{ProductID: "p2", Quantity: 1, UnitPrice: 10},
+	})
+	if len(applied) != 1 {
+		t.Fatalf("promociones aplicadas = %d, se esperaba 1", len(applied))
+	}
+	if d := applied[0].LineDiscounts; d[0] != 4 || d[1] != 2 {
+		t.Fatalf("descuentos por línea = %v, se esperaba [4 2]", d)
+	}
+}
+
+func TestCreateRuleValidation(t *testing.T) {
+	svc := promotions.NewService(promotions.NewInMemoryRepository())
+	invalid := []promotions.RuleRequest{
+		{Name: "sin tipo"},
+		{Name: "ambos descuentos", Type: promotions.TypeSpendThreshold, PercentOff: 10, AmountOff: 5},
+		{Name: "escalones y descuento", Type: promotions.TypeSpendThreshold, PercentOff: 10, Tiers: []promotions.Tier{{MinSubtotal: 10, PercentOff: 5}}},
+		{Name: "escalones repetidos", Type: promotions.TypeSpendThreshold, Tiers: []promotions.Tier{{MinSubtotal: 10, PercentOff: 5}, {MinSubtotal: 10, PercentOff: 8}}},
+		{Name: "combo de un producto", Type: promotions.TypeBundle, ProductIDs: []string{"p1"}, BundlePrice: 10},
+		{Name: "combo sin precio", Type: promotions.TypeBundle, ProductIDs: []string{"p1", "p2"}},
+	}
+	for _, req := range invalid {
+		if _, err := svc.CreateRule(context.Background(), req); !errors.Is(err, promotions.ErrInvalidRule) {
+			t.Errorf("%s: err = %v, se esperaba %v", req.Name, err, promotions.ErrInvalidRule)
+		}
+	}
+	if _, err := svc.CreateRule(context.Background(), promotions.RuleRequest{Name: "combo", Type: promotions.TypeBundle, ProductIDs: []string{"p1", "p2"}, BundlePrice: 10}); err != nil {
+		t.Fatalf("CreateRule combo válido: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Paquete para manejo de promociones automáticas basadas en reglas
+package promotions
+
+import (
+	"context"       // Manejo de contexto en funciones
+	"encoding/json" // Lectura del archivo de reglas
+	"fmt"           // Formateo de IDs y errores
+	"os"            // Lectura de archivos
+	"sort"          // Ordenamiento de resultados
+	"sync"          // Sincronización de acceso concurrente
+	"time"          // Manejo de tiempos y fechas
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de promociones
+type Service interface {
+	CreateRule(ctx context.Context, req RuleRequest) (*Rule, error)            // Crear regla
+	ListRules(ctx context.Context) ([]Rule, error)                             // Listar reglas en orden de evaluación
+	GetRule(ctx context.Context, id string) (*Rule, error)                     // Obtener regla por ID
+	UpdateRule(ctx context.Context, id string, req RuleRequest) (*Rule, error) // Reemplazar regla
+	DeleteRule(ctx context.Context, id string) error                           // Eliminar regla
+	Evaluate(ctx context.Context, lines []Line) ([]Applied, error)             // Aplicar las reglas vigentes a una orden
+}
+
+// Implementación en memoria del repositorio de reglas
+type inMemoryRepository struct {
+	rules map[string]Rule // Reglas indexadas por ID
+	seq   int             // Secuencia para IDs generados
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{rules: make(map[string]Rule)}
+}
+
+// Implementación del servicio de promociones
+type promotionService struct {
+	mu   sync.RWMutex        // Protege las reglas mientras se evalúan
+	repo *inMemoryRepository // Repositorio interno
+}
+
+// Constructor para crear un nuevo servicio de promociones
+func NewService(repo *inMemoryRepository) Service {
+	return &promotionService{repo: repo}
+}
+
+// Lee un archivo JSON con una lista de reglas (formato de RuleRequest)
+func LoadFile(path string) ([]RuleRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []RuleRequest
+	if err := json.Unmarshal(data, &reqs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reqs, nil
+}
+
+// Crear una regla validando sus campos
+func (s *promotionService) CreateRule(ctx context.Context, req RuleRequest) (*Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := req.ID
+	if id == "" {
+		s.repo.seq++
+		id = fmt.Sprintf("PROMO-%03d", s.repo.seq)
+	}
+	if _, exists := s.repo.rules[id]; exists {
+		return nil, fmt.Errorf("%w: id %s already exists", ErrInvalidRule, id)
+	}
+	now := time.Now()
+	r := ruleFromRequest(id, req)
+	r.CreatedAt, r.UpdatedAt = now, now
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	s.repo.rules[id] = r
+	return &r, nil
+}
+
+// Listar reglas en el orden en que se evalúan
+func (s *promotionService) ListRules(ctx context.Context) ([]Rule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := s.sortedRules()
+	return list, nil
+}
+
+// Obtener una regla por su ID
+func (s *promotionService) GetRule(ctx context.Context, id string) (*Rule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.repo.rules[id]
+	if !ok {
+		return nil, ErrRuleNotFound
+	}
+	return &r, nil
+}
+
+// Reemplazar una regla existente conservando su fecha de creación
+func (s *promotionService) UpdateRule(ctx context.Context, id string, req RuleRequest) (*Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.repo.rules[id]
+	if !ok {
+		return nil, ErrRuleNotFound
+	}
+	r := ruleFromRequest(id, req)
+	r.CreatedAt, r.UpdatedAt = existing.CreatedAt, time.Now()
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	s.repo.rules[id] = r
+	return &r, nil
+}
+
+// Eliminar una regla
+func (s *promotionService) DeleteRule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.repo.rules[id]; !ok {
+		return ErrRuleNotFound
+	}
+	delete(s.repo.rules, id)
+	return nil
+}
+
+// Aplicar las reglas vigentes a las líneas de una orden
+func (s *promotionService) Evaluate(ctx context.Context, lines []Line) ([]Applied, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Evaluate(s.sortedRules(), lines), nil
+}
+
+// Reglas ordenadas por prioridad descendente y luego por ID
+func (s *promotionService) sortedRules() []Rule {
+	list := make([]Rule, 0, len(s.repo.rules))
+	for _, r := range s.repo.rules {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority > list[j].Priority
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Construye una regla a partir de la solicitud
+func ruleFromRequest(id string, req RuleRequest) Rule {
+	return Rule{
+		ID:            id,
+		Name:          req.Name,
+		Type:          req.Type,
+		Priority:      req.Priority,
+		Exclusive:     req.Exclusive,
+		Disabled:      req.Disabled,
+		Categories:    req.Categories,
+		ProductIDs:    req.ProductIDs,
+		BuyQuantity:   req.BuyQuantity,
+		FreeQuantity:  req.FreeQuantity,
+		MinSubtotal:   req.MinSubtotal,
+		PercentOff:    req.PercentOff,
+		AmountOff:     req.AmountOff,
+		Tiers:         req.Tiers,
+		BundlePrice:   req.BundlePrice,
+		GiftProductID: req.GiftProductID,
+		GiftQuantity:  req.GiftQuantity,
+	}
+}
@@ -0,0 +1,21 @@
+// Paquete para manejo de reembolsos de órdenes
+package refunds
+
+// Estructura que representa la solicitud de un reembolso.
+// Sin líneas ni monto se reembolsa todo lo pendiente; con líneas se calcula el monto de esas unidades;
+// con monto (sin líneas) se devuelve ese importe.
+type RefundRequest struct {
+	Lines   []RefundLineRequest `json:"lines,omitempty"`  // Líneas y unidades a reembolsar
+	Amount  float64             `json:"amount,omitempty"` // Monto a reembolsar
+	Reason  Reason              `json:"reason"`           // Motivo del reembolso
+	Note    string              `json:"note,omitempty"`   // Comentario libre
+	Restock bool                `json:"restock"`          // Devolver al inventario las unidades reembolsadas
+	// Pago sobre el que se devuelve el dinero (opcional; por defecto el primero con saldo suficiente)
+	PaymentID string `json:"payment_id,omitempty"`
+}
+
+// Estructura que representa una línea dentro de la solicitud de reembolso
+type RefundLineRequest struct {
+	Line     int `json:"line"`     // Número de línea de la orden
+	Quantity int `json:"quantity"` // Unidades a reembolsar
+}
@@ -0,0 +1,59 @@
+// Paquete para manejo de reembolsos de órdenes
+package refunds
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+)
+
+// Reason representa el motivo de un reembolso
+type Reason string
+
+// Constantes que definen los motivos de reembolso
+const (
+	ReasonCustomerRequest Reason = "customer_request" // El cliente desistió de la compra
+	ReasonDamaged         Reason = "damaged"          // Producto dañado
+	ReasonDefective       Reason = "defective"        // Producto defectuoso
+	ReasonNotAsDescribed  Reason = "not_as_described" // No coincide con la descripción
+	ReasonWrongItem       Reason = "wrong_item"       // Se envió otro producto
+	ReasonPriceAdjustment Reason = "price_adjustment" // Compensación o ajuste de precio
+	ReasonOther           Reason = "other"            // Otro motivo
+)
+
+// Método que valida si el motivo es uno de los permitidos
+func (r Reason) IsValid() bool {
+	switch r {
+	case ReasonCustomerRequest, ReasonDamaged, ReasonDefective, ReasonNotAsDescribed, ReasonWrongItem, ReasonPriceAdjustment, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// RefundLine es una línea de la orden incluida en un reembolso
+type RefundLine struct {
+	Line      int     `json:"line"`       // Número de línea de la orden
+	ProductID string  `json:"product_id"` // Producto de la línea
+	Quantity  int     `json:"quantity"`   // Unidades reembolsadas
+	Amount    float64 `json:"amount"`     // Monto reembolsado por la línea (precio neto con impuestos)
+	Restocked bool    `json:"restocked"`  // Las unidades volvieron al inventario
+}
+
+// Refund es una devolución de dinero asociada a un pago de la orden
+type Refund struct {
+	ID        string       `json:"id"`              // ID único del reembolso
+	OrderID   string       `json:"order_id"`        // Orden reembolsada
+	PaymentID string       `json:"payment_id"`      // Pago sobre el que se devolvió el dinero
+	Amount    float64      `json:"amount"`          // Monto total reembolsado
+	Reason    Reason       `json:"reason"`          // Motivo
+	Note      string       `json:"note,omitempty"`  // Comentario libre
+	Lines     []RefundLine `json:"lines,omitempty"` // Líneas reembolsadas (vacío si fue solo por monto)
+	Actor     string       `json:"actor"`           // Quién registró el reembolso
+	CreatedAt time.Time    `json:"created_at"`      // Fecha de creación
+}
+
+// Errores del servicio de reembolsos
+var (
+	ErrInvalidRefund     = errors.New("invalid refund request")
+	ErrNotRefundable     = errors.New("order has nothing left to refund")
+	ErrExceedsRefundable = errors.New("refund exceeds the amount paid")
+)
@@ -0,0 +1,233 @@
+// Paquete para manejo de reembolsos de órdenes
+package refunds
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de IDs
+	"math"    // Redondeo de montos
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"     // Servicio de órdenes
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"   // Servicio de pagos
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"   // Servicio de productos
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Asignaciones de bodega
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de reembolsos
+type Service interface {
+	CreateRefund(ctx context.Context, orderID, actor string, req RefundRequest) (*Refund, error) // Reembolsar una orden
+	ListByOrder(ctx context.Context, orderID string) ([]Refund, error)                           // Reembolsos de una orden
+}
+
+// Implementación en memoria del repositorio de reembolsos
+type inMemoryRepository struct {
+	refunds []Refund // Reembolsos en orden de creación
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{}
+}
+
+// Implementación del servicio de reembolsos
+type refundService struct {
+	mu             sync.Mutex          // Serializa los reembolsos para no devolver dos veces lo mismo
+	repo           *inMemoryRepository // Repositorio interno
+	orderService   orders.Service      // Órdenes reembolsadas
+	paymentService payments.Service    // Pagos sobre los que se devuelve el dinero
+	productService products.Service    // Reingreso de unidades al inventario
+	restocker      Restocker           // Reingreso a las bodegas de origen (opcional)
+	observers      []Observer          // Interesados en los reembolsos completados
+}
+
+// Observer recibe la orden después de devolver el dinero de un reembolso, con su monto y las unidades por línea
+type Observer interface {
+	OrderRefunded(ctx context.Context, o orders.Order, amount float64, quantities map[int]int)
+}
+
+// Restocker reingresa unidades vendidas a las bodegas de las que salieron
+type Restocker interface {
+	Restock(ctx context.Context, productID string, allocations []warehouses.Allocation, adj products.StockAdjustment) error
+}
+
+// Option configura dependencias opcionales del servicio de reembolsos
+type Option func(*refundService)
+
+// Opción que reingresa las unidades a las bodegas que despacharon cada línea
+func WithRestocker(restocker Restocker) Option {
+	return func(s *refundService) { s.restocker = restocker }
+}
+
+// Opción que registra un observador de reembolsos (ej. notas de crédito)
+func WithObserver(observer Observer) Option {
+	return func(s *refundService) { s.observers = append(s.observers, observer) }
+}
+
+// Constructor para crear un nuevo servicio de reembolsos
+func NewService(repo *inMemoryRepository, ordService orders.Service, payService payments.Service, prodService products.Service, opts ...Option) Service {
+	s := &refundService{repo: repo, orderService: ordService, paymentService: payService, productService: prodService}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Reembolsar una orden por líneas, por monto o por completo
+func (s *refundService) CreateRefund(ctx context.Context, orderID, actor string, req RefundRequest) (*Refund, error) {
+	if !req.Reason.IsValid() || req.Amount < 0 || (len(req.Lines) > 0 && req.Amount > 0) {
+		return nil, ErrInvalidRefund
+	}
+	if actor == "" {
+		actor = products.SystemActor
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	refundable := order.Refundable()
+	if refundable <= 0 {
+		return nil, ErrNotRefundable
+	}
+
+	refund := Refund{
+		ID:        fmt.Sprintf("RF-%06d", len(s.repo.refunds)+1),
+		OrderID:   orderID,
+		Reason:    req.Reason,
+		Note:      req.Note,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}
+	quantities := make(map[int]int) // Unidades por número de línea
+	switch {
+	case len(req.Lines) > 0:
+		for _, l := range req.Lines {
+			item, ok := order.LineByNumber(l.Line)
+			if !ok || l.Quantity <= 0 || quantities[l.Line]+l.Quantity > item.Quantity-item.RefundedQuantity {
+				return nil, fmt.Errorf("%w: line %d", ErrInvalidRefund, l.Line)
+			}
+			quantities[l.Line] += l.Quantity
+			amount := roundCents(item.Total / float64(item.Quantity) * float64(l.Quantity)) // Precio neto con descuentos e impuestos
+			refund.Lines = append(refund.Lines, RefundLine{Line: l.Line, ProductID: item.ProductID, Quantity: l.Quantity, Amount: amount})
+			refund.Amount += amount
+		}
+		if refundsEverything(order, quantities) {
+			refund.Amount = refundable // La última devolución incluye el envío y la diferencia de redondeo
+		}
+	case req.Amount > 0:
+		refund.Amount = req.Amount
+	default:
+		for _, item := range order.LineItems {
+			if left := item.Quantity - item.RefundedQuantity; left > 0 {
+				quantities[item.Line] = left
+				refund.Lines = append(refund.Lines, RefundLine{Line: item.Line, ProductID: item.ProductID, Quantity: left,
+					Amount: roundCents(item.Total / float64(item.Quantity) * float64(left))})
+			}
+		}
+		refund.Amount = refundable
+	}
+	refund.Amount = roundCents(refund.Amount)
+	if refund.Amount > refundable {
+		return nil, ErrExceedsRefundable
+	}
+
+	// Devolver el dinero sobre un pago cobrado que cubra el monto. El reembolso se registra primero en la
+	// orden, para que nadie más pueda reembolsar lo mismo, y se deshace si la pasarela no lo devuelve
+	payment, err := s.refundablePayment(ctx, orderID, req.PaymentID, refund.Amount)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.orderService.RecordRefund(ctx, orderID, refund.Amount, quantities)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.paymentService.Refund(ctx, payment.ID, refund.Amount); err != nil {
+		if _, revertErr := s.orderService.RevertRefund(ctx, orderID, refund.Amount, quantities, order.Status); revertErr != nil {
+			return nil, fmt.Errorf("%w (order refund record not reverted: %v)", err, revertErr)
+		}
+		return nil, err
+	}
+	refund.PaymentID = payment.ID
+
+	// Reingresar las unidades al inventario si se pidió
+	if req.Restock {
+		for i, l := range refund.Lines {
+			err := s.restock(ctx, order, l.Line, l.ProductID, products.StockAdjustment{
+				Delta:       l.Quantity,
+				Reason:      products.ReasonReturn,
+				Actor:       actor,
+				ReferenceID: refund.ID,
+			})
+			refund.Lines[i].Restocked = err == nil // Un producto eliminado no impide el reembolso
+		}
+	}
+
+	s.repo.refunds = append(s.repo.refunds, refund)
+	for _, observer := range s.observers {
+		observer.OrderRefunded(ctx, *updated, refund.Amount, quantities)
+	}
+	return &refund, nil
+}
+
+// Listar los reembolsos de una orden en orden de creación
+func (s *refundService) ListByOrder(ctx context.Context, orderID string) ([]Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Refund{}
+	for _, r := range s.repo.refunds {
+		if r.OrderID == orderID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+// Busca un pago cobrado de la orden con saldo suficiente para el reembolso (el indicado, si se pidió uno)
+func (s *refundService) refundablePayment(ctx context.Context, orderID, paymentID string, amount float64) (*payments.Payment, error) {
+	list, err := s.paymentService.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range list {
+		if paymentID != "" && p.ID != paymentID {
+			continue
+		}
+		if (p.Status == payments.StatusCaptured || p.Status == payments.StatusPartiallyRefunded) && p.Refundable()+0.005 >= amount {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotRefundable
+}
+
+// Indica si con estas unidades quedan reembolsadas todas las líneas de la orden
+func refundsEverything(order *orders.Order, quantities map[int]int) bool {
+	for _, item := range order.LineItems {
+		if item.RefundedQuantity+quantities[item.Line] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// Redondea un monto a centavos
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Reingresa unidades de una línea; con bodegas vuelven a las que despacharon la línea
+func (s *refundService) restock(ctx context.Context, order *orders.Order, line int, productID string, adj products.StockAdjustment) error {
+	if s.restocker == nil {
+		_, err := s.productService.AdjustStock(ctx, productID, adj)
+		return err
+	}
+	var allocations []warehouses.Allocation
+	for _, item := range order.LineItems {
+		if item.Line == line {
+			allocations = item.Allocations
+		}
+	}
+	return s.restocker.Restock(ctx, productID, allocations, adj)
+}
@@ -0,0 +1,160 @@
+// Pruebas de los reembolsos sobre órdenes cobradas con la pasarela simulada
+package refunds_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// Pasarela simulada que rechaza las devoluciones mientras failRefunds sea true
+type flakyGateway struct {
+	*payments.FakeGateway
+	failRefunds bool
+}
+
+func (g *flakyGateway) Refund(ctx context.Context, transactionID string, amount float64) error {
+	if g.failRefunds {
+		return payments.ErrGatewayTimeout
+	}
+	return g.FakeGateway.Refund(ctx, transactionID, amount)
+}
+
+// Observador que cuenta los reembolsos notificados
+type countingObserver struct{ calls int }
+
+func (o *countingObserver) OrderRefunded(ctx context.Context, order orders.Order, amount float64, quantities map[int]int) {
+	o.calls++
+}
+
+// Entorno de prueba: una orden de 3 unidades a 10, cobrada por completo
+type fixture struct {
+	products products.Service
+	orders   orders.Service
+	gateway  *flakyGateway
+	observer *countingObserver
+	refunds  refunds.Service
+	product  *products.Product
+	order    *orders.Order
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &fixture{gateway: &flakyGateway{FakeGateway: payments.NewFakeGateway()}, observer: &countingObserver{}}
+	f.products = products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := f.products.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	f.product = prod
+	f.orders = orders.NewService(orders.NewInMemoryRepository(), f.products)
+	order, err := f.orders.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 3}},
+		ShippingAddress: &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	paymentService := payments.NewService(payments.NewInMemoryRepository(), f.gateway, f.orders)
+	if _, err := paymentService.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true}); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	f.order, _ = f.orders.GetOrderByID(ctx, order.ID)
+	f.refunds = refunds.NewService(refunds.NewInMemoryRepository(), f.orders, paymentService, f.products, refunds.WithObserver(f.observer))
+	return f
+}
+
+func TestRefundLinesWithRestock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	refund, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", refunds.RefundRequest{
+		Lines:   []refunds.RefundLineRequest{{Line: 1, Quantity: 2}},
+		Reason:  refunds.ReasonDamaged,
+		Restock: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if refund.Amount != 20 || !refund.Lines[0].Restocked {
+		t.Fatalf("reembolso = %.2f (reingresado %v), se esperaba 20 reingresado", refund.Amount, refund.Lines[0].Restocked)
+	}
+	if p, _ := f.products.GetProductByID(ctx, f.product.ID); p.Stock != 9 {
+		t.Fatalf("stock = %d, se esperaba 9 (7 + 2 reingresadas)", p.Stock)
+	}
+	o, _ := f.orders.GetOrderByID(ctx, f.order.ID)
+	if o.RefundedAmount != 20 || o.LineItems[0].RefundedQuantity != 2 {
+		t.Fatalf("orden con %.2f y %d unidades reembolsadas, se esperaba 20 y 2", o.RefundedAmount, o.LineItems[0].RefundedQuantity)
+	}
+	if f.observer.calls != 1 {
+		t.Fatalf("notificaciones = %d, se esperaba 1", f.observer.calls)
+	}
+
+	// El resto completa el reembolso y deja la orden como Reembolsado
+	if _, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", refunds.RefundRequest{Reason: refunds.ReasonOther}); err != nil {
+		t.Fatalf("CreateRefund del resto: %v", err)
+	}
+	if o, _ := f.orders.GetOrderByID(ctx, f.order.ID); o.Status != orders.StatusRefunded || o.Refundable() != 0 {
+		t.Fatalf("estado %s con %.2f por reembolsar, se esperaba %s sin saldo", o.Status, o.Refundable(), orders.StatusRefunded)
+	}
+	if _, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", refunds.RefundRequest{Reason: refunds.ReasonOther}); !errors.Is(err, refunds.ErrNotRefundable) {
+		t.Fatalf("tercer reembolso: err = %v, se esperaba %v", err, refunds.ErrNotRefundable)
+	}
+}
+
+func TestRefundValidation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	tests := []struct {
+		name string
+		req  refunds.RefundRequest
+		want error
+	}{
+		{name: "motivo desconocido", req: refunds.RefundRequest{Amount: 5, Reason: "capricho"}, want: refunds.ErrInvalidRefund},
+		{name: "líneas y monto a la vez", req: refunds.RefundRequest{Amount: 5, Lines: []refunds.RefundLineRequest{{Line: 1, Quantity: 1}}, Reason: refunds.ReasonOther}, want: refunds.ErrInvalidRefund},
+		{name: "más unidades que las compradas", req: refunds.RefundRequest{Lines: []refunds.RefundLineRequest{{Line: 1, Quantity: 4}}, Reason: refunds.ReasonOther}, want: refunds.ErrInvalidRefund},
+		{name: "monto mayor a lo cobrado", req: refunds.RefundRequest{Amount: f.order.GrandTotal + 1, Reason: refunds.ReasonOther}, want: refunds.ErrExceedsRefundable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayFailureRevertsOrderRefund(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.gateway.failRefunds = true
+
+	_, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", refunds.RefundRequest{Reason: refunds.ReasonOther, Restock: true})
+	if !errors.Is(err, payments.ErrGatewayTimeout) {
+		t.Fatalf("err = %v, se esperaba %v", err, payments.ErrGatewayTimeout)
+	}
+	o, _ := f.orders.GetOrderByID(ctx, f.order.ID)
+	if o.RefundedAmount != 0 || o.LineItems[0].RefundedQuantity != 0 || o.Status != f.order.Status {
+		t.Fatalf("orden con %.2f reembolsado y estado %s, se esperaba sin reembolso y estado %s", o.RefundedAmount, o.Status, f.order.Status)
+	}
+	if p, _ := f.products.GetProductByID(ctx, f.product.ID); p.Stock != 7 {
+		t.Fatalf("stock = %d, se esperaba 7 (sin reingreso)", p.Stock)
+	}
+	if list, _ := f.refunds.ListByOrder(ctx, f.order.ID); len(list) != 0 || f.observer.calls != 0 {
+		t.Fatalf("%d reembolsos y %d notificaciones, se esperaba ninguno", len(list), f.observer.calls)
+	}
+
+	// Cuando la pasarela vuelve, el reembolso se puede repetir
+	f.gateway.failRefunds = false
+	if _, err := f.refunds.CreateRefund(ctx, f.order.ID, "admin", refunds.RefundRequest{Reason: refunds.ReasonOther}); err != nil {
+		t.Fatalf("CreateRefund tras recuperar la pasarela: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Paquete para manejo de devoluciones de productos (RMA)
+package returns
+
+import "github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds" // Motivos de reembolso
+
+// Estructura que representa la solicitud de devolución del cliente
+type ReturnRequest struct {
+	Lines  []ReturnLineRequest `json:"lines"`          // Líneas y unidades a devolver
+	Reason refunds.Reason      `json:"reason"`         // Motivo de la devolución
+	Note   string              `json:"note,omitempty"` // Comentario del cliente
+}
+
+// Estructura que representa una línea dentro de la solicitud de devolución
+type ReturnLineRequest struct {
+	Line     int `json:"line"`     // Número de línea de la orden
+	Quantity int `json:"quantity"` // Unidades a devolver
+}
+
+// Estructura para los pasos que solo llevan un comentario (aprobar, rechazar, recibir, reembolsar)
+type StepRequest struct {
+	Note string `json:"note,omitempty"` // Comentario
+}
+
+// Estructura que representa el resultado de la inspección
+type InspectRequest struct {
+	Lines []InspectLineRequest `json:"lines"`          // Destino de cada línea
+	Note  string               `json:"note,omitempty"` // Comentario
+}
+
+// Estructura que representa el destino de una línea inspeccionada
+type InspectLineRequest struct {
+	Line        int         `json:"line"`        // Número de línea de la orden
+	Disposition Disposition `json:"disposition"` // restock o write_off
+}
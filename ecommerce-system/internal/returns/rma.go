@@ -0,0 +1,87 @@
+// Paquete para manejo de devoluciones de productos (RMA)
+package returns
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Actor del sistema
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"  // Motivos de reembolso
+)
+
+// Status representa el estado de una devolución
+type Status string
+
+// Constantes que definen los estados de una devolución
+const (
+	StatusRequested Status = "requested" // Solicitada por el cliente
+	StatusApproved  Status = "approved"  // Aprobada; el cliente puede enviar los productos
+	StatusRejected  Status = "rejected"  // Rechazada
+	StatusReceived  Status = "received"  // Productos recibidos en bodega
+	StatusInspected Status = "inspected" // Productos revisados (reingresados o dados de baja); falta el reembolso
+	StatusRefunded  Status = "refunded"  // Reembolso emitido; devolución terminada
+)
+
+// Disposition indica qué se hace con las unidades recibidas
+type Disposition string
+
+// Constantes que definen el destino de las unidades
+const (
+	DispositionRestock  Disposition = "restock"   // Vuelven al inventario
+	DispositionWriteOff Disposition = "write_off" // Se dan de baja (no vuelven a venderse)
+)
+
+// ReturnLine es una línea de la orden incluida en la devolución
+type ReturnLine struct {
+	Line        int         `json:"line"`                  // Número de línea de la orden
+	ProductID   string      `json:"product_id"`            // Producto de la línea
+	Quantity    int         `json:"quantity"`              // Unidades devueltas
+	Disposition Disposition `json:"disposition,omitempty"` // Destino tras la inspección
+}
+
+// StatusChange registra cada paso de la devolución
+type StatusChange struct {
+	Status Status    `json:"status"`         // Estado alcanzado
+	Actor  string    `json:"actor"`          // Quién realizó el paso
+	Note   string    `json:"note,omitempty"` // Comentario
+	At     time.Time `json:"at"`             // Momento del cambio
+}
+
+// Return es una solicitud de devolución (RMA)
+type Return struct {
+	ID          string         `json:"id"`                  // ID único (RMA)
+	OrderID     string         `json:"order_id"`            // Orden de origen
+	UserID      string         `json:"user_id"`             // Cliente que devuelve
+	Lines       []ReturnLine   `json:"lines"`               // Líneas devueltas
+	Reason      refunds.Reason `json:"reason"`              // Motivo de la devolución
+	Status      Status         `json:"status"`              // Estado actual
+	RefundID    string         `json:"refund_id,omitempty"` // Reembolso emitido
+	History     []StatusChange `json:"history"`             // Pasos con fecha y actor
+	RequestedAt time.Time      `json:"requested_at"`        // Fecha de solicitud
+	UpdatedAt   time.Time      `json:"updated_at"`          // Fecha de última actualización
+}
+
+// Método que registra un cambio de estado
+func (r *Return) advance(status Status, actor, note string) {
+	if actor == "" {
+		actor = products.SystemActor
+	}
+	now := time.Now()
+	r.Status, r.UpdatedAt = status, now
+	r.History = append(r.History, StatusChange{Status: status, Actor: actor, Note: note, At: now})
+}
+
+// Método que indica si la devolución sigue en curso (aparta unidades de la orden)
+func (r *Return) IsOpen() bool {
+	return r.Status != StatusRejected && r.Status != StatusRefunded
+}
+
+// Errores del servicio de devoluciones
+var (
+	ErrReturnNotFound     = errors.New("return not found")
+	ErrInvalidReturn      = errors.New("invalid return request")
+	ErrNotReturnable      = errors.New("order is not delivered")
+	ErrReturnWindowClosed = errors.New("return window has closed")
+	ErrNotOrderOwner      = errors.New("order belongs to another user")
+	ErrInvalidTransition  = errors.New("operation not allowed in the current return status")
+)
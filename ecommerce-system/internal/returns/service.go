@@ -0,0 +1,291 @@
+// Paquete para manejo de devoluciones de productos (RMA)
+package returns
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de IDs y errores
+	"sort"    // Ordenamiento de resultados
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"     // Servicio de órdenes
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"   // Reingreso al inventario
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"    // Reembolso final
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Asignaciones de bodega
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de devoluciones
+type Service interface {
+	RequestReturn(ctx context.Context, orderID, userID string, req ReturnRequest) (*Return, error) // El cliente solicita la devolución
+	Approve(ctx context.Context, id, actor, note string) (*Return, error)                          // Aprobar
+	Reject(ctx context.Context, id, actor, note string) (*Return, error)                           // Rechazar
+	Receive(ctx context.Context, id, actor, note string) (*Return, error)                          // Registrar la recepción de los productos
+	Inspect(ctx context.Context, id, actor string, req InspectRequest) (*Return, error)            // Reingresar o dar de baja y reembolsar
+	Refund(ctx context.Context, id, actor, note string) (*Return, error)                           // Reintentar el reembolso de una devolución inspeccionada
+	GetReturn(ctx context.Context, id string) (*Return, error)                                     // Obtener devolución por ID
+	ListReturns(ctx context.Context, status Status) ([]Return, error)                              // Listar devoluciones (filtro opcional por estado)
+	ListByOrder(ctx context.Context, orderID string) ([]Return, error)                             // Devoluciones de una orden
+}
+
+// Implementación en memoria del repositorio de devoluciones
+type inMemoryRepository struct {
+	returns map[string]Return // Devoluciones indexadas por ID
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{returns: make(map[string]Return)}
+}
+
+// Implementación del servicio de devoluciones
+type returnService struct {
+	mu             sync.Mutex          // Serializa los cambios de estado
+	repo           *inMemoryRepository // Repositorio interno
+	window         time.Duration       // Plazo de devolución desde la entrega
+	orderService   orders.Service      // Órdenes de origen
+	productService products.Service    // Reingreso de unidades al inventario
+	refundService  refunds.Service     // Reembolso de las unidades devueltas
+	restocker      refunds.Restocker   // Reingreso a las bodegas de origen (opcional)
+}
+
+// Option configura dependencias opcionales del servicio de devoluciones
+type Option func(*returnService)
+
+// Opción que reingresa las unidades a las bodegas que despacharon cada línea
+func WithRestocker(restocker refunds.Restocker) Option {
+	return func(s *returnService) { s.restocker = restocker }
+}
+
+// Constructor para crear un nuevo servicio de devoluciones con el plazo indicado
+func NewService(repo *inMemoryRepository, window time.Duration, ordService orders.Service, prodService products.Service, refService refunds.Service, opts ...Option) Service {
+	s := &returnService{repo: repo, window: window, orderService: ordService, productService: prodService, refundService: refService}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Solicitar la devolución de líneas de una orden entregada dentro del plazo
+func (s *returnService) RequestReturn(ctx context.Context, orderID, userID string, req ReturnRequest) (*Return, error) {
+	if len(req.Lines) == 0 || !req.Reason.IsValid() {
+		return nil, ErrInvalidReturn
+	}
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrNotOrderOwner
+	}
+	if order.Status != orders.StatusDelivered || order.DeliveredAt == nil {
+		return nil, ErrNotReturnable
+	}
+	if time.Since(*order.DeliveredAt) > s.window {
+		return nil, ErrReturnWindowClosed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.openQuantities(orderID) // Unidades ya incluidas en otras devoluciones en curso
+	rma := Return{
+		ID:          fmt.Sprintf("RMA-%06d", len(s.repo.returns)+1),
+		OrderID:     orderID,
+		UserID:      userID,
+		Reason:      req.Reason,
+		RequestedAt: time.Now(),
+	}
+	for _, l := range req.Lines {
+		item, ok := order.LineByNumber(l.Line)
+		if !ok || l.Quantity <= 0 || pending[l.Line]+l.Quantity > item.Quantity-item.RefundedQuantity {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidReturn, l.Line)
+		}
+		pending[l.Line] += l.Quantity
+		rma.Lines = append(rma.Lines, ReturnLine{Line: l.Line, ProductID: item.ProductID, Quantity: l.Quantity})
+	}
+	rma.advance(StatusRequested, userID, req.Note)
+	s.repo.returns[rma.ID] = rma
+	return &rma, nil
+}
+
+// Aprobar una devolución solicitada
+func (s *returnService) Approve(ctx context.Context, id, actor, note string) (*Return, error) {
+	return s.transition(id, StatusRequested, StatusApproved, actor, note)
+}
+
+// Rechazar una devolución solicitada
+func (s *returnService) Reject(ctx context.Context, id, actor, note string) (*Return, error) {
+	return s.transition(id, StatusRequested, StatusRejected, actor, note)
+}
+
+// Registrar que los productos de una devolución aprobada llegaron
+func (s *returnService) Receive(ctx context.Context, id, actor, note string) (*Return, error) {
+	return s.transition(id, StatusApproved, StatusReceived, actor, note)
+}
+
+// Registrar la inspección: cada línea se reingresa al inventario o se da de baja; luego se emite el reembolso
+func (s *returnService) Inspect(ctx context.Context, id, actor string, req InspectRequest) (*Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rma, ok := s.repo.returns[id]
+	if !ok {
+		return nil, ErrReturnNotFound
+	}
+	if rma.Status != StatusReceived {
+		return nil, ErrInvalidTransition
+	}
+	dispositions := make(map[int]Disposition)
+	for _, l := range req.Lines {
+		if l.Disposition != DispositionRestock && l.Disposition != DispositionWriteOff {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidReturn, l.Line)
+		}
+		dispositions[l.Line] = l.Disposition
+	}
+	rma.Lines = append([]ReturnLine(nil), rma.Lines...)
+	for i, l := range rma.Lines {
+		d, ok := dispositions[l.Line]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing disposition for line %d", ErrInvalidReturn, l.Line)
+		}
+		rma.Lines[i].Disposition = d
+	}
+	order, err := s.orderService.GetOrderByID(ctx, rma.OrderID) // Bodegas que despacharon cada línea
+	if err != nil {
+		return nil, err
+	}
+	var restocked []ReturnLine // Para deshacer el reingreso si falla una línea
+	for _, l := range rma.Lines {
+		if l.Disposition != DispositionRestock {
+			continue // Las unidades dadas de baja no vuelven a estar disponibles
+		}
+		err := s.restock(ctx, order, l, products.StockAdjustment{
+			Delta:       l.Quantity,
+			Reason:      products.ReasonReturn,
+			Actor:       actor,
+			ReferenceID: rma.ID,
+		})
+		if err != nil {
+			for _, done := range restocked {
+				s.restock(ctx, order, done, products.StockAdjustment{
+					Delta:       -done.Quantity,
+					Reason:      products.ReasonReturn,
+					Actor:       actor,
+					ReferenceID: rma.ID,
+				})
+			}
+			return nil, fmt.Errorf("restock line %d: %w", l.Line, err) // La devolución sigue recibida para reintentar
+		}
+		restocked = append(restocked, l)
+	}
+	rma.advance(StatusInspected, actor, req.Note)
+	s.repo.returns[rma.ID] = rma
+	return s.refund(ctx, rma, actor, "")
+}
+
+// Reingresa (o con delta negativo retira) unidades de una línea; con bodegas van a las que despacharon la línea
+func (s *returnService) restock(ctx context.Context, order *orders.Order, l ReturnLine, adj products.StockAdjustment) error {
+	if s.restocker == nil {
+		_, err := s.productService.AdjustStock(ctx, l.ProductID, adj)
+		return err
+	}
+	var allocations []warehouses.Allocation
+	for _, item := range order.LineItems {
+		if item.Line == l.Line {
+			allocations = item.Allocations
+		}
+	}
+	return s.restocker.Restock(ctx, l.ProductID, allocations, adj)
+}
+
+// Reintentar el reembolso de una devolución inspeccionada (por ejemplo, si la pasarela no respondió)
+func (s *returnService) Refund(ctx context.Context, id, actor, note string) (*Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rma, ok := s.repo.returns[id]
+	if !ok {
+		return nil, ErrReturnNotFound
+	}
+	if rma.Status != StatusInspected {
+		return nil, ErrInvalidTransition
+	}
+	return s.refund(ctx, rma, actor, note)
+}
+
+// Obtener una devolución por su ID
+func (s *returnService) GetReturn(ctx context.Context, id string) (*Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rma, ok := s.repo.returns[id]
+	if !ok {
+		return nil, ErrReturnNotFound
+	}
+	return &rma, nil
+}
+
+// Listar devoluciones, opcionalmente filtradas por estado
+func (s *returnService) ListReturns(ctx context.Context, status Status) ([]Return, error) {
+	return s.list(func(r Return) bool { return status == "" || r.Status == status }), nil
+}
+
+// Listar las devoluciones de una orden
+func (s *returnService) ListByOrder(ctx context.Context, orderID string) ([]Return, error) {
+	return s.list(func(r Return) bool { return r.OrderID == orderID }), nil
+}
+
+// Emite el reembolso de las unidades devueltas (requiere s.mu tomado)
+func (s *returnService) refund(ctx context.Context, rma Return, actor, note string) (*Return, error) {
+	req := refunds.RefundRequest{Reason: rma.Reason, Note: "RMA " + rma.ID}
+	for _, l := range rma.Lines {
+		req.Lines = append(req.Lines, refunds.RefundLineRequest{Line: l.Line, Quantity: l.Quantity})
+	}
+	refund, err := s.refundService.CreateRefund(ctx, rma.OrderID, actor, req)
+	if err != nil {
+		return &rma, err // La devolución queda inspeccionada para reintentar el reembolso
+	}
+	rma.RefundID = refund.ID
+	rma.advance(StatusRefunded, actor, note)
+	s.repo.returns[rma.ID] = rma
+	return &rma, nil
+}
+
+// Aplica un cambio de estado simple validando el estado de origen
+func (s *returnService) transition(id string, from, to Status, actor, note string) (*Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rma, ok := s.repo.returns[id]
+	if !ok {
+		return nil, ErrReturnNotFound
+	}
+	if rma.Status != from {
+		return nil, ErrInvalidTransition
+	}
+	rma.advance(to, actor, note)
+	s.repo.returns[rma.ID] = rma
+	return &rma, nil
+}
+
+// Unidades por número de línea incluidas en devoluciones en curso de una orden (requiere s.mu tomado)
+func (s *returnService) openQuantities(orderID string) map[int]int {
+	pending := make(map[int]int)
+	for _, r := range s.repo.returns {
+		if r.OrderID == orderID && r.IsOpen() {
+			for _, l := range r.Lines {
+				pending[l.Line] += l.Quantity
+			}
+		}
+	}
+	return pending
+}
+
+// Devoluciones que cumplen el filtro, ordenadas por ID
+func (s *returnService) list(match func(Return) bool) []Return {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Return{}
+	for _, r := range s.repo.returns {
+		if match(r) {
+			list = append(list, r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,230 @@
+// Pruebas del flujo de devoluciones (RMA) sobre órdenes entregadas
+package returns_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+	"time"    // Plazo de devolución
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/refunds"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/returns"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// Entorno de prueba: una orden de 3 unidades a 10, cobrada y entregada por completo
+type fixture struct {
+	products products.Service
+	orders   orders.Service
+	refunds  refunds.Service
+	product  *products.Product
+	order    *orders.Order
+}
+
+func newFixture(t *testing.T, deliver bool) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &fixture{}
+	f.products = products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := f.products.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	f.product = prod
+	f.orders = orders.NewService(orders.NewInMemoryRepository(), f.products)
+	order, err := f.orders.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 3}},
+		ShippingAddress: &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	paymentService := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), f.orders)
+	if _, err := paymentService.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true}); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if deliver {
+		if _, err := f.orders.RecordFulfillment(ctx, order.ID, map[int]int{1: 3}, map[int]int{1: 3}); err != nil {
+			t.Fatalf("RecordFulfillment: %v", err)
+		}
+	}
+	f.order, _ = f.orders.GetOrderByID(ctx, order.ID)
+	f.refunds = refunds.NewService(refunds.NewInMemoryRepository(), f.orders, paymentService, f.products)
+	return f
+}
+
+// Crea el servicio de devoluciones con el plazo indicado
+func (f *fixture) service(window time.Duration) returns.Service {
+	return returns.NewService(returns.NewInMemoryRepository(), window, f.orders, f.products, f.refunds)
+}
+
+func TestReturnFullFlow(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, true)
+	svc := f.service(30 * 24 * time.Hour)
+
+	rma, err := svc.RequestReturn(ctx, f.order.ID, "user-1", returns.ReturnRequest{
+		Lines:  []returns.ReturnLineRequest{{Line: 1, Quantity: 2}},
+		Reason: refunds.ReasonDefective,
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+	if rma.Status != returns.StatusRequested {
+		t.Fatalf("estado = %s, se esperaba %s", rma.Status, returns.StatusRequested)
+	}
+	// Las unidades en una devolución abierta no pueden pedirse otra vez
+	if _, err := svc.RequestReturn(ctx, f.order.ID, "user-1", returns.ReturnRequest{
+		Lines:  []returns.ReturnLineRequest{{Line: 1, Quantity: 2}},
+		Reason: refunds.ReasonDefective,
+	}); !errors.Is(err, returns.ErrInvalidReturn) {
+		t.Fatalf("segunda solicitud: err = %v, se esperaba %v", err, returns.ErrInvalidReturn)
+	}
+
+	if _, err := svc.Approve(ctx, rma.ID, "admin", ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if _, err := svc.Receive(ctx, rma.ID, "admin", "llegó la caja"); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	// La inspección debe indicar el destino de cada línea
+	if _, err := svc.Inspect(ctx, rma.ID, "admin", returns.InspectRequest{}); !errors.Is(err, returns.ErrInvalidReturn) {
+		t.Fatalf("inspección sin destinos: err = %v, se esperaba %v", err, returns.ErrInvalidReturn)
+	}
+	rma, err = svc.Inspect(ctx, rma.ID, "admin", returns.InspectRequest{
+		Lines: []returns.InspectLineRequest{{Line: 1, Disposition: returns.DispositionRestock}},
+	})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if rma.Status != returns.StatusRefunded || rma.RefundID == "" {
+		t.Fatalf("estado = %s (reembolso %q), se esperaba %s con reembolso", rma.Status, rma.RefundID, returns.StatusRefunded)
+	}
+	if len(rma.History) != 5 {
+		t.Fatalf("pasos en el historial = %d, se esperaba 5", len(rma.History))
+	}
+	if p, _ := f.products.GetProductByID(ctx, f.product.ID); p.Stock != 9 {
+		t.Fatalf("stock = %d, se esperaba 9 (7 + 2 reingresadas)", p.Stock)
+	}
+	if o, _ := f.orders.GetOrderByID(ctx, f.order.ID); o.RefundedAmount != 20 || o.LineItems[0].RefundedQuantity != 2 {
+		t.Fatalf("orden con %.2f y %d unidades reembolsadas, se esperaba 20 y 2", o.RefundedAmount, o.LineItems[0].RefundedQuantity)
+	}
+	if list, _ := svc.ListByOrder(ctx, f.order.ID); len(list) != 1 {
+		t.Fatalf("devoluciones de la orden = %d, se esperaba 1", len(list))
+	}
+}
+
+func TestReturnWriteOffKeepsStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, true)
+	svc := f.service(time.Hour)
+
+	rma, err := svc.RequestReturn(ctx, f.order.ID, "user-1", returns.ReturnRequest{
+		Lines:  []returns.ReturnLineRequest{{Line: 1, Quantity: 1}},
+		Reason: refunds.ReasonDamaged,
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+	if _, err := svc.Approve(ctx, rma.ID, "admin", ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if _, err := svc.Receive(ctx, rma.ID, "admin", ""); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	rma, err = svc.Inspect(ctx, rma.ID, "admin", returns.InspectRequest{
+		Lines: []returns.InspectLineRequest{{Line: 1, Disposition: returns.DispositionWriteOff}},
+	})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if rma.Status != returns.StatusRefunded {
+		t.Fatalf("estado = %s, se esperaba %s", rma.Status, returns.StatusRefunded)
+	}
+	if p, _ := f.products.GetProductByID(ctx, f.product.ID); p.Stock != 7 {
+		t.Fatalf("stock = %d, se esperaba 7 (la unidad dada de baja no vuelve)", p.Stock)
+	}
+}
+
+func TestRequestReturnValidation(t *testing.T) {
+	ctx := context.Background()
+	delivered := newFixture(t, true)
+	undelivered := newFixture(t, false)
+	valid := returns.ReturnRequest{Lines: []returns.ReturnLineRequest{{Line: 1, Quantity: 1}}, Reason: refunds.ReasonOther}
+	tests := []struct {
+		name   string
+		f      *fixture
+		window time.Duration
+		user   string
+		req    returns.ReturnRequest
+		want   error
+	}{
+		{name: "sin líneas", f: delivered, window: time.Hour, user: "user-1", req: returns.ReturnRequest{Reason: refunds.ReasonOther}, want: returns.ErrInvalidReturn},
+		{name: "motivo desconocido", f: delivered, window: time.Hour, user: "user-1", req: returns.ReturnRequest{Lines: valid.Lines, Reason: "capricho"}, want: returns.ErrInvalidReturn},
+		{name: "más unidades que las compradas", f: delivered, window: time.Hour, user: "user-1", req: returns.ReturnRequest{Lines: []returns.ReturnLineRequest{{Line: 1, Quantity: 4}}, Reason: refunds.ReasonOther}, want: returns.ErrInvalidReturn},
+		{name: "línea inexistente", f: delivered, window: time.Hour, user: "user-1", req: returns.ReturnRequest{Lines: []returns.ReturnLineRequest{{Line: 2, Quantity: 1}}, Reason: refunds.ReasonOther}, want: returns.ErrInvalidReturn},
+		{name: "orden de otro usuario", f: delivered, window: time.Hour, user: "user-2", req: valid, want: returns.ErrNotOrderOwner},
+		{name: "orden sin entregar", f: undelivered, window: time.Hour, user: "user-1", req: valid, want: returns.ErrNotReturnable},
+		{name: "plazo vencido", f: delivered, window: time.Nanosecond, user: "user-1", req: valid, want: returns.ErrReturnWindowClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := tt.f.service(tt.window)
+			if _, err := svc.RequestReturn(ctx, tt.f.order.ID, tt.user, tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReturnTransitions(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, true)
+	svc := f.service(time.Hour)
+	rma, err := svc.RequestReturn(ctx, f.order.ID, "user-1", returns.ReturnRequest{
+		Lines:  []returns.ReturnLineRequest{{Line: 1, Quantity: 3}},
+		Reason: refunds.ReasonWrongItem,
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+
+	// Una devolución solicitada no puede recibirse, inspeccionarse ni reembolsarse
+	if _, err := svc.Receive(ctx, rma.ID, "admin", ""); !errors.Is(err, returns.ErrInvalidTransition) {
+		t.Fatalf("Receive: err = %v, se esperaba %v", err, returns.ErrInvalidTransition)
+	}
+	if _, err := svc.Inspect(ctx, rma.ID, "admin", returns.InspectRequest{}); !errors.Is(err, returns.ErrInvalidTransition) {
+		t.Fatalf("Inspect: err = %v, se esperaba %v", err, returns.ErrInvalidTransition)
+	}
+	if _, err := svc.Refund(ctx, rma.ID, "admin", ""); !errors.Is(err, returns.ErrInvalidTransition) {
+		t.Fatalf("Refund: err = %v, se esperaba %v", err, returns.ErrInvalidTransition)
+	}
+
+	// Al rechazarla queda cerrada y libera las unidades para otra solicitud
+	rma, err = svc.Reject(ctx, rma.ID, "admin", "fuera de política")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if rma.Status != returns.StatusRejected {
+		t.Fatalf("estado = %s, se esperaba %s", rma.Status, returns.StatusRejected)
+	}
+	if _, err := svc.Approve(ctx, rma.ID, "admin", ""); !errors.Is(err, returns.ErrInvalidTransition) {
+		t.Fatalf("Approve tras rechazo: err = %v, se esperaba %v", err, returns.ErrInvalidTransition)
+	}
+	if _, err := svc.RequestReturn(ctx, f.order.ID, "user-1", returns.ReturnRequest{
+		Lines:  []returns.ReturnLineRequest{{Line: 1, Quantity: 3}},
+		Reason: refunds.ReasonWrongItem,
+	}); err != nil {
+		t.Fatalf("nueva solicitud tras rechazo: %v", err)
+	}
+	if list, _ := svc.ListReturns(ctx, returns.StatusRequested); len(list) != 1 {
+		t.Fatalf("devoluciones solicitadas = %d, se esperaba 1", len(list))
+	}
+	if _, err := svc.GetReturn(ctx, "RMA-999999"); !errors.Is(err, returns.ErrReturnNotFound) {
+		t.Fatalf("GetReturn: err = %v, se esperaba %v", err, returns.ErrReturnNotFound)
+	}
+}
@@ -0,0 +1,92 @@
+// Paquete para ejecutar tareas periódicas dentro del proceso de la API
+package scheduler
+
+import (
+	"sync" // Sincronización de acceso concurrente
+	"time" // Manejo de tiempos y fechas
+)
+
+// Clock es la fuente de tiempo del planificador; permite reemplazar el reloj real en pruebas
+type Clock interface {
+	Now() time.Time                         // Hora actual
+	After(d time.Duration) <-chan time.Time // Canal que recibe la hora cuando pasa d
+}
+
+// Reloj del sistema
+type realClock struct{}
+
+// Constructor del reloj del sistema
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+// Hora actual del sistema
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Espera real de d
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Espera pendiente en el reloj falso
+type waiter struct {
+	at time.Time      // Momento en que vence la espera
+	ch chan time.Time // Canal que se notifica al vencer
+}
+
+// FakeClock es un reloj manual: el tiempo solo avanza con Advance
+type FakeClock struct {
+	mu      sync.Mutex // Mutex para sincronizar acceso concurrente
+	now     time.Time  // Hora actual simulada
+	waiters []waiter   // Esperas aún no vencidas
+}
+
+// Constructor de un reloj falso que empieza en start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Hora actual simulada
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Canal que recibe la hora simulada cuando el reloj avance al menos d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Avanza el reloj d y notifica las esperas que vencen
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// Cantidad de esperas pendientes (útil en pruebas para saber que una tarea ya está esperando)
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
@@ -0,0 +1,75 @@
+// Paquete para ejecutar tareas periódicas dentro del proceso de la API
+package scheduler
+
+import (
+	"context" // Manejo de contexto en funciones
+	"log"     // Registro de fallos de las tareas
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+)
+
+// JobFunc es una tarea periódica; now es la hora del reloj del planificador en esa ejecución
+type JobFunc func(ctx context.Context, now time.Time) error
+
+// Tarea registrada en el planificador
+type job struct {
+	name     string        // Nombre para los registros
+	interval time.Duration // Tiempo entre ejecuciones
+	run      JobFunc       // Función a ejecutar
+}
+
+// Scheduler ejecuta tareas registradas cada cierto intervalo hasta que se cancele su contexto
+type Scheduler struct {
+	clock Clock // Fuente de tiempo
+	mu    sync.Mutex
+	jobs  []job // Tareas registradas
+}
+
+// Constructor del planificador; sin reloj usa el del sistema
+func New(clock Clock) *Scheduler {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+	return &Scheduler{clock: clock}
+}
+
+// Registra una tarea que se ejecuta cada intervalo (debe llamarse antes de Run)
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Ejecuta las tareas hasta que se cancele el contexto; retorna cuando todas terminaron su ejecución en curso
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// Ciclo de una tarea: espera el intervalo, ejecuta y vuelve a esperar
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-s.clock.After(j.interval):
+			if ctx.Err() != nil {
+				return // No empezar una ejecución si el servidor ya se está deteniendo
+			}
+			if err := j.run(ctx, now); err != nil {
+				log.Printf("La tarea programada %s falló: %v\n", j.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Pruebas del planificador con el reloj falso
+package scheduler
+
+import (
+	"context" // Cancelación del planificador
+	"testing" // Paquete de pruebas
+	"time"    // Manejo de tiempos y fechas
+)
+
+// Espera a que haya n esperas registradas en el reloj (las tareas terminaron su ejecución y volvieron a esperar)
+func waitForWaiters(t *testing.T, clock *FakeClock, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for clock.Waiters() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("esperas registradas = %d, se esperaban %d", clock.Waiters(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsJobsWhenClockAdvances(t *testing.T) {
+	start := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	s := New(clock)
+	runs := make(chan time.Time, 10)
+	s.Every("prueba", time.Minute, func(ctx context.Context, now time.Time) error {
+		runs <- now
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	waitForWaiters(t, clock, 1)
+
+	clock.Advance(30 * time.Second)
+	if len(runs) != 0 {
+		t.Fatalf("la tarea corrió antes de cumplirse el intervalo")
+	}
+	clock.Advance(30 * time.Second)
+	if got := <-runs; !got.Equal(start.Add(time.Minute)) {
+		t.Fatalf("now = %v, se esperaba %v", got, start.Add(time.Minute))
+	}
+	waitForWaiters(t, clock, 1)
+	clock.Advance(time.Minute)
+	if got := <-runs; !got.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("now = %v, se esperaba %v", got, start.Add(2*time.Minute))
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run no terminó al cancelar el contexto")
+	}
+}
+
+func TestFakeClockAfter(t *testing.T) {
+	clock := NewFakeClock(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	if got := <-clock.After(0); !got.Equal(clock.Now()) {
+		t.Fatalf("After(0) = %v, se esperaba la hora actual", got)
+	}
+	ch := clock.After(time.Hour)
+	clock.Advance(59 * time.Minute)
+	select {
+	case <-ch:
+		t.Fatal("la espera venció antes de tiempo")
+	default:
+	}
+	clock.Advance(time.Minute)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("la espera no venció al cumplirse la hora")
+	}
+	if clock.Waiters() != 0 {
+		t.Fatalf("quedaron %d esperas pendientes", clock.Waiters())
+	}
+}
@@ -0,0 +1,21 @@
+// Paquete para manejo de envíos (despachos parciales de una orden)
+package shipments
+
+// Estructura que representa la solicitud para crear un envío
+type ShipmentRequest struct {
+	Lines          []ShipmentLineRequest `json:"lines"`                     // Líneas y unidades (vacío = todo lo pendiente)
+	Carrier        string                `json:"carrier,omitempty"`         // Transportadora
+	TrackingNumber string                `json:"tracking_number,omitempty"` // Número de guía; si se indica, el envío sale despachado
+}
+
+// Estructura que representa una línea dentro de la solicitud de envío
+type ShipmentLineRequest struct {
+	Line     int `json:"line"`     // Número de línea de la orden
+	Quantity int `json:"quantity"` // Unidades a enviar
+}
+
+// Estructura que representa los datos para despachar un envío pendiente
+type ShipRequest struct {
+	Carrier        string `json:"carrier"`         // Transportadora
+	TrackingNumber string `json:"tracking_number"` // Número de guía
+}
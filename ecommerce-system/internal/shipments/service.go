@@ -0,0 +1,324 @@
+// Paquete para manejo de envíos (despachos parciales de una orden)
+package shipments
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de IDs y errores
+	"log"     // Registro de fallas del rastreo periódico
+	"sort"    // Ordenamiento de resultados
+	"strings" // Limpieza de datos de la transportadora
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/carriers" // Adaptadores de transportadoras
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"   // Servicio de órdenes
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de envíos
+type Service interface {
+	CreateShipment(ctx context.Context, orderID string, req ShipmentRequest) (*Shipment, error) // Armar un paquete con unidades de la orden
+	Ship(ctx context.Context, id string, req ShipRequest) (*Shipment, error)                    // Despachar un envío pendiente
+	Deliver(ctx context.Context, id string) (*Shipment, error)                                  // Confirmar la entrega
+	SyncTracking(ctx context.Context, id string) (*Shipment, error)                             // Consultar el rastreo en la transportadora
+	GetShipment(ctx context.Context, id string) (*Shipment, error)                              // Obtener envío por ID
+	GetLabel(ctx context.Context, id string) (*carriers.Label, error)                           // Guía generada por la transportadora
+	Run(ctx context.Context, interval time.Duration)                                            // Consulta periódica del rastreo de los envíos en camino
+	ListByOrder(ctx context.Context, orderID string) ([]Shipment, error)                        // Envíos de una orden
+}
+
+// Implementación en memoria del repositorio de envíos
+type inMemoryRepository struct {
+	shipments map[string]Shipment // Envíos indexados por ID
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{shipments: make(map[string]Shipment)}
+}
+
+// Implementación del servicio de envíos
+type shipmentService struct {
+	mu           sync.Mutex                  // Serializa los envíos para no despachar dos veces las mismas unidades
+	repo         *inMemoryRepository         // Repositorio interno
+	orderService orders.Service              // Órdenes despachadas
+	carriers     map[string]carriers.Carrier // Adaptadores de transportadoras por código
+}
+
+// Option configura dependencias opcionales del servicio de envíos
+type Option func(*shipmentService)
+
+// Opción que registra adaptadores de transportadoras: generan la guía al despachar y reportan el rastreo
+func WithCarriers(adapters ...carriers.Carrier) Option {
+	return func(s *shipmentService) {
+		for _, c := range adapters {
+			s.carriers[strings.ToLower(c.Code())] = c
+		}
+	}
+}
+
+// Constructor para crear un nuevo servicio de envíos
+func NewService(repo *inMemoryRepository, ordService orders.Service, opts ...Option) Service {
+	s := &shipmentService{repo: repo, orderService: ordService, carriers: make(map[string]carriers.Carrier)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Armar un envío con unidades pendientes de una orden pagada; sale despachado si trae guía
+// o si la transportadora tiene integración (que genera la guía)
+func (s *shipmentService) CreateShipment(ctx context.Context, orderID string, req ShipmentRequest) (*Shipment, error) {
+	carrier, tracking := strings.TrimSpace(req.Carrier), strings.TrimSpace(req.TrackingNumber)
+	if tracking != "" && carrier == "" {
+		return nil, ErrMissingTracking
+	}
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.IsPaid() || (order.Status != orders.StatusProcessed && order.Status != orders.StatusPartial) {
+		return nil, ErrNotShippable
+	}
+	if order.ShippingAddress == nil {
+		return nil, fmt.Errorf("%w: order has no shipping address", ErrNotShippable)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assigned := s.assignedQuantities(orderID) // Unidades ya incluidas en otros envíos
+	shipment := Shipment{
+		ID:             fmt.Sprintf("SHP-%06d", len(s.repo.shipments)+1),
+		OrderID:        orderID,
+		Carrier:        carrier,
+		TrackingNumber: tracking,
+		Status:         StatusPending,
+		CreatedAt:      time.Now(),
+	}
+	if len(req.Lines) == 0 {
+		for _, item := range order.LineItems {
+			if left := item.Quantity - item.RefundedQuantity - assigned[item.Line]; left > 0 {
+				shipment.Lines = append(shipment.Lines, ShipmentLine{Line: item.Line, ProductID: item.ProductID, Quantity: left})
+			}
+		}
+		if len(shipment.Lines) == 0 {
+			return nil, fmt.Errorf("%w: nothing left to ship", ErrInvalidShipment)
+		}
+	}
+	for _, l := range req.Lines {
+		item, ok := order.LineByNumber(l.Line)
+		if !ok || l.Quantity <= 0 || assigned[l.Line]+l.Quantity > item.Quantity-item.RefundedQuantity {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidShipment, l.Line)
+		}
+		assigned[l.Line] += l.Quantity
+		shipment.Lines = append(shipment.Lines, ShipmentLine{Line: l.Line, ProductID: item.ProductID, Quantity: l.Quantity})
+	}
+
+	if _, integrated := s.adapter(carrier); tracking != "" || integrated {
+		if err := s.ship(ctx, &shipment); err != nil {
+			return nil, err
+		}
+	}
+	s.repo.shipments[shipment.ID] = shipment
+	return &shipment, nil
+}
+
+// Despachar un envío pendiente con su transportadora y guía (la guía es opcional si la transportadora tiene integración)
+func (s *shipmentService) Ship(ctx context.Context, id string, req ShipRequest) (*Shipment, error) {
+	carrier, tracking := strings.TrimSpace(req.Carrier), strings.TrimSpace(req.TrackingNumber)
+	if _, integrated := s.adapter(carrier); carrier == "" || (tracking == "" && !integrated) {
+		return nil, ErrMissingTracking
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shipment, ok := s.repo.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	if shipment.Status != StatusPending {
+		return nil, ErrInvalidTransition
+	}
+	shipment.Carrier, shipment.TrackingNumber = carrier, tracking
+	if err := s.ship(ctx, &shipment); err != nil {
+		return nil, err
+	}
+	s.repo.shipments[shipment.ID] = shipment
+	return &shipment, nil
+}
+
+// Confirmar la entrega de un envío despachado
+func (s *shipmentService) Deliver(ctx context.Context, id string) (*Shipment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shipment, ok := s.repo.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	if shipment.Status != StatusShipped {
+		return nil, ErrInvalidTransition
+	}
+	if err := s.deliver(ctx, &shipment, time.Now()); err != nil {
+		return nil, err
+	}
+	s.repo.shipments[shipment.ID] = shipment
+	return &shipment, nil
+}
+
+// Consultar los eventos de rastreo en la transportadora; un evento de entrega confirma el envío y la orden
+func (s *shipmentService) SyncTracking(ctx context.Context, id string) (*Shipment, error) {
+	s.mu.Lock()
+	shipment, ok := s.repo.shipments[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	adapter, integrated := s.adapter(shipment.Carrier)
+	if !integrated {
+		return nil, ErrNoCarrierAdapter
+	}
+	if shipment.Status == StatusPending {
+		return nil, ErrInvalidTransition
+	}
+	events, err := adapter.Track(ctx, shipment.TrackingNumber) // Sin bloquear el servicio durante la llamada externa
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shipment = s.repo.shipments[id] // Releer por si cambió durante la consulta
+	shipment.TrackingEvents = events
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		shipment.TrackingStatus = last.Status
+		if last.Status == carriers.StatusDelivered && shipment.Status == StatusShipped {
+			if err := s.deliver(ctx, &shipment, last.At); err != nil {
+				return nil, err
+			}
+		}
+	}
+	s.repo.shipments[shipment.ID] = shipment
+	return &shipment, nil
+}
+
+// Obtener la guía de un envío despachado con una transportadora integrada
+func (s *shipmentService) GetLabel(ctx context.Context, id string) (*carriers.Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shipment, ok := s.repo.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	if shipment.Label == nil {
+		return nil, ErrNoCarrierAdapter
+	}
+	return shipment.Label, nil
+}
+
+// Consulta el rastreo de los envíos en camino cada intervalo hasta que se cancele el contexto
+func (s *shipmentService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			var ids []string
+			for _, sh := range s.repo.shipments {
+				if _, integrated := s.adapter(sh.Carrier); integrated && sh.Status == StatusShipped {
+					ids = append(ids, sh.ID)
+				}
+			}
+			s.mu.Unlock()
+			for _, id := range ids {
+				if _, err := s.SyncTracking(ctx, id); err != nil {
+					log.Printf("No se pudo actualizar el rastreo del envío %s: %v\n", id, err)
+				}
+			}
+		}
+	}
+}
+
+// Obtener un envío por su ID
+func (s *shipmentService) GetShipment(ctx context.Context, id string) (*Shipment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shipment, ok := s.repo.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	return &shipment, nil
+}
+
+// Listar los envíos de una orden, ordenados por ID
+func (s *shipmentService) ListByOrder(ctx context.Context, orderID string) ([]Shipment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Shipment{}
+	for _, sh := range s.repo.shipments {
+		if sh.OrderID == orderID {
+			list = append(list, sh)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// Marca el envío como despachado y registra sus unidades en la orden; sin guía, la genera la transportadora
+// integrada (requiere s.mu tomado)
+func (s *shipmentService) ship(ctx context.Context, shipment *Shipment) error {
+	if shipment.TrackingNumber == "" {
+		adapter, _ := s.adapter(shipment.Carrier)
+		order, err := s.orderService.GetOrderByID(ctx, shipment.OrderID)
+		if err != nil {
+			return err
+		}
+		req := carriers.LabelRequest{Reference: shipment.ID, To: *order.ShippingAddress}
+		for _, l := range shipment.Lines {
+			if item, ok := order.LineByNumber(l.Line); ok {
+				req.WeightKg += item.Product.WeightKg * float64(l.Quantity)
+			}
+		}
+		label, err := adapter.CreateLabel(ctx, req)
+		if err != nil {
+			return err
+		}
+		shipment.Label, shipment.TrackingNumber = label, label.TrackingNumber
+		shipment.TrackingStatus = carriers.StatusLabelCreated
+	}
+	if _, err := s.orderService.RecordFulfillment(ctx, shipment.OrderID, shipment.quantities(), nil); err != nil {
+		return err
+	}
+	now := time.Now()
+	shipment.Status, shipment.ShippedAt = StatusShipped, &now
+	return nil
+}
+
+// Marca el envío como entregado y registra sus unidades en la orden (requiere s.mu tomado)
+func (s *shipmentService) deliver(ctx context.Context, shipment *Shipment, at time.Time) error {
+	if _, err := s.orderService.RecordFulfillment(ctx, shipment.OrderID, nil, shipment.quantities()); err != nil {
+		return err
+	}
+	shipment.Status, shipment.DeliveredAt = StatusDelivered, &at
+	return nil
+}
+
+// Busca el adaptador de la transportadora del envío
+func (s *shipmentService) adapter(carrier string) (carriers.Carrier, bool) {
+	c, ok := s.carriers[strings.ToLower(carrier)]
+	return c, ok
+}
+
+// Unidades por número de línea incluidas en envíos de una orden (requiere s.mu tomado)
+func (s *shipmentService) assignedQuantities(orderID string) map[int]int {
+	assigned := make(map[int]int)
+	for _, sh := range s.repo.shipments {
+		if sh.OrderID == orderID {
+			for _, l := range sh.Lines {
+				assigned[l.Line] += l.Quantity
+			}
+		}
+	}
+	return assigned
+}
@@ -0,0 +1,166 @@
+// Pruebas de los envíos parciales y del estado derivado de la orden
+package shipments_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"testing" // Paquete de pruebas
+	"time"    // Separación de IDs basados en la hora
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+)
+
+// Crea una orden con 3 unidades de café y 1 de té; si pay es true la cobra por completo
+func newOrder(t *testing.T, pay bool) (orders.Service, *orders.Order) {
+	t.Helper()
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	coffee, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	time.Sleep(time.Millisecond) // Los IDs de producto se basan en la hora
+	tea, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Té", SKU: "TE-1", Price: 5, Stock: 10})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	orderService := orders.NewService(orders.NewInMemoryRepository(), productService)
+	order, err := orderService.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: coffee.ID, Quantity: 3}, {ProductID: tea.ID, Quantity: 1}},
+		ShippingAddress: &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if pay {
+		paymentService := payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), orderService)
+		if _, err := paymentService.Authorize(ctx, order.ID, payments.PaymentRequest{Method: "tok_visa", Capture: true}); err != nil {
+			t.Fatalf("Authorize: %v", err)
+		}
+	}
+	return orderService, order
+}
+
+func TestPartialShipmentsDeriveOrderStatus(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t, true)
+	svc := shipments.NewService(shipments.NewInMemoryRepository(), orderService)
+
+	// Primer paquete: 2 cafés, despachado al indicar la guía
+	first, err := svc.CreateShipment(ctx, order.ID, shipments.ShipmentRequest{
+		Lines:          []shipments.ShipmentLineRequest{{Line: 1, Quantity: 2}},
+		Carrier:        "servientrega",
+		TrackingNumber: "SV-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateShipment: %v", err)
+	}
+	if first.Status != shipments.StatusShipped {
+		t.Fatalf("estado = %s, se esperaba %s", first.Status, shipments.StatusShipped)
+	}
+	if o, _ := orderService.GetOrderByID(ctx, order.ID); o.Status != orders.StatusPartial {
+		t.Fatalf("orden en %s, se esperaba %s", o.Status, orders.StatusPartial)
+	}
+
+	// Segundo paquete sin líneas: toma todo lo pendiente y queda sin despachar
+	second, err := svc.CreateShipment(ctx, order.ID, shipments.ShipmentRequest{})
+	if err != nil {
+		t.Fatalf("CreateShipment: %v", err)
+	}
+	if second.Status != shipments.StatusPending || len(second.Lines) != 2 || second.Lines[0].Quantity != 1 || second.Lines[1].Quantity != 1 {
+		t.Fatalf("segundo envío = %+v, se esperaba pendiente con 1 café y 1 té", second)
+	}
+	if _, err := svc.CreateShipment(ctx, order.ID, shipments.ShipmentRequest{}); !errors.Is(err, shipments.ErrInvalidShipment) {
+		t.Fatalf("tercer envío: err = %v, se esperaba %v", err, shipments.ErrInvalidShipment)
+	}
+
+	if _, err := svc.Ship(ctx, second.ID, shipments.ShipRequest{Carrier: "servientrega"}); !errors.Is(err, shipments.ErrMissingTracking) {
+		t.Fatalf("Ship sin guía: err = %v, se esperaba %v", err, shipments.ErrMissingTracking)
+	}
+	if _, err := svc.Ship(ctx, second.ID, shipments.ShipRequest{Carrier: "servientrega", TrackingNumber: "SV-2"}); err != nil {
+		t.Fatalf("Ship: %v", err)
+	}
+	if o, _ := orderService.GetOrderByID(ctx, order.ID); o.Status != orders.StatusShipped {
+		t.Fatalf("orden en %s, se esperaba %s", o.Status, orders.StatusShipped)
+	}
+
+	// La orden queda entregada solo cuando llegan todos los paquetes
+	if _, err := svc.Deliver(ctx, first.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if o, _ := orderService.GetOrderByID(ctx, order.ID); o.Status != orders.StatusShipped {
+		t.Fatalf("orden en %s, se esperaba %s", o.Status, orders.StatusShipped)
+	}
+	if _, err := svc.Deliver(ctx, second.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	o, _ := orderService.GetOrderByID(ctx, order.ID)
+	if o.Status != orders.StatusDelivered || o.DeliveredAt == nil {
+		t.Fatalf("orden en %s (entregada %v), se esperaba %s con fecha", o.Status, o.DeliveredAt, orders.StatusDelivered)
+	}
+	if list, _ := svc.ListByOrder(ctx, order.ID); len(list) != 2 {
+		t.Fatalf("envíos de la orden = %d, se esperaba 2", len(list))
+	}
+}
+
+func TestCreateShipmentValidation(t *testing.T) {
+	ctx := context.Background()
+	orderService, paid := newOrder(t, true)
+	unpaidService, unpaid := newOrder(t, false)
+	tests := []struct {
+		name    string
+		service orders.Service
+		orderID string
+		req     shipments.ShipmentRequest
+		want    error
+	}{
+		{name: "orden sin pagar", service: unpaidService, orderID: unpaid.ID, want: shipments.ErrNotShippable},
+		{name: "guía sin transportadora", service: orderService, orderID: paid.ID, req: shipments.ShipmentRequest{TrackingNumber: "SV-1"}, want: shipments.ErrMissingTracking},
+		{name: "más unidades que las compradas", service: orderService, orderID: paid.ID, req: shipments.ShipmentRequest{Lines: []shipments.ShipmentLineRequest{{Line: 1, Quantity: 4}}}, want: shipments.ErrInvalidShipment},
+		{name: "línea inexistente", service: orderService, orderID: paid.ID, req: shipments.ShipmentRequest{Lines: []shipments.ShipmentLineRequest{{Line: 3, Quantity: 1}}}, want: shipments.ErrInvalidShipment},
+		{name: "cantidad cero", service: orderService, orderID: paid.ID, req: shipments.ShipmentRequest{Lines: []shipments.ShipmentLineRequest{{Line: 1, Quantity: 0}}}, want: shipments.ErrInvalidShipment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := shipments.NewService(shipments.NewInMemoryRepository(), tt.service)
+			if _, err := svc.CreateShipment(ctx, tt.orderID, tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, se esperaba %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestShipmentTransitions(t *testing.T) {
+	ctx := context.Background()
+	orderService, order := newOrder(t, true)
+	svc := shipments.NewService(shipments.NewInMemoryRepository(), orderService)
+	shipment, err := svc.CreateShipment(ctx, order.ID, shipments.ShipmentRequest{})
+	if err != nil {
+		t.Fatalf("CreateShipment: %v", err)
+	}
+
+	// Un envío pendiente no puede entregarse; uno despachado no puede despacharse otra vez
+	if _, err := svc.Deliver(ctx, shipment.ID); !errors.Is(err, shipments.ErrInvalidTransition) {
+		t.Fatalf("Deliver pendiente: err = %v, se esperaba %v", err, shipments.ErrInvalidTransition)
+	}
+	if _, err := svc.Ship(ctx, shipment.ID, shipments.ShipRequest{Carrier: "servientrega", TrackingNumber: "SV-1"}); err != nil {
+		t.Fatalf("Ship: %v", err)
+	}
+	if _, err := svc.Ship(ctx, shipment.ID, shipments.ShipRequest{Carrier: "servientrega", TrackingNumber: "SV-1"}); !errors.Is(err, shipments.ErrInvalidTransition) {
+		t.Fatalf("Ship repetido: err = %v, se esperaba %v", err, shipments.ErrInvalidTransition)
+	}
+	if _, err := svc.Deliver(ctx, shipment.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if _, err := svc.Deliver(ctx, shipment.ID); !errors.Is(err, shipments.ErrInvalidTransition) {
+		t.Fatalf("Deliver repetido: err = %v, se esperaba %v", err, shipments.ErrInvalidTransition)
+	}
+	if _, err := svc.GetShipment(ctx, "SHP-999999"); !errors.Is(err, shipments.ErrShipmentNotFound) {
+		t.Fatalf("GetShipment: err = %v, se esperaba %v", err, shipments.ErrShipmentNotFound)
+	}
+}
@@ -0,0 +1,61 @@
+// Paquete para manejo de envíos (despachos parciales de una orden)
+package shipments
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/carriers" // Rastreo de la transportadora
+)
+
+// Status representa el estado de un envío
+type Status string
+
+// Constantes que definen los estados de un envío
+const (
+	StatusPending   Status = "pending"   // Paquete preparado, aún sin despachar
+	StatusShipped   Status = "shipped"   // Entregado a la transportadora
+	StatusDelivered Status = "delivered" // Recibido por el cliente
+)
+
+// ShipmentLine es una línea de la orden incluida en el paquete
+type ShipmentLine struct {
+	Line      int    `json:"line"`       // Número de línea de la orden
+	ProductID string `json:"product_id"` // Producto de la línea
+	Quantity  int    `json:"quantity"`   // Unidades del paquete
+}
+
+// Shipment es un paquete con parte (o todas) las unidades de una orden
+type Shipment struct {
+	ID             string                   `json:"id"`                        // ID único del envío
+	OrderID        string                   `json:"order_id"`                  // Orden a la que pertenece
+	Lines          []ShipmentLine           `json:"lines"`                     // Líneas y unidades del paquete
+	Carrier        string                   `json:"carrier,omitempty"`         // Transportadora
+	TrackingNumber string                   `json:"tracking_number,omitempty"` // Número de guía
+	Status         Status                   `json:"status"`                    // Estado actual
+	TrackingStatus carriers.Status          `json:"tracking_status,omitempty"` // Último estado reportado por la transportadora
+	TrackingEvents []carriers.TrackingEvent `json:"tracking_events,omitempty"` // Eventos de rastreo
+	Label          *carriers.Label          `json:"label,omitempty"`           // Guía generada por la transportadora
+	CreatedAt      time.Time                `json:"created_at"`                // Fecha de creación
+	ShippedAt      *time.Time               `json:"shipped_at,omitempty"`      // Fecha de despacho
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`    // Fecha de entrega
+}
+
+// Método que retorna las unidades del envío por número de línea
+func (s *Shipment) quantities() map[int]int {
+	q := make(map[int]int, len(s.Lines))
+	for _, l := range s.Lines {
+		q[l.Line] += l.Quantity
+	}
+	return q
+}
+
+// Errores del servicio de envíos
+var (
+	ErrShipmentNotFound  = errors.New("shipment not found")
+	ErrInvalidShipment   = errors.New("invalid shipment")
+	ErrNotShippable      = errors.New("order cannot be shipped in its current status")
+	ErrMissingTracking   = errors.New("carrier and tracking number are required to ship")
+	ErrInvalidTransition = errors.New("operation not allowed in the current shipment status")
+	ErrNoCarrierAdapter  = errors.New("shipment carrier has no tracking integration")
+)
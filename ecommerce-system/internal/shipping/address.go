@@ -0,0 +1,74 @@
+// Paquete para direcciones de envío y cálculo de tarifas por zona
+package shipping
+
+import (
+	"errors"  // Manejo de errores
+	"fmt"     // Formateo de errores
+	"regexp"  // Formatos de código postal
+	"strings" // Normalización de campos
+)
+
+// Address es una dirección postal de envío o facturación
+type Address struct {
+	Name       string `json:"name"`             // Destinatario o razón social
+	Line1      string `json:"line1"`            // Calle y número
+	Line2      string `json:"line2,omitempty"`  // Departamento, piso, referencia
+	City       string `json:"city"`             // Ciudad
+	Region     string `json:"region,omitempty"` // Provincia o estado
+	PostalCode string `json:"postal_code"`      // Código postal
+	Country    string `json:"country"`          // Código ISO 3166-1 alfa-2 (EC, US...)
+	Phone      string `json:"phone,omitempty"`  // Teléfono de contacto
+}
+
+// countryFormat describe las reglas de dirección de un país
+type countryFormat struct {
+	postalCode     *regexp.Regexp // Formato del código postal
+	regionRequired bool           // El país exige provincia o estado
+}
+
+// Formatos de dirección por país admitido
+var countryFormats = map[string]countryFormat{
+	"EC": {postalCode: regexp.MustCompile(`^\d{6}$`)},
+	"CO": {postalCode: regexp.MustCompile(`^\d{6}$`)},
+	"PE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"CL": {postalCode: regexp.MustCompile(`^\d{7}$`)},
+	"AR": {postalCode: regexp.MustCompile(`^([A-Z]\d{4}[A-Z]{3}|\d{4})$`)},
+	"MX": {postalCode: regexp.MustCompile(`^\d{5}$`), regionRequired: true},
+	"ES": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"US": {postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`), regionRequired: true},
+	"CA": {postalCode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`), regionRequired: true},
+}
+
+// Errores de direcciones
+var (
+	ErrInvalidAddress     = errors.New("invalid address")
+	ErrUnsupportedCountry = errors.New("country is not supported")
+	ErrAddressRequired    = errors.New("shipping address is required")
+)
+
+// Método que retorna la dirección sin espacios sobrantes y con país y código postal en mayúsculas
+func (a Address) Normalized() Address {
+	a.Name, a.Line1, a.Line2 = strings.TrimSpace(a.Name), strings.TrimSpace(a.Line1), strings.TrimSpace(a.Line2)
+	a.City, a.Region, a.Phone = strings.TrimSpace(a.City), strings.TrimSpace(a.Region), strings.TrimSpace(a.Phone)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	return a
+}
+
+// Método que valida los campos obligatorios y el formato del país
+func (a Address) Validate() error {
+	if a.Name == "" || a.Line1 == "" || a.City == "" || a.Country == "" {
+		return fmt.Errorf("%w: name, line1, city and country are required", ErrInvalidAddress)
+	}
+	format, ok := countryFormats[a.Country]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCountry, a.Country)
+	}
+	if !format.postalCode.MatchString(a.PostalCode) {
+		return fmt.Errorf("%w: postal code %q does not match the format for %s", ErrInvalidAddress, a.PostalCode, a.Country)
+	}
+	if format.regionRequired && a.Region == "" {
+		return fmt.Errorf("%w: region is required for %s", ErrInvalidAddress, a.Country)
+	}
+	return nil
+}
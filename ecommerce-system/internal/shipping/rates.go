@@ -0,0 +1,183 @@
+// Paquete para direcciones de envío y cálculo de tarifas por zona
+package shipping
+
+import (
+	"encoding/json" // Lectura del archivo de tarifas
+	"errors"        // Manejo de errores
+	"fmt"           // Formateo de errores y descripciones
+	"math"          // Redondeo y kilos adicionales
+	"os"            // Lectura de archivos
+	"strings"       // Comparación de países
+)
+
+// RateType indica cómo se cobra el envío en una zona
+type RateType string
+
+// Constantes que definen los tipos de tarifa
+const (
+	RateFlat   RateType = "flat"   // Tarifa fija por orden
+	RateWeight RateType = "weight" // Tarifa según el peso facturable
+)
+
+// DefaultVolumetricDivisor convierte cm³ en kg de peso volumétrico (estándar de transportadoras)
+const DefaultVolumetricDivisor = 5000
+
+// WeightBracket es un tramo de la tabla de pesos
+type WeightBracket struct {
+	UpToKg float64 `json:"up_to_kg"` // Peso máximo del tramo
+	Price  float64 `json:"price"`    // Precio del tramo
+}
+
+// Zone agrupa países con la misma tabla de tarifas
+type Zone struct {
+	Name       string          `json:"name"`                   // Nombre de la zona
+	Countries  []string        `json:"countries"`              // Códigos de país ("*" = resto del mundo)
+	Type       RateType        `json:"type"`                   // flat o weight
+	FlatRate   float64         `json:"flat_rate,omitempty"`    // Tarifa fija (type flat)
+	Brackets   []WeightBracket `json:"brackets,omitempty"`     // Tramos de peso ordenados (type weight)
+	ExtraPerKg float64         `json:"extra_per_kg,omitempty"` // Precio por kg adicional sobre el último tramo (0 = no se envía)
+	FreeAbove  float64         `json:"free_above,omitempty"`   // Monto neto desde el que el envío es gratis (0 = nunca)
+}
+
+// RateTable es la configuración de tarifas de envío
+type RateTable struct {
+	VolumetricDivisor float64 `json:"volumetric_divisor,omitempty"` // cm³ por kg volumétrico (0 = 5000)
+	Zones             []Zone  `json:"zones"`                        // Zonas; gana la primera que incluye el país
+}
+
+// Parcel describe las unidades de una línea a enviar
+type Parcel struct {
+	WeightKg float64 // Peso unitario
+	LengthCm float64 // Largo unitario
+	WidthCm  float64 // Ancho unitario
+	HeightCm float64 // Alto unitario
+	Quantity int     // Unidades
+}
+
+// Quote es el costo de envío calculado para una orden
+type Quote struct {
+	Zone             string  // Zona aplicada
+	BillableWeightKg float64 // Peso facturable (real o volumétrico, el mayor)
+	Amount           float64 // Costo del envío
+	Description      string  // Detalle legible para el desglose
+}
+
+// Errores de tarifas
+var (
+	ErrInvalidRateTable = errors.New("invalid shipping rate table")
+	ErrNoShippingZone   = errors.New("no shipping zone covers the destination country")
+	ErrOverweight       = errors.New("order exceeds the maximum shipping weight for the zone")
+)
+
+// Lee una tabla de tarifas desde un archivo JSON y la valida
+func LoadFile(path string) (*RateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t RateTable
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// Método que valida la configuración de zonas y tramos
+func (t *RateTable) Validate() error {
+	if t.VolumetricDivisor < 0 || len(t.Zones) == 0 {
+		return ErrInvalidRateTable
+	}
+	for _, z := range t.Zones {
+		if z.Name == "" || len(z.Countries) == 0 || z.FreeAbove < 0 {
+			return fmt.Errorf("%w: zone %q", ErrInvalidRateTable, z.Name)
+		}
+		switch z.Type {
+		case RateFlat:
+			if z.FlatRate < 0 {
+				return fmt.Errorf("%w: zone %q has a negative flat rate", ErrInvalidRateTable, z.Name)
+			}
+		case RateWeight:
+			if len(z.Brackets) == 0 || z.ExtraPerKg < 0 {
+				return fmt.Errorf("%w: zone %q needs weight brackets", ErrInvalidRateTable, z.Name)
+			}
+			for i, b := range z.Brackets {
+				if b.UpToKg <= 0 || b.Price < 0 || (i > 0 && b.UpToKg <= z.Brackets[i-1].UpToKg) {
+					return fmt.Errorf("%w: zone %q brackets must be positive and ascending", ErrInvalidRateTable, z.Name)
+				}
+			}
+		default:
+			return fmt.Errorf("%w: zone %q has unknown type %q", ErrInvalidRateTable, z.Name, z.Type)
+		}
+	}
+	return nil
+}
+
+// Método que busca la zona de un país; "*" actúa como comodín
+func (t *RateTable) ZoneFor(country string) (*Zone, bool) {
+	for i, z := range t.Zones {
+		for _, c := range z.Countries {
+			if c == "*" || strings.EqualFold(c, country) {
+				return &t.Zones[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Método que calcula el envío de los paquetes hacia un país; orderAmount es el monto neto de la orden
+func (t *RateTable) Quote(country string, parcels []Parcel, orderAmount float64) (*Quote, error) {
+	zone, ok := t.ZoneFor(country)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoShippingZone, country)
+	}
+	q := &Quote{Zone: zone.Name, BillableWeightKg: t.billableWeight(parcels)}
+	if zone.FreeAbove > 0 && orderAmount >= zone.FreeAbove {
+		q.Description = fmt.Sprintf("%s: free above %.2f", zone.Name, zone.FreeAbove)
+		return q, nil
+	}
+	switch zone.Type {
+	case RateFlat:
+		q.Amount = zone.FlatRate
+		q.Description = fmt.Sprintf("%s: flat rate", zone.Name)
+	case RateWeight:
+		amount, err := zone.weightPrice(q.BillableWeightKg)
+		if err != nil {
+			return nil, err
+		}
+		q.Amount = amount
+		q.Description = fmt.Sprintf("%s: %.2f kg", zone.Name, q.BillableWeightKg)
+	}
+	q.Amount = math.Round(q.Amount*100) / 100
+	return q, nil
+}
+
+// Método que suma el peso facturable: por unidad, el mayor entre el peso real y el volumétrico
+func (t *RateTable) billableWeight(parcels []Parcel) float64 {
+	divisor := t.VolumetricDivisor
+	if divisor == 0 {
+		divisor = DefaultVolumetricDivisor
+	}
+	var total float64
+	for _, p := range parcels {
+		volumetric := p.LengthCm * p.WidthCm * p.HeightCm / divisor
+		total += math.Max(p.WeightKg, volumetric) * float64(p.Quantity)
+	}
+	return math.Round(total*1000) / 1000
+}
+
+// Método que busca el precio del tramo que cubre el peso; sobre el último tramo cobra los kg adicionales
+func (z *Zone) weightPrice(weight float64) (float64, error) {
+	for _, b := range z.Brackets {
+		if weight <= b.UpToKg {
+			return b.Price, nil
+		}
+	}
+	last := z.Brackets[len(z.Brackets)-1]
+	if z.ExtraPerKg == 0 {
+		return 0, fmt.Errorf("%w: %.2f kg over %.2f kg", ErrOverweight, weight, last.UpToKg)
+	}
+	return last.Price + math.Ceil(weight-last.UpToKg)*z.ExtraPerKg, nil
+}
@@ -0,0 +1,27 @@
+// Paquete para manejo de suscripciones que generan órdenes periódicas
+package subscriptions
+
+import (
+	"time" // Fecha de inicio
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"     // Líneas de la orden
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Ubicación de entrega
+)
+
+// Estructura que representa la solicitud para crear una suscripción
+type SubscriptionRequest struct {
+	Items         []orders.LineItemRequest `json:"items"`                    // Productos y cantidades de cada orden
+	Interval      Interval                 `json:"interval"`                 // weekly o monthly
+	StartAt       *time.Time               `json:"start_at,omitempty"`       // Primera entrega (por defecto, ahora)
+	PaymentMethod string                   `json:"payment_method,omitempty"` // Token para cobrar cada orden (opcional)
+	Destination   *warehouses.Location     `json:"destination,omitempty"`    // Ubicación de entrega (opcional)
+	// Direcciones de entrega (obligatoria) y facturación (opcional)
+	ShippingAddress *shipping.Address `json:"shipping_address,omitempty"`
+	BillingAddress  *shipping.Address `json:"billing_address,omitempty"`
+}
+
+// Estructura que representa la solicitud para saltar entregas
+type SkipRequest struct {
+	Count int `json:"count,omitempty"` // Entregas a saltar (por defecto 1)
+}
@@ -0,0 +1,382 @@
+// Paquete para manejo de suscripciones que generan órdenes periódicas
+package subscriptions
+
+import (
+	"context" // Manejo de contexto en funciones
+	"errors"  // Comparación de errores
+	"fmt"     // Formateo de IDs y mensajes
+	"log"     // Registro de fallas al notificar o cancelar
+	"sort"    // Ordenamiento de resultados
+	"strconv" // Conversión de números en notificaciones
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/notifications" // Avisos al cliente
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"        // Servicio de órdenes
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"      // Cobro de cada orden
+)
+
+// Tipos de notificación que envía el servicio
+const (
+	KindOrderCreated = "subscription_order_created" // Se generó la orden del período
+	KindRetry        = "subscription_retry"         // Falló la entrega y se reintentará
+	KindPaused       = "subscription_paused"        // Se agotaron los reintentos y la suscripción quedó en pausa
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de suscripciones
+type Service interface {
+	CreateSubscription(ctx context.Context, userID string, req SubscriptionRequest) (*Subscription, error) // Crear suscripción
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)                                 // Obtener suscripción por ID
+	ListByUser(ctx context.Context, userID string) ([]Subscription, error)                                 // Suscripciones de un usuario
+	Pause(ctx context.Context, id string) (*Subscription, error)                                           // Pausar
+	Resume(ctx context.Context, id string) (*Subscription, error)                                          // Reanudar
+	Skip(ctx context.Context, id string, count int) (*Subscription, error)                                 // Saltar las próximas entregas
+	Cancel(ctx context.Context, id string) (*Subscription, error)                                          // Cancelar
+	RunDue(ctx context.Context, now time.Time) error                                                       // Generar las órdenes vencidas (tarea programada)
+}
+
+// Implementación en memoria del repositorio de suscripciones
+type inMemoryRepository struct {
+	subscriptions map[string]Subscription // Suscripciones indexadas por ID
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{subscriptions: make(map[string]Subscription)}
+}
+
+// Implementación del servicio de suscripciones
+type subscriptionService struct {
+	mu             sync.Mutex             // Serializa los cambios de las suscripciones
+	repo           *inMemoryRepository    // Repositorio interno
+	orderService   orders.Service         // Genera cada orden
+	paymentService payments.Service       // Cobra cada orden (opcional)
+	notifier       notifications.Notifier // Avisos al cliente
+	maxAttempts    int                    // Intentos por entrega antes de pausar
+	retryDelay     time.Duration          // Espera entre intentos
+	now            func() time.Time       // Reloj (reemplazable en pruebas)
+}
+
+// Option configura parámetros opcionales del servicio de suscripciones
+type Option func(*subscriptionService)
+
+// Opción que cobra cada orden generada con el método de pago de la suscripción
+func WithPayments(paymentService payments.Service) Option {
+	return func(s *subscriptionService) { s.paymentService = paymentService }
+}
+
+// Opción que fija cuántas veces se intenta una entrega y cuánto se espera entre intentos
+func WithRetry(maxAttempts int, delay time.Duration) Option {
+	return func(s *subscriptionService) { s.maxAttempts, s.retryDelay = maxAttempts, delay }
+}
+
+// Opción que reemplaza el reloj (por ejemplo, por el reloj falso del planificador)
+func WithClock(now func() time.Time) Option {
+	return func(s *subscriptionService) { s.now = now }
+}
+
+// Constructor para crear un nuevo servicio de suscripciones (por defecto 3 intentos con 1 hora entre ellos)
+func NewService(repo *inMemoryRepository, ordService orders.Service, notifier notifications.Notifier, opts ...Option) Service {
+	s := &subscriptionService{
+		repo:         repo,
+		orderService: ordService,
+		notifier:     notifier,
+		maxAttempts:  3,
+		retryDelay:   time.Hour,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Crear una suscripción; los productos y direcciones se validan cotizando la primera orden
+func (s *subscriptionService) CreateSubscription(ctx context.Context, userID string, req SubscriptionRequest) (*Subscription, error) {
+	if userID == "" || len(req.Items) == 0 || !req.Interval.IsValid() {
+		return nil, ErrInvalidSubscription
+	}
+	for _, item := range req.Items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: every item needs a product_id and a positive quantity", ErrInvalidSubscription)
+		}
+	}
+	if req.PaymentMethod != "" && s.paymentService == nil {
+		return nil, fmt.Errorf("%w: payments are not enabled", ErrInvalidSubscription)
+	}
+	// Solo valida: el precio se calcula de nuevo en cada entrega
+	_, err := s.orderService.QuoteOrder(ctx, orders.OrderRequest{
+		UserID:          userID,
+		LineItems:       req.Items,
+		Destination:     req.Destination,
+		ShippingAddress: req.ShippingAddress,
+		BillingAddress:  req.BillingAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	start := now
+	if req.StartAt != nil {
+		if req.StartAt.Before(now) {
+			return nil, fmt.Errorf("%w: start_at is in the past", ErrInvalidSubscription)
+		}
+		start = *req.StartAt
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub := Subscription{
+		ID:              fmt.Sprintf("SUB-%06d", len(s.repo.subscriptions)+1),
+		UserID:          userID,
+		Items:           append([]orders.LineItemRequest(nil), req.Items...),
+		Interval:        req.Interval,
+		PaymentMethod:   req.PaymentMethod,
+		ShippingAddress: req.ShippingAddress,
+		BillingAddress:  req.BillingAddress,
+		Destination:     req.Destination,
+		Status:          StatusActive,
+		StartAt:         start,
+		NextRunAt:       start,
+		OrderIDs:        []string{},
+		CreatedAt:       now,
+	}
+	sub.record(EventCreated, "", "", now)
+	s.repo.subscriptions[sub.ID] = sub
+	return &sub, nil
+}
+
+// Obtener una suscripción por su ID
+func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.repo.subscriptions[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return &sub, nil
+}
+
+// Suscripciones de un usuario ordenadas por ID
+func (s *subscriptionService) ListByUser(ctx context.Context, userID string) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []Subscription{}
+	for _, sub := range s.repo.subscriptions {
+		if sub.UserID == userID {
+			list = append(list, sub)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// Pausar una suscripción activa; se descarta el reintento pendiente
+func (s *subscriptionService) Pause(ctx context.Context, id string) (*Subscription, error) {
+	return s.change(id, func(sub *Subscription, now time.Time) error {
+		if sub.Status != StatusActive {
+			return ErrInvalidTransition
+		}
+		sub.Status = StatusPaused
+		sub.RetryAt, sub.Attempts = nil, 0
+		sub.record(EventPaused, "", "paused by customer", now)
+		return nil
+	})
+}
+
+// Reanudar una suscripción pausada; las fechas que pasaron durante la pausa no generan órdenes
+func (s *subscriptionService) Resume(ctx context.Context, id string) (*Subscription, error) {
+	return s.change(id, func(sub *Subscription, now time.Time) error {
+		if sub.Status != StatusPaused {
+			return ErrInvalidTransition
+		}
+		sub.Status = StatusActive
+		sub.RetryAt, sub.Attempts, sub.LastError = nil, 0, ""
+		sub.catchUp(now)
+		sub.record(EventResumed, "", "next delivery "+sub.NextRunAt.Format(time.RFC3339), now)
+		return nil
+	})
+}
+
+// Saltar las próximas count entregas (activa o pausada)
+func (s *subscriptionService) Skip(ctx context.Context, id string, count int) (*Subscription, error) {
+	if count <= 0 {
+		count = 1
+	}
+	return s.change(id, func(sub *Subscription, now time.Time) error {
+		if sub.Status == StatusCancelled {
+			return ErrInvalidTransition
+		}
+		for i := 0; i < count; i++ {
+			sub.record(EventSkipped, "", sub.NextRunAt.Format(time.RFC3339), now)
+			sub.advance()
+		}
+		return nil
+	})
+}
+
+// Cancelar una suscripción; no vuelve a generar órdenes
+func (s *subscriptionService) Cancel(ctx context.Context, id string) (*Subscription, error) {
+	return s.change(id, func(sub *Subscription, now time.Time) error {
+		if sub.Status == StatusCancelled {
+			return ErrInvalidTransition
+		}
+		sub.Status = StatusCancelled
+		sub.RetryAt = nil
+		sub.record(EventCancelled, "", "", now)
+		return nil
+	})
+}
+
+// Aplica un cambio de estado a una suscripción y la guarda
+func (s *subscriptionService) change(id string, apply func(sub *Subscription, now time.Time) error) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.repo.subscriptions[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	sub.History = append([]Event(nil), sub.History...) // Copia para no modificar la suscripción guardada si falla
+	if err := apply(&sub, s.now()); err != nil {
+		return nil, err
+	}
+	s.repo.subscriptions[id] = sub
+	return &sub, nil
+}
+
+// Generar la orden de cada suscripción activa cuya entrega (o reintento) venció a la hora now
+func (s *subscriptionService) RunDue(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	var due []string
+	for id, sub := range s.repo.subscriptions {
+		if sub.Status == StatusActive && !sub.dueAt().After(now) {
+			due = append(due, id)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(due)
+	for _, id := range due {
+		if ctx.Err() != nil {
+			return ctx.Err() // El servidor se está deteniendo: el resto queda para la próxima ejecución
+		}
+		s.generate(ctx, id, now)
+	}
+	return nil
+}
+
+// Genera y cobra la orden de una suscripción; si falla programa un reintento o, agotados, la pausa
+func (s *subscriptionService) generate(ctx context.Context, id string, now time.Time) {
+	s.mu.Lock()
+	sub := s.repo.subscriptions[id]
+	s.mu.Unlock()
+
+	order, err := s.placeOrder(ctx, sub)
+
+	s.mu.Lock()
+	sub = s.repo.subscriptions[id] // Pudo cambiar (pausa, salto, cancelación) mientras se generaba la orden
+	if sub.Status == StatusCancelled {
+		s.mu.Unlock()
+		if err == nil {
+			log.Printf("Suscripción %s cancelada mientras se generaba la orden %s\n", id, order.ID)
+		}
+		return // Una suscripción cancelada no vuelve a cambiar de estado
+	}
+	sub.History = append([]Event(nil), sub.History...)
+	var n notifications.Notification
+	switch {
+	case err == nil:
+		sub.OrderIDs = append(append([]string(nil), sub.OrderIDs...), order.ID)
+		sub.record(EventOrderCreated, order.ID, "", now)
+		sub.advance()
+		sub.catchUp(now) // Si el servicio estuvo detenido no se generan órdenes atrasadas
+		n = s.notification(sub, KindOrderCreated, "Orden de suscripción generada",
+			fmt.Sprintf("Se generó la orden %s; la próxima entrega es el %s", order.ID, sub.NextRunAt.Format("2006-01-02")))
+		n.Data["order_id"] = order.ID
+	case sub.Attempts+1 < s.maxAttempts:
+		sub.Attempts++
+		sub.LastError = err.Error()
+		retryAt := now.Add(s.retryDelay)
+		sub.RetryAt = &retryAt
+		sub.record(EventFailed, "", err.Error(), now)
+		n = s.notification(sub, KindRetry, "No se pudo generar la orden de su suscripción",
+			fmt.Sprintf("Intento %d de %d falló (%v); se reintentará el %s", sub.Attempts, s.maxAttempts, err, retryAt.Format(time.RFC3339)))
+	default:
+		sub.LastError = err.Error()
+		sub.Status = StatusPaused
+		sub.RetryAt, sub.Attempts = nil, 0
+		sub.record(EventFailed, "", err.Error(), now)
+		sub.record(EventPaused, "", fmt.Sprintf("paused after %d failed attempts", s.maxAttempts), now)
+		n = s.notification(sub, KindPaused, "Su suscripción quedó en pausa",
+			fmt.Sprintf("No se pudo generar la orden tras %d intentos (%v); revise su método de pago o reanude la suscripción", s.maxAttempts, err))
+	}
+	s.repo.subscriptions[id] = sub
+	s.mu.Unlock()
+
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		log.Printf("No se pudo notificar la suscripción %s: %v\n", id, err)
+	}
+}
+
+// Crea la orden del período y la cobra; si el cobro falla la orden se cancela para liberar el stock
+func (s *subscriptionService) placeOrder(ctx context.Context, sub Subscription) (*orders.Order, error) {
+	order, err := s.orderService.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          sub.UserID,
+		LineItems:       sub.Items,
+		Destination:     sub.Destination,
+		ShippingAddress: sub.ShippingAddress,
+		BillingAddress:  sub.BillingAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sub.PaymentMethod == "" || s.paymentService == nil {
+		return order, nil // Sin método de pago la orden queda Pendiente para que el cliente la pague
+	}
+	if _, err := s.paymentService.Authorize(ctx, order.ID, payments.PaymentRequest{Method: sub.PaymentMethod, Capture: true}); err != nil {
+		s.discardOrder(ctx, order.ID)
+		return nil, fmt.Errorf("payment failed: %w", err)
+	}
+	return order, nil
+}
+
+// Cancela la orden de un cobro fallido para liberar su stock, anulando antes la autorización que haya quedado sin cobrar
+func (s *subscriptionService) discardOrder(ctx context.Context, orderID string) {
+	if list, err := s.paymentService.ListByOrder(ctx, orderID); err == nil {
+		for _, p := range list {
+			if p.Status != payments.StatusAuthorized {
+				continue
+			}
+			if _, err := s.paymentService.Void(ctx, p.ID); err != nil {
+				log.Printf("No se pudo anular el pago %s de la orden %s: %v\n", p.ID, orderID, err)
+			}
+		}
+	}
+	for {
+		current, err := s.orderService.GetOrderByID(ctx, orderID)
+		if err == nil {
+			_, err = s.orderService.UpdateOrderStatus(ctx, orderID, orders.StatusCancelled, current.Version)
+		}
+		if errors.Is(err, orders.ErrVersionConflict) {
+			continue // Otra operación cambió la orden: se vuelve a leer y cancelar
+		}
+		if err != nil {
+			log.Printf("No se pudo cancelar la orden %s de la suscripción: %v\n", orderID, err)
+		}
+		return
+	}
+}
+
+// Arma una notificación para el dueño de la suscripción
+func (s *subscriptionService) notification(sub Subscription, kind, subject, message string) notifications.Notification {
+	return notifications.Notification{
+		Kind:    kind,
+		Subject: subject,
+		Message: message,
+		Data: map[string]string{
+			"subscription_id": sub.ID,
+			"user_id":         sub.UserID,
+			"attempts":        strconv.Itoa(sub.Attempts),
+		},
+		CreatedAt: s.now(),
+	}
+}
@@ -0,0 +1,270 @@
+// Pruebas de las suscripciones ejecutadas por el planificador con el reloj falso
+package subscriptions_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"sync"    // Sincronización del notificador de prueba
+	"testing" // Paquete de pruebas
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/notifications"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/payments"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/scheduler"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/subscriptions"
+)
+
+// Notificador que guarda los avisos enviados
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notifications.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notifications.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// Tipos de los avisos enviados en orden
+func (n *recordingNotifier) kinds() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	kinds := []string{}
+	for _, sent := range n.sent {
+		kinds = append(kinds, sent.Kind)
+	}
+	return kinds
+}
+
+// Entorno de prueba: servicios en memoria y planificador con el reloj falso
+type harness struct {
+	t        *testing.T
+	clock    *scheduler.FakeClock
+	products products.Service
+	orders   orders.Service
+	payments payments.Service
+	subs     subscriptions.Service
+	notifier *recordingNotifier
+	product  *products.Product
+}
+
+// Crea el entorno y arranca el planificador con la tarea de suscripciones cada minuto
+func newHarness(t *testing.T, start time.Time, opts ...subscriptions.Option) *harness {
+	t.Helper()
+	ctx := context.Background()
+	h := &harness{t: t, clock: scheduler.NewFakeClock(start), notifier: &recordingNotifier{}}
+	h.products = products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := h.products.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: 100})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	h.product = prod
+	h.orders = orders.NewService(orders.NewInMemoryRepository(), h.products)
+	h.payments = payments.NewService(payments.NewInMemoryRepository(), payments.NewFakeGateway(), h.orders)
+	opts = append([]subscriptions.Option{subscriptions.WithClock(h.clock.Now), subscriptions.WithPayments(h.payments)}, opts...)
+	h.subs = subscriptions.NewService(subscriptions.NewInMemoryRepository(), h.orders, h.notifier, opts...)
+
+	jobs := scheduler.New(h.clock)
+	jobs.Every("suscripciones", time.Minute, h.subs.RunDue)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		jobs.Run(runCtx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	h.waitForJob()
+	return h
+}
+
+// Espera a que la tarea termine su ejecución y vuelva a esperar al reloj
+func (h *harness) waitForJob() {
+	h.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for h.clock.Waiters() != 1 {
+		if time.Now().After(deadline) {
+			h.t.Fatal("la tarea programada no volvió a esperar")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Avanza el reloj hasta at; la tarea corre una vez con now = at
+func (h *harness) runAt(at time.Time) {
+	h.t.Helper()
+	h.clock.Advance(at.Sub(h.clock.Now()))
+	h.waitForJob()
+	time.Sleep(time.Millisecond) // Los IDs de orden se basan en la hora real
+}
+
+// Crea una suscripción con dos unidades del producto de prueba
+func (h *harness) subscribe(req subscriptions.SubscriptionRequest) *subscriptions.Subscription {
+	h.t.Helper()
+	req.Items = []orders.LineItemRequest{{ProductID: h.product.ID, Quantity: 2}}
+	req.ShippingAddress = &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"}
+	sub, err := h.subs.CreateSubscription(context.Background(), "user-1", req)
+	if err != nil {
+		h.t.Fatalf("CreateSubscription: %v", err)
+	}
+	return sub
+}
+
+// Lee el estado actual de la suscripción
+func (h *harness) get(id string) *subscriptions.Subscription {
+	h.t.Helper()
+	sub, err := h.subs.GetSubscription(context.Background(), id)
+	if err != nil {
+		h.t.Fatalf("GetSubscription: %v", err)
+	}
+	return sub
+}
+
+// Verifica la cantidad de órdenes generadas y la fecha de la próxima entrega
+func (h *harness) expect(id string, orderCount int, next time.Time) *subscriptions.Subscription {
+	h.t.Helper()
+	sub := h.get(id)
+	if len(sub.OrderIDs) != orderCount {
+		h.t.Fatalf("órdenes generadas = %d, se esperaban %d", len(sub.OrderIDs), orderCount)
+	}
+	if !sub.NextRunAt.Equal(next) {
+		h.t.Fatalf("next_run_at = %v, se esperaba %v", sub.NextRunAt, next)
+	}
+	return sub
+}
+
+func TestWeeklySubscriptionGeneratesOrderEachWeek(t *testing.T) {
+	start := time.Date(2027, 3, 1, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, start)
+	sub := h.subscribe(subscriptions.SubscriptionRequest{Interval: subscriptions.IntervalWeekly})
+
+	h.runAt(start.Add(time.Hour))
+	h.expect(sub.ID, 1, start.AddDate(0, 0, 7))
+	h.runAt(start.AddDate(0, 0, 6))
+	h.expect(sub.ID, 1, start.AddDate(0, 0, 7))
+	h.runAt(start.AddDate(0, 0, 7))
+	sub = h.expect(sub.ID, 2, start.AddDate(0, 0, 14))
+
+	order, err := h.orders.GetOrderByID(context.Background(), sub.OrderIDs[1])
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+	if order.Status != orders.StatusPending || order.UserID != "user-1" {
+		t.Fatalf("orden generada con estado %s para %s", order.Status, order.UserID)
+	}
+	if kinds := h.notifier.kinds(); len(kinds) != 2 || kinds[1] != subscriptions.KindOrderCreated {
+		t.Fatalf("avisos = %v", kinds)
+	}
+}
+
+func TestMonthlySubscriptionClampsToLastDayOfMonth(t *testing.T) {
+	cases := []struct {
+		name    string
+		startAt time.Time
+		feb     time.Time
+	}{
+		{"año común", time.Date(2027, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2027, 2, 28, 9, 0, 0, 0, time.UTC)},
+		{"año bisiesto", time.Date(2028, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t, tc.startAt.AddDate(0, 0, -1))
+			startAt := tc.startAt
+			sub := h.subscribe(subscriptions.SubscriptionRequest{Interval: subscriptions.IntervalMonthly, StartAt: &startAt})
+			h.expect(sub.ID, 0, tc.startAt)
+
+			h.runAt(tc.startAt)
+			h.expect(sub.ID, 1, tc.feb)
+			h.runAt(tc.feb)
+			// Marzo vuelve al día 31 del calendario original
+			h.expect(sub.ID, 2, time.Date(tc.startAt.Year(), 3, 31, 9, 0, 0, 0, time.UTC))
+		})
+	}
+}
+
+func TestSkipMovesToFollowingDelivery(t *testing.T) {
+	start := time.Date(2027, 3, 1, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, start)
+	sub := h.subscribe(subscriptions.SubscriptionRequest{Interval: subscriptions.IntervalWeekly})
+
+	if _, err := h.subs.Skip(context.Background(), sub.ID, 1); err != nil {
+		t.Fatalf("Skip: %v", err)
+	}
+	h.runAt(start.Add(time.Hour))
+	h.expect(sub.ID, 0, start.AddDate(0, 0, 7))
+	h.runAt(start.AddDate(0, 0, 7))
+	sub = h.expect(sub.ID, 1, start.AddDate(0, 0, 14))
+	if sub.History[1].Kind != subscriptions.EventSkipped {
+		t.Fatalf("historial = %+v, se esperaba el salto", sub.History)
+	}
+}
+
+func TestPauseAndResumeSkipsMissedDeliveries(t *testing.T) {
+	start := time.Date(2027, 3, 1, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, start)
+	sub := h.subscribe(subscriptions.SubscriptionRequest{Interval: subscriptions.IntervalWeekly})
+	ctx := context.Background()
+
+	h.runAt(start.Add(time.Hour))
+	h.expect(sub.ID, 1, start.AddDate(0, 0, 7))
+	if _, err := h.subs.Pause(ctx, sub.ID); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	h.runAt(start.AddDate(0, 0, 22))
+	h.expect(sub.ID, 1, start.AddDate(0, 0, 7))
+
+	// Al reanudar, las entregas de los días 7, 14 y 21 no generan órdenes atrasadas
+	if _, err := h.subs.Resume(ctx, sub.ID); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	h.expect(sub.ID, 1, start.AddDate(0, 0, 28))
+	h.runAt(start.AddDate(0, 0, 28))
+	h.expect(sub.ID, 2, start.AddDate(0, 0, 35))
+}
+
+func TestFailedPaymentRetriesThenPauses(t *testing.T) {
+	start := time.Date(2027, 3, 1, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, start, subscriptions.WithRetry(2, time.Hour))
+	sub := h.subscribe(subscriptions.SubscriptionRequest{Interval: subscriptions.IntervalWeekly, PaymentMethod: payments.FakeMethodDecline})
+	ctx := context.Background()
+
+	h.runAt(start.Add(time.Minute))
+	got := h.get(sub.ID)
+	if got.Status != subscriptions.StatusActive || got.Attempts != 1 || got.RetryAt == nil || !got.RetryAt.Equal(start.Add(time.Minute+time.Hour)) {
+		t.Fatalf("tras la primera falla: estado %s, intentos %d, reintento %v", got.Status, got.Attempts, got.RetryAt)
+	}
+	if p, _ := h.products.GetProductByID(ctx, h.product.ID); p.Stock != 100 {
+		t.Fatalf("stock = %d; la orden del cobro fallido debe devolver su stock", p.Stock)
+	}
+
+	// Antes del reintento no pasa nada
+	h.runAt(start.Add(31 * time.Minute))
+	if got := h.get(sub.ID); got.Attempts != 1 {
+		t.Fatalf("intentos = %d antes del reintento", got.Attempts)
+	}
+
+	h.runAt(start.Add(time.Minute + time.Hour))
+	got = h.get(sub.ID)
+	if got.Status != subscriptions.StatusPaused || got.RetryAt != nil || got.LastError == "" || len(got.OrderIDs) != 0 {
+		t.Fatalf("tras agotar los intentos: estado %s, reintento %v, error %q, órdenes %v", got.Status, got.RetryAt, got.LastError, got.OrderIDs)
+	}
+	kinds := h.notifier.kinds()
+	if len(kinds) != 2 || kinds[0] != subscriptions.KindRetry || kinds[1] != subscriptions.KindPaused {
+		t.Fatalf("avisos = %v, se esperaba reintento y pausa", kinds)
+	}
+	if p, _ := h.products.GetProductByID(ctx, h.product.ID); p.Stock != 100 {
+		t.Fatalf("stock = %d tras las fallas, se esperaba 100", p.Stock)
+	}
+
+	// La suscripción pausada no vuelve a intentar
+	h.runAt(start.AddDate(0, 0, 7))
+	if got := h.get(sub.ID); got.Status != subscriptions.StatusPaused || len(h.notifier.kinds()) != 2 {
+		t.Fatalf("la suscripción pausada volvió a generar (estado %s)", got.Status)
+	}
+}
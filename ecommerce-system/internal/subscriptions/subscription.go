@@ -0,0 +1,129 @@
+// Paquete para manejo de suscripciones que generan órdenes periódicas
+package subscriptions
+
+import (
+	"errors" // Manejo de errores
+	"time"   // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"     // Líneas de la orden
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"   // Direcciones
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses" // Ubicación de entrega
+)
+
+// Interval es la frecuencia con la que se genera una orden
+type Interval string
+
+// Constantes que definen las frecuencias posibles
+const (
+	IntervalWeekly  Interval = "weekly"  // Cada 7 días
+	IntervalMonthly Interval = "monthly" // El mismo día de cada mes (o el último si el mes es más corto)
+)
+
+// Método que valida si la frecuencia es conocida
+func (i Interval) IsValid() bool {
+	return i == IntervalWeekly || i == IntervalMonthly
+}
+
+// Fecha de la entrega número cycle (0 = la primera) contando desde start
+func (i Interval) occurrence(start time.Time, cycle int) time.Time {
+	if i == IntervalWeekly {
+		return start.AddDate(0, 0, 7*cycle)
+	}
+	y, m, d := start.Date()
+	first := time.Date(y, m+time.Month(cycle), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last // 31 de enero -> 28/29 de febrero
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// Status representa el estado de una suscripción
+type Status string
+
+// Constantes que definen los estados de una suscripción
+const (
+	StatusActive    Status = "active"    // Genera órdenes en cada fecha
+	StatusPaused    Status = "paused"    // No genera órdenes hasta que se reanude
+	StatusCancelled Status = "cancelled" // Terminada; no se puede reanudar
+)
+
+// EventKind es el tipo de una entrada del historial de la suscripción
+type EventKind string
+
+// Constantes que definen los tipos de evento
+const (
+	EventCreated      EventKind = "created"       // Alta de la suscripción
+	EventOrderCreated EventKind = "order_created" // Se generó la orden del período
+	EventFailed       EventKind = "failed"        // Falló la orden o el pago; se reintentará
+	EventSkipped      EventKind = "skipped"       // El cliente saltó una entrega
+	EventPaused       EventKind = "paused"        // Pausada por el cliente o por agotar los reintentos
+	EventResumed      EventKind = "resumed"       // Reanudada
+	EventCancelled    EventKind = "cancelled"     // Cancelada
+)
+
+// Event es una entrada del historial de la suscripción
+type Event struct {
+	Kind    EventKind `json:"kind"`               // Tipo de evento
+	OrderID string    `json:"order_id,omitempty"` // Orden generada (si aplica)
+	Note    string    `json:"note,omitempty"`     // Detalle (motivo de la falla, fecha saltada...)
+	At      time.Time `json:"at"`                 // Fecha del evento
+}
+
+// Subscription representa una compra recurrente de un usuario
+type Subscription struct {
+	ID              string                   `json:"id"`                         // ID único (SUB-000001)
+	UserID          string                   `json:"user_id"`                    // Dueño de la suscripción
+	Items           []orders.LineItemRequest `json:"items"`                      // Productos y cantidades de cada orden
+	Interval        Interval                 `json:"interval"`                   // weekly o monthly
+	PaymentMethod   string                   `json:"payment_method,omitempty"`   // Token con el que se cobra cada orden (opcional)
+	ShippingAddress *shipping.Address        `json:"shipping_address,omitempty"` // Dirección de entrega
+	BillingAddress  *shipping.Address        `json:"billing_address,omitempty"`  // Dirección de facturación
+	Destination     *warehouses.Location     `json:"destination,omitempty"`      // Ubicación para asignar bodegas
+	Status          Status                   `json:"status"`                     // Estado actual
+	StartAt         time.Time                `json:"start_at"`                   // Fecha de la primera entrega (ancla del calendario)
+	Cycle           int                      `json:"cycle"`                      // Número de la próxima entrega (0 = la primera)
+	NextRunAt       time.Time                `json:"next_run_at"`                // Fecha de la próxima entrega
+	RetryAt         *time.Time               `json:"retry_at,omitempty"`         // Próximo reintento si la entrega actual falló
+	Attempts        int                      `json:"attempts"`                   // Intentos fallidos de la entrega actual
+	LastError       string                   `json:"last_error,omitempty"`       // Motivo de la última falla
+	OrderIDs        []string                 `json:"order_ids"`                  // Órdenes generadas
+	History         []Event                  `json:"history"`                    // Eventos en orden cronológico
+	CreatedAt       time.Time                `json:"created_at"`                 // Fecha de creación
+	UpdatedAt       time.Time                `json:"updated_at"`                 // Fecha de última actualización
+}
+
+// Errores del paquete
+var (
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrInvalidSubscription  = errors.New("invalid subscription data")
+	ErrInvalidTransition    = errors.New("invalid subscription status transition")
+)
+
+// Fecha en que corresponde el próximo intento (reintento pendiente o próxima entrega)
+func (s *Subscription) dueAt() time.Time {
+	if s.RetryAt != nil {
+		return *s.RetryAt
+	}
+	return s.NextRunAt
+}
+
+// Pasa a la entrega siguiente y limpia el estado de reintentos
+func (s *Subscription) advance() {
+	s.Cycle++
+	s.NextRunAt = s.Interval.occurrence(s.StartAt, s.Cycle)
+	s.RetryAt, s.Attempts, s.LastError = nil, 0, ""
+}
+
+// Salta las entregas cuya fecha ya pasó (sin generar órdenes atrasadas)
+func (s *Subscription) catchUp(now time.Time) {
+	for !s.NextRunAt.After(now) {
+		s.Cycle++
+		s.NextRunAt = s.Interval.occurrence(s.StartAt, s.Cycle)
+	}
+}
+
+// Registra un evento en el historial
+func (s *Subscription) record(kind EventKind, orderID, note string, at time.Time) {
+	s.History = append(s.History, Event{Kind: kind, OrderID: orderID, Note: note, At: at})
+	s.UpdatedAt = at
+}
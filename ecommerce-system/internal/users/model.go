@@ -12,3 +12,10 @@ type UserLoginRequest struct {
 	Email    string `json:"email"`    // Correo electrónico para autenticación
 	Password string `json:"password"` // Contraseña para autenticación
 }
+
+// Estructura que representa los datos editables del perfil de un usuario
+type UserProfileRequest struct {
+	Email     string `json:"email"`      // Correo electrónico
+	FirstName string `json:"first_name"` // Nombre
+	LastName  string `json:"last_name"`  // Apellido
+}
@@ -4,43 +4,75 @@ package users
 import (
 	"context" // Manejo de contexto en funciones
 	"errors"  // Manejo de errores
+	"sync"    // Sincronización de acceso concurrente
 	"time"    // Manejo de tiempos y fechas
 )
 
 // Interfaz que define los servicios disponibles para usuarios
 type Service interface {
-	RegisterUser(ctx context.Context, email, password string) (*User, error)     // Registrar usuario
-	AuthenticateUser(ctx context.Context, email, password string) (*User, error) // Autenticar usuario
+	RegisterUser(ctx context.Context, email, password string) (*User, error)             // Registrar usuario
+	AuthenticateUser(ctx context.Context, email, password string) (*User, error)         // Autenticar usuario
+	GetUserByID(ctx context.Context, id string) (*User, error)                           // Obtener usuario por ID
+	UpdateProfile(ctx context.Context, id string, req UserProfileRequest) (*User, error) // Actualizar perfil del usuario
 }
 
 // Interfaz para operaciones de almacenamiento de usuarios
 type Repository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error) // Obtener usuario por email
+	GetByID(ctx context.Context, id string) (*User, error)       // Obtener usuario por ID
 	Save(ctx context.Context, user User) error                   // Guardar usuario
 }
 
+// Error que indica que el usuario no existe
+var ErrUserNotFound = errors.New("user not found")
+
 // Implementación en memoria del repositorio de usuarios
 type inMemoryRepository struct {
-	data map[string]User // Mapa que almacena usuarios indexados por email
+	mu   sync.RWMutex      // Mutex para sincronizar acceso concurrente
+	data map[string]User   // Mapa que almacena usuarios indexados por email
+	ids  map[string]string // Índice secundario: ID de usuario -> email
 }
 
 // Constructor para crear un nuevo repositorio en memoria
 func NewInMemoryRepository() *inMemoryRepository {
-	return &inMemoryRepository{data: make(map[string]User)} // Inicializa mapa vacío
+	return &inMemoryRepository{data: make(map[string]User), ids: make(map[string]string)} // Inicializa mapas vacíos
 }
 
-// Guarda un usuario en el repositorio
+// Guarda un usuario en el repositorio (inserta o actualiza, incluso si cambia el email)
 func (r *inMemoryRepository) Save(ctx context.Context, u User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if oldEmail, ok := r.ids[u.ID]; ok && oldEmail != u.Email {
+		delete(r.data, oldEmail) // El email es la clave principal, se reindexa
+	}
 	r.data[u.Email] = u
+	r.ids[u.ID] = u.Email
 	return nil
 }
 
 // Obtiene un usuario por su email, retorna error si no existe
 func (r *inMemoryRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	u, ok := r.data[email]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
+	}
+	return &u, nil
+}
+
+// Obtiene un usuario por su ID, retorna error si no existe
+func (r *inMemoryRepository) GetByID(ctx context.Context, id string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	email, ok := r.ids[id]
+	if !ok {
+		return nil, ErrUserNotFound
 	}
+	u := r.data[email]
 	return &u, nil
 }
 
@@ -82,3 +114,30 @@ func (s *userService) AuthenticateUser(ctx context.Context, email, password stri
 	}
 	return u, nil
 }
+
+// Obtiene un usuario por su ID
+func (s *userService) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Actualiza los datos del perfil validando que el email no quede vacío ni duplicado
+func (s *userService) UpdateProfile(ctx context.Context, id string, req UserProfileRequest) (*User, error) {
+	if req.Email == "" {
+		return nil, errors.New("invalid user data") // Mismas reglas que en el registro
+	}
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Email != u.Email {
+		if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
+			return nil, errors.New("email already registered") // El nuevo email ya pertenece a otro usuario
+		}
+	}
+	u.Email = req.Email
+	u.FirstName = req.FirstName
+	u.LastName = req.LastName
+	u.UpdatedAt = time.Now() // Actualizar timestamp
+	s.repo.Save(ctx, *u)     // Guardar cambios
+	return u, nil
+}
@@ -10,7 +10,9 @@ type Role string
 type User struct {
 	ID        string    `json:"id"`         // Identificador único del usuario
 	Email     string    `json:"email"`      // Correo electrónico
-	Password  string    `json:"password"`   // Contraseña (idealmente almacenada hasheada)
+	FirstName string    `json:"first_name"` // Nombre
+	LastName  string    `json:"last_name"`  // Apellido
+	Password  string    `json:"-"`          // Contraseña (idealmente almacenada hasheada); nunca se serializa
 	Roles     []Role    `json:"roles"`      // Lista de roles asignados al usuario
 	CreatedAt time.Time `json:"created_at"` // Fecha de creación
 	UpdatedAt time.Time `json:"updated_at"` // Fecha de última actualización
@@ -22,6 +24,8 @@ func NewUser(id, email, password, nombre, apellido string) User {
 	return User{
 		ID:        id,
 		Email:     email,
+		FirstName: nombre,
+		LastName:  apellido,
 		Password:  password,
 		Roles:     []Role{"cliente"}, // Asigna rol "cliente" por defecto
 		CreatedAt: now,
@@ -29,6 +33,11 @@ func NewUser(id, email, password, nombre, apellido string) User {
 	}
 }
 
+// Método que convierte el perfil del usuario en una solicitud editable (base para actualizaciones parciales)
+func (u *User) ToProfileRequest() UserProfileRequest {
+	return UserProfileRequest{Email: u.Email, FirstName: u.FirstName, LastName: u.LastName}
+}
+
 // Método que verifica si un usuario tiene un rol específico
 func (u *User) TieneRol(rol string) bool {
 	for _, r := range u.Roles {
@@ -0,0 +1,178 @@
+// Paquete para manejo de bodegas (almacenes) y su inventario
+package warehouses
+
+import "sort" // Ordenamiento de bodegas
+
+// StockLookup retorna el stock disponible de un producto en una bodega
+type StockLookup func(warehouseID, productID string) int
+
+// AllocationStrategy decide desde qué bodegas se despacha cada línea de una orden
+type AllocationStrategy interface {
+	Name() string // Nombre de la estrategia
+	// Retorna las asignaciones de cada línea, en el mismo orden que lines
+	Allocate(lines []AllocationLine, warehouses []Warehouse, stock StockLookup, dest Destination) ([][]Allocation, error)
+}
+
+// Constructor que obtiene una estrategia por su nombre: "priority", "nearest" o "fewest_splits"
+func StrategyByName(name string) (AllocationStrategy, error) {
+	switch name {
+	case "", "priority":
+		return PriorityStrategy{}, nil
+	case "nearest":
+		return NearestStrategy{}, nil
+	case "fewest_splits":
+		return FewestSplitsStrategy{}, nil
+	default:
+		return nil, ErrUnknownAllocationRule
+	}
+}
+
+// PriorityStrategy recorre las bodegas según su prioridad configurada
+type PriorityStrategy struct{}
+
+// Nombre de la estrategia
+func (PriorityStrategy) Name() string { return "priority" }
+
+// Asigna cada línea tomando stock de las bodegas en orden de prioridad
+func (PriorityStrategy) Allocate(lines []AllocationLine, warehouses []Warehouse, stock StockLookup, dest Destination) ([][]Allocation, error) {
+	return greedyAllocate(lines, byPriority(warehouses), newWorkingStock(stock))
+}
+
+// NearestStrategy prefiere las bodegas más cercanas al destino (si no hay destino usa la prioridad)
+type NearestStrategy struct{}
+
+// Nombre de la estrategia
+func (NearestStrategy) Name() string { return "nearest" }
+
+// Asigna cada línea tomando stock de las bodegas más cercanas primero
+func (NearestStrategy) Allocate(lines []AllocationLine, warehouses []Warehouse, stock StockLookup, dest Destination) ([][]Allocation, error) {
+	ordered := byPriority(warehouses)
+	if dest.Location != nil {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Location.DistanceKm(*dest.Location) < ordered[j].Location.DistanceKm(*dest.Location)
+		})
+	}
+	return greedyAllocate(lines, ordered, newWorkingStock(stock))
+}
+
+// FewestSplitsStrategy intenta despachar la orden desde el menor número de bodegas
+type FewestSplitsStrategy struct{}
+
+// Nombre de la estrategia
+func (FewestSplitsStrategy) Name() string { return "fewest_splits" }
+
+// Busca primero una bodega que cubra toda la orden; si no existe, cubre cada línea con la menor cantidad de bodegas
+func (FewestSplitsStrategy) Allocate(lines []AllocationLine, warehouses []Warehouse, stock StockLookup, dest Destination) ([][]Allocation, error) {
+	ordered := byPriority(warehouses)
+
+	// 1. Una sola bodega para toda la orden
+	for _, w := range ordered {
+		working := newWorkingStock(stock)
+		result := make([][]Allocation, len(lines))
+		complete := true
+		for i, line := range lines {
+			if working.available(w.ID, line.ProductID) < line.Quantity {
+				complete = false
+				break
+			}
+			working.take(w.ID, line.ProductID, line.Quantity)
+			result[i] = []Allocation{{WarehouseID: w.ID, Quantity: line.Quantity}}
+		}
+		if complete {
+			return result, nil
+		}
+	}
+
+	// 2. Por línea: preferir la bodega que cubra la línea completa, luego la de mayor stock
+	working := newWorkingStock(stock)
+	result := make([][]Allocation, len(lines))
+	for i, line := range lines {
+		candidates := append([]Warehouse(nil), ordered...)
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return working.available(candidates[a].ID, line.ProductID) > working.available(candidates[b].ID, line.ProductID)
+		})
+		for _, w := range candidates {
+			if working.available(w.ID, line.ProductID) >= line.Quantity {
+				candidates = []Warehouse{w} // Una bodega basta para la línea
+				break
+			}
+		}
+		allocs, err := allocateLine(line, candidates, working)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = allocs
+	}
+	return result, nil
+}
+
+// Asigna las líneas en orden tomando stock de las bodegas en el orden recibido
+func greedyAllocate(lines []AllocationLine, ordered []Warehouse, working *workingStock) ([][]Allocation, error) {
+	result := make([][]Allocation, len(lines))
+	for i, line := range lines {
+		allocs, err := allocateLine(line, ordered, working)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = allocs
+	}
+	return result, nil
+}
+
+// Asigna una línea recorriendo las bodegas en orden hasta cubrir la cantidad
+func allocateLine(line AllocationLine, ordered []Warehouse, working *workingStock) ([]Allocation, error) {
+	remaining := line.Quantity
+	var allocs []Allocation
+	for _, w := range ordered {
+		if remaining == 0 {
+			break
+		}
+		qty := working.available(w.ID, line.ProductID)
+		if qty <= 0 {
+			continue
+		}
+		if qty > remaining {
+			qty = remaining
+		}
+		working.take(w.ID, line.ProductID, qty)
+		allocs = append(allocs, Allocation{WarehouseID: w.ID, Quantity: qty})
+		remaining -= qty
+	}
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+	return allocs, nil
+}
+
+// Copia de las bodegas ordenadas por prioridad (y luego por ID para ser deterministas)
+func byPriority(warehouses []Warehouse) []Warehouse {
+	ordered := append([]Warehouse(nil), warehouses...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority < ordered[j].Priority
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+	return ordered
+}
+
+// workingStock lleva la cuenta del stock consumido mientras se calcula una asignación
+type workingStock struct {
+	lookup StockLookup
+	used   map[[2]string]int
+}
+
+// Constructor del stock de trabajo
+func newWorkingStock(lookup StockLookup) *workingStock {
+	return &workingStock{lookup: lookup, used: make(map[[2]string]int)}
+}
+
+// Stock aún disponible de un producto en una bodega
+func (s *workingStock) available(warehouseID, productID string) int {
+	return s.lookup(warehouseID, productID) - s.used[[2]string{warehouseID, productID}]
+}
+
+// Marca stock como consumido
+func (s *workingStock) take(warehouseID, productID string, qty int) {
+	s.used[[2]string{warehouseID, productID}] += qty
+}
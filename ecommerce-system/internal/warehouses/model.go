@@ -0,0 +1,27 @@
+// Paquete para manejo de bodegas (almacenes) y su inventario
+package warehouses
+
+import "github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+
+// Estructura que representa la solicitud para crear una bodega
+type WarehouseRequest struct {
+	Code     string   `json:"code"`     // Código corto
+	Name     string   `json:"name"`     // Nombre descriptivo
+	Location Location `json:"location"` // Ubicación
+	Priority int      `json:"priority"` // Prioridad de despacho
+}
+
+// Estructura que representa la solicitud de ajuste de stock en una bodega
+type StockAdjustmentRequest struct {
+	ProductID string `json:"product_id"` // Producto a ajustar
+	products.StockAdjustment
+}
+
+// Estructura que representa la solicitud de traslado entre bodegas
+type TransferRequest struct {
+	ProductID       string `json:"product_id"`        // Producto a trasladar
+	FromWarehouseID string `json:"from_warehouse_id"` // Bodega de origen
+	ToWarehouseID   string `json:"to_warehouse_id"`   // Bodega de destino
+	Quantity        int    `json:"quantity"`          // Cantidad a trasladar
+	Actor           string `json:"actor"`             // Quién solicita el traslado
+}
@@ -0,0 +1,341 @@
+// Paquete para manejo de bodegas (almacenes) y su inventario
+package warehouses
+
+import (
+	"context" // Manejo de contexto en funciones
+	"fmt"     // Formateo de IDs
+	"sort"    // Ordenamiento de resultados
+	"sync"    // Sincronización de acceso concurrente
+	"time"    // Manejo de tiempos y fechas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products" // Servicio productos
+)
+
+// Interfaz que define las operaciones disponibles en el servicio de bodegas
+type Service interface {
+	CreateWarehouse(ctx context.Context, req WarehouseRequest) (*Warehouse, error)                               // Crear bodega
+	ListWarehouses(ctx context.Context) ([]Warehouse, error)                                                     // Listar bodegas
+	GetWarehouse(ctx context.Context, id string) (*Warehouse, error)                                             // Obtener bodega por ID
+	AdjustStock(ctx context.Context, warehouseID string, req StockAdjustmentRequest) (*StockLevel, error)        // Ajustar stock en una bodega
+	TransferStock(ctx context.Context, req TransferRequest) (*Transfer, error)                                   // Trasladar stock entre bodegas
+	ListStockByWarehouse(ctx context.Context, warehouseID string) ([]StockLevel, error)                          // Stock de una bodega
+	ListStockByProduct(ctx context.Context, productID string) ([]StockLevel, error)                              // Stock de un producto por bodega
+	Reserve(ctx context.Context, lines []AllocationLine, dest Destination) ([][]Allocation, error)               // Asignar y apartar stock para una orden
+	Plan(ctx context.Context, lines []AllocationLine, dest Destination) ([][]Allocation, error)                  // Calcular la asignación sin apartar stock
+	Release(ctx context.Context, productID string, allocations []Allocation) error                               // Devolver stock apartado
+	Restock(ctx context.Context, productID string, allocations []Allocation, adj products.StockAdjustment) error // Reingresar unidades vendidas a sus bodegas
+	Manages(ctx context.Context, productID string) bool                                                          // Si el stock del producto se gestiona por bodega
+}
+
+// Implementación en memoria del repositorio de bodegas
+type inMemoryRepository struct {
+	warehouses map[string]Warehouse      // Bodegas indexadas por ID
+	levels     map[string]map[string]int // Stock por producto y bodega: producto -> bodega -> cantidad
+	transfers  []Transfer                // Historial de traslados
+}
+
+// Constructor para crear un nuevo repositorio en memoria
+func NewInMemoryRepository() *inMemoryRepository {
+	return &inMemoryRepository{
+		warehouses: make(map[string]Warehouse),
+		levels:     make(map[string]map[string]int),
+	}
+}
+
+// Implementación del servicio de bodegas
+type warehouseService struct {
+	mu             sync.Mutex          // Serializa las operaciones de stock entre bodegas
+	repo           *inMemoryRepository // Repositorio interno
+	productService products.Service    // Servicio de productos para mantener el stock total y el libro
+	strategy       AllocationStrategy  // Estrategia de asignación de bodegas
+}
+
+// Constructor para crear un nuevo servicio de bodegas
+func NewService(repo *inMemoryRepository, prodService products.Service, strategy AllocationStrategy) Service {
+	return &warehouseService{repo: repo, productService: prodService, strategy: strategy}
+}
+
+// Crear una bodega validando datos básicos
+func (s *warehouseService) CreateWarehouse(ctx context.Context, req WarehouseRequest) (*Warehouse, error) {
+	if req.Code == "" || req.Name == "" {
+		return nil, ErrInvalidWarehouseData
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.repo.warehouses {
+		if w.Code == req.Code {
+			return nil, fmt.Errorf("%w: code %s already exists", ErrInvalidWarehouseData, req.Code)
+		}
+	}
+	w := Warehouse{
+		ID:        fmt.Sprintf("WH-%03d", len(s.repo.warehouses)+1),
+		Code:      req.Code,
+		Name:      req.Name,
+		Location:  req.Location,
+		Priority:  req.Priority,
+		CreatedAt: time.Now(),
+	}
+	s.repo.warehouses[w.ID] = w
+	return &w, nil
+}
+
+// Listar bodegas ordenadas por prioridad
+func (s *warehouseService) ListWarehouses(ctx context.Context) ([]Warehouse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedWarehouses(), nil
+}
+
+// Obtener una bodega por su ID
+func (s *warehouseService) GetWarehouse(ctx context.Context, id string) (*Warehouse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.repo.warehouses[id]
+	if !ok {
+		return nil, ErrWarehouseNotFound
+	}
+	return &w, nil
+}
+
+// Ajustar el stock de un producto en una bodega; el stock total y el libro del producto se ajustan igual.
+// Si el producto aún no se gestiona por bodega, su stock general pasa primero a esta bodega.
+func (s *warehouseService) AdjustStock(ctx context.Context, warehouseID string, req StockAdjustmentRequest) (*StockLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.repo.warehouses[warehouseID]; !ok {
+		return nil, ErrWarehouseNotFound
+	}
+	if err := s.adopt(ctx, warehouseID, req.ProductID); err != nil {
+		return nil, err
+	}
+	current := s.level(warehouseID, req.ProductID)
+	if current+req.Delta < 0 {
+		return nil, products.ErrorStockInsuficiente
+	}
+	if _, err := s.productService.AdjustStock(ctx, req.ProductID, req.StockAdjustment); err != nil {
+		return nil, err
+	}
+	s.setLevel(warehouseID, req.ProductID, current+req.Delta)
+	return &StockLevel{WarehouseID: warehouseID, ProductID: req.ProductID, Quantity: current + req.Delta}, nil
+}
+
+// Trasladar stock entre bodegas; el stock total del producto no cambia
+func (s *warehouseService) TransferStock(ctx context.Context, req TransferRequest) (*Transfer, error) {
+	if req.Quantity <= 0 || req.FromWarehouseID == req.ToWarehouseID {
+		return nil, ErrInvalidTransfer
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, okFrom := s.repo.warehouses[req.FromWarehouseID]
+	_, okTo := s.repo.warehouses[req.ToWarehouseID]
+	if !okFrom || !okTo {
+		return nil, ErrWarehouseNotFound
+	}
+	if err := s.adopt(ctx, req.FromWarehouseID, req.ProductID); err != nil {
+		return nil, err
+	}
+	from := s.level(req.FromWarehouseID, req.ProductID)
+	if from < req.Quantity {
+		return nil, ErrInsufficientStock
+	}
+	s.setLevel(req.FromWarehouseID, req.ProductID, from-req.Quantity)
+	s.setLevel(req.ToWarehouseID, req.ProductID, s.level(req.ToWarehouseID, req.ProductID)+req.Quantity)
+	t := Transfer{
+		ID:              fmt.Sprintf("TR-%06d", len(s.repo.transfers)+1),
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Actor:           req.Actor,
+		CreatedAt:       time.Now(),
+	}
+	s.repo.transfers = append(s.repo.transfers, t)
+	return &t, nil
+}
+
+// Listar el stock de todos los productos en una bodega
+func (s *warehouseService) ListStockByWarehouse(ctx context.Context, warehouseID string) ([]StockLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.repo.warehouses[warehouseID]; !ok {
+		return nil, ErrWarehouseNotFound
+	}
+	levels := []StockLevel{}
+	for productID, byWarehouse := range s.repo.levels {
+		if qty, ok := byWarehouse[warehouseID]; ok {
+			levels = append(levels, StockLevel{WarehouseID: warehouseID, ProductID: productID, Quantity: qty})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].ProductID < levels[j].ProductID })
+	return levels, nil
+}
+
+// Listar el stock de un producto en cada bodega
+func (s *warehouseService) ListStockByProduct(ctx context.Context, productID string) ([]StockLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	levels := []StockLevel{}
+	for _, w := range s.sortedWarehouses() {
+		levels = append(levels, StockLevel{WarehouseID: w.ID, ProductID: productID, Quantity: s.level(w.ID, productID)})
+	}
+	return levels, nil
+}
+
+// Asignar bodegas a las líneas con la estrategia configurada y apartar ese stock.
+// Los productos sin stock registrado en ninguna bodega quedan sin asignar (stock general).
+func (s *warehouseService) Reserve(ctx context.Context, lines []AllocationLine, dest Destination) ([][]Allocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, err := s.plan(lines, dest)
+	if err != nil {
+		return nil, err
+	}
+	for i, allocs := range result {
+		for _, a := range allocs {
+			s.setLevel(a.WarehouseID, lines[i].ProductID, s.level(a.WarehouseID, lines[i].ProductID)-a.Quantity)
+		}
+	}
+	return result, nil
+}
+
+// Calcular la asignación que haría Reserve sin apartar stock (para cotizar)
+func (s *warehouseService) Plan(ctx context.Context, lines []AllocationLine, dest Destination) ([][]Allocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.plan(lines, dest)
+}
+
+// Asigna bodegas a las líneas de productos gestionados por bodega; el resto queda sin asignar
+func (s *warehouseService) plan(lines []AllocationLine, dest Destination) ([][]Allocation, error) {
+	var tracked []AllocationLine // Líneas cuyos productos se gestionan por bodega
+	var positions []int          // Posición original de cada línea gestionada
+	for i, line := range lines {
+		if _, ok := s.repo.levels[line.ProductID]; ok {
+			tracked = append(tracked, line)
+			positions = append(positions, i)
+		}
+	}
+	result := make([][]Allocation, len(lines))
+	if len(tracked) == 0 {
+		return result, nil
+	}
+	allocated, err := s.strategy.Allocate(tracked, s.sortedWarehouses(), s.level, dest)
+	if err != nil {
+		return nil, err
+	}
+	for i, allocs := range allocated {
+		result[positions[i]] = allocs
+	}
+	return result, nil
+}
+
+// Devolver a cada bodega el stock apartado de un producto
+func (s *warehouseService) Release(ctx context.Context, productID string, allocations []Allocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range allocations {
+		s.setLevel(a.WarehouseID, productID, s.level(a.WarehouseID, productID)+a.Quantity)
+	}
+	return nil
+}
+
+// Reingresar unidades vendidas (devoluciones, reembolsos) a las bodegas de las que salieron según las asignaciones
+// de la línea; el stock total y el libro del producto se ajustan igual. Sin asignaciones solo cambia el stock general.
+// Un delta negativo deshace un reingreso anterior con las mismas asignaciones.
+func (s *warehouseService) Restock(ctx context.Context, productID string, allocations []Allocation, adj products.StockAdjustment) error {
+	quantity := adj.Delta
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	parts := splitAllocations(allocations, quantity)
+	if len(parts) == 0 {
+		_, err := s.productService.AdjustStock(ctx, productID, adj)
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sign := 1
+	if adj.Delta < 0 {
+		sign = -1
+		for _, a := range parts {
+			if s.level(a.WarehouseID, productID) < a.Quantity {
+				return products.ErrorStockInsuficiente
+			}
+		}
+	}
+	if _, err := s.productService.AdjustStock(ctx, productID, adj); err != nil {
+		return err
+	}
+	for _, a := range parts {
+		s.setLevel(a.WarehouseID, productID, s.level(a.WarehouseID, productID)+sign*a.Quantity)
+	}
+	return nil
+}
+
+// Indica si el producto tiene stock registrado en alguna bodega; su stock solo debe cambiar a través de ellas
+func (s *warehouseService) Manages(ctx context.Context, productID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.repo.levels[productID]
+	return ok
+}
+
+// Pasa el stock general de un producto aún no gestionado por bodega a la bodega indicada, para que las
+// unidades existentes sigan siendo asignables a las órdenes. La primera bodega que recibe el producto es su
+// bodega por defecto; el stock total no cambia.
+func (s *warehouseService) adopt(ctx context.Context, warehouseID, productID string) error {
+	if _, ok := s.repo.levels[productID]; ok {
+		return nil
+	}
+	p, err := s.productService.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	s.setLevel(warehouseID, productID, p.Stock)
+	return nil
+}
+
+// Reparte quantity unidades entre las asignaciones de una línea, en su orden; lo que exceda queda en la última
+func splitAllocations(allocations []Allocation, quantity int) []Allocation {
+	var parts []Allocation
+	for _, a := range allocations {
+		if quantity <= 0 {
+			break
+		}
+		take := a.Quantity
+		if take > quantity {
+			take = quantity
+		}
+		if take <= 0 {
+			continue
+		}
+		parts = append(parts, Allocation{WarehouseID: a.WarehouseID, Quantity: take})
+		quantity -= take
+	}
+	if quantity > 0 && len(parts) > 0 {
+		parts[len(parts)-1].Quantity += quantity
+	}
+	return parts
+}
+
+// Stock de un producto en una bodega (0 si no hay registro)
+func (s *warehouseService) level(warehouseID, productID string) int {
+	return s.repo.levels[productID][warehouseID]
+}
+
+// Fija el stock de un producto en una bodega
+func (s *warehouseService) setLevel(warehouseID, productID string, qty int) {
+	if s.repo.levels[productID] == nil {
+		s.repo.levels[productID] = make(map[string]int)
+	}
+	s.repo.levels[productID][warehouseID] = qty
+}
+
+// Bodegas ordenadas por prioridad y luego por ID
+func (s *warehouseService) sortedWarehouses() []Warehouse {
+	list := make([]Warehouse, 0, len(s.repo.warehouses))
+	for _, w := range s.repo.warehouses {
+		list = append(list, w)
+	}
+	return byPriority(list)
+}
@@ -0,0 +1,116 @@
+// Pruebas del stock por bodega y de la asignación de bodegas a las órdenes
+package warehouses_test
+
+import (
+	"context" // Manejo de contexto en funciones
+	"testing" // Paquete de pruebas
+
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/orders"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/products"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/shipping"
+	"github.com/Kevinchox/Programacion-Orientada-a-objetos/ecommerce-system/internal/warehouses"
+)
+
+// Crea el servicio de productos con un producto de stock general y el servicio de bodegas con dos bodegas
+func setup(t *testing.T, stock int) (products.Service, warehouses.Service, *products.Product) {
+	t.Helper()
+	ctx := context.Background()
+	productService := products.NewService(products.NewInMemoryRepository(), products.NewInMemoryLedger())
+	prod, err := productService.CreateProduct(ctx, products.ProductRequest{Name: "Café", SKU: "CAF-1", Price: 10, Stock: stock})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	svc := warehouses.NewService(warehouses.NewInMemoryRepository(), productService, warehouses.PriorityStrategy{})
+	for _, req := range []warehouses.WarehouseRequest{{Code: "UIO", Name: "Quito", Priority: 2}, {Code: "GYE", Name: "Guayaquil", Priority: 1}} {
+		if _, err := svc.CreateWarehouse(ctx, req); err != nil {
+			t.Fatalf("CreateWarehouse: %v", err)
+		}
+	}
+	return productService, svc, prod
+}
+
+// Stock de un producto en una bodega
+func levelOf(t *testing.T, svc warehouses.Service, warehouseID, productID string) int {
+	t.Helper()
+	levels, err := svc.ListStockByProduct(context.Background(), productID)
+	if err != nil {
+		t.Fatalf("ListStockByProduct: %v", err)
+	}
+	for _, l := range levels {
+		if l.WarehouseID == warehouseID {
+			return l.Quantity
+		}
+	}
+	return 0
+}
+
+func TestInitialStockMovesToFirstWarehouse(t *testing.T) {
+	ctx := context.Background()
+	productService, svc, prod := setup(t, 10)
+
+	adj := warehouses.StockAdjustmentRequest{ProductID: prod.ID, StockAdjustment: products.StockAdjustment{Delta: 5, Reason: products.ReasonRestock}}
+	if _, err := svc.AdjustStock(ctx, "WH-002", adj); err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if got := levelOf(t, svc, "WH-002", prod.ID); got != 15 {
+		t.Fatalf("stock en WH-002 = %d, se esperaba 15 (10 iniciales + 5 repuestos)", got)
+	}
+	if p, _ := productService.GetProductByID(ctx, prod.ID); p.Stock != 15 {
+		t.Fatalf("stock total = %d, se esperaba 15", p.Stock)
+	}
+
+	// El traslado no cambia el total y el stock inicial ya no se vuelve a sumar
+	if _, err := svc.TransferStock(ctx, warehouses.TransferRequest{ProductID: prod.ID, FromWarehouseID: "WH-002", ToWarehouseID: "WH-001", Quantity: 4}); err != nil {
+		t.Fatalf("TransferStock: %v", err)
+	}
+	if a, b := levelOf(t, svc, "WH-001", prod.ID), levelOf(t, svc, "WH-002", prod.ID); a != 4 || b != 11 {
+		t.Fatalf("stock por bodega = %d y %d, se esperaba 4 y 11", a, b)
+	}
+}
+
+func TestTransferAdoptsGeneralStock(t *testing.T) {
+	ctx := context.Background()
+	_, svc, prod := setup(t, 6)
+	if _, err := svc.TransferStock(ctx, warehouses.TransferRequest{ProductID: prod.ID, FromWarehouseID: "WH-001", ToWarehouseID: "WH-002", Quantity: 2}); err != nil {
+		t.Fatalf("TransferStock: %v", err)
+	}
+	if a, b := levelOf(t, svc, "WH-001", prod.ID), levelOf(t, svc, "WH-002", prod.ID); a != 4 || b != 2 {
+		t.Fatalf("stock por bodega = %d y %d, se esperaba 4 y 2", a, b)
+	}
+}
+
+func TestOrderAllocatesInitialStock(t *testing.T) {
+	ctx := context.Background()
+	productService, svc, prod := setup(t, 10)
+	adj := warehouses.StockAdjustmentRequest{ProductID: prod.ID, StockAdjustment: products.StockAdjustment{Delta: 2, Reason: products.ReasonRestock}}
+	if _, err := svc.AdjustStock(ctx, "WH-002", adj); err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if _, err := svc.TransferStock(ctx, warehouses.TransferRequest{ProductID: prod.ID, FromWarehouseID: "WH-002", ToWarehouseID: "WH-001", Quantity: 3}); err != nil {
+		t.Fatalf("TransferStock: %v", err)
+	}
+	orderService := orders.NewService(orders.NewInMemoryRepository(), productService, orders.WithStockAllocator(svc))
+
+	// 11 unidades: las 9 de la bodega prioritaria (GYE, con el stock inicial) y 2 de la otra
+	o, err := orderService.CreateOrder(ctx, orders.OrderRequest{
+		UserID:          "user-1",
+		LineItems:       []orders.LineItemRequest{{ProductID: prod.ID, Quantity: 11}},
+		ShippingAddress: &shipping.Address{Name: "Ana Pérez", Line1: "Av. Amazonas 123", City: "Quito", PostalCode: "170135", Country: "EC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	want := []warehouses.Allocation{{WarehouseID: "WH-002", Quantity: 9}, {WarehouseID: "WH-001", Quantity: 2}}
+	got := o.LineItems[0].Allocations
+	if len(got) != len(want) {
+		t.Fatalf("asignaciones = %v, se esperaba %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("asignaciones = %v, se esperaba %v", got, want)
+		}
+	}
+	if a, b := levelOf(t, svc, "WH-001", prod.ID), levelOf(t, svc, "WH-002", prod.ID); a != 1 || b != 0 {
+		t.Fatalf("stock por bodega tras la orden = %d y %d, se esperaba 1 y 0", a, b)
+	}
+}
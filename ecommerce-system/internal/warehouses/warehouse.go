@@ -0,0 +1,78 @@
+// Paquete para manejo de bodegas (almacenes) y su inventario
+package warehouses
+
+import (
+	"errors" // Manejo de errores
+	"math"   // Cálculo de distancias
+	"time"   // Manejo de tiempos y fechas
+)
+
+// Location representa una ubicación geográfica
+type Location struct {
+	Latitude  float64 `json:"latitude"`  // Latitud en grados
+	Longitude float64 `json:"longitude"` // Longitud en grados
+}
+
+// Método que calcula la distancia en kilómetros hasta otra ubicación (fórmula de Haversine)
+func (l Location) DistanceKm(other Location) float64 {
+	const earthRadiusKm = 6371.0
+	lat1, lat2 := l.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude - l.Longitude) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Warehouse representa una bodega desde la que se despachan pedidos
+type Warehouse struct {
+	ID        string    `json:"id"`         // ID único de la bodega
+	Code      string    `json:"code"`       // Código corto (ej. "UIO-1")
+	Name      string    `json:"name"`       // Nombre descriptivo
+	Location  Location  `json:"location"`   // Ubicación para la estrategia de bodega más cercana
+	Priority  int       `json:"priority"`   // Prioridad de despacho (menor valor = mayor prioridad)
+	CreatedAt time.Time `json:"created_at"` // Fecha de creación
+}
+
+// StockLevel representa la cantidad de un producto en una bodega
+type StockLevel struct {
+	WarehouseID string `json:"warehouse_id"` // Bodega
+	ProductID   string `json:"product_id"`   // Producto
+	Quantity    int    `json:"quantity"`     // Cantidad disponible
+}
+
+// Transfer registra el traslado de stock entre dos bodegas
+type Transfer struct {
+	ID              string    `json:"id"`                // ID único del traslado
+	ProductID       string    `json:"product_id"`        // Producto trasladado
+	FromWarehouseID string    `json:"from_warehouse_id"` // Bodega de origen
+	ToWarehouseID   string    `json:"to_warehouse_id"`   // Bodega de destino
+	Quantity        int       `json:"quantity"`          // Cantidad trasladada
+	Actor           string    `json:"actor"`             // Quién realizó el traslado
+	CreatedAt       time.Time `json:"created_at"`        // Fecha del traslado
+}
+
+// Allocation indica cuánto de una línea de orden despacha una bodega
+type Allocation struct {
+	WarehouseID string `json:"warehouse_id"` // Bodega que despacha
+	Quantity    int    `json:"quantity"`     // Cantidad despachada desde esa bodega
+}
+
+// AllocationLine es una línea de orden a asignar
+type AllocationLine struct {
+	ProductID string // Producto solicitado
+	Quantity  int    // Cantidad solicitada
+}
+
+// Destination describe el destino del envío (opcional, usado por la estrategia de cercanía)
+type Destination struct {
+	Location *Location // Ubicación de entrega, nil si se desconoce
+}
+
+// Errores del paquete
+var (
+	ErrWarehouseNotFound     = errors.New("warehouse not found")
+	ErrInvalidWarehouseData  = errors.New("invalid warehouse data")
+	ErrInsufficientStock     = errors.New("insufficient stock in warehouses")
+	ErrInvalidTransfer       = errors.New("invalid transfer")
+	ErrUnknownAllocationRule = errors.New("unknown allocation strategy")
+)